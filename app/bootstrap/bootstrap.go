@@ -0,0 +1,189 @@
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/maxlcoder/homework-backend/app/contract"
+	"github.com/maxlcoder/homework-backend/app/middleware"
+	"github.com/maxlcoder/homework-backend/app/route"
+	"github.com/maxlcoder/homework-backend/config"
+	"github.com/maxlcoder/homework-backend/database"
+	"github.com/maxlcoder/homework-backend/database/migrate"
+	"github.com/maxlcoder/homework-backend/database/seed"
+	"github.com/maxlcoder/homework-backend/kafka"
+	"github.com/maxlcoder/homework-backend/model"
+	"github.com/maxlcoder/homework-backend/pkg/lifecycle"
+	"github.com/maxlcoder/homework-backend/pkg/logger"
+	"github.com/maxlcoder/homework-backend/pkg/metrics"
+	"github.com/maxlcoder/homework-backend/pkg/tracing"
+	"github.com/maxlcoder/homework-backend/pkg/validator"
+	"github.com/maxlcoder/homework-backend/service"
+	_ "github.com/spf13/viper/remote"
+)
+
+// ServeOptions 服务启动选项
+type ServeOptions struct {
+	Port int  // 监听端口
+	Seed bool // 启动前同步初始化数据（超管、权限、菜单及 casbin 规则）
+}
+
+// Init 加载配置并初始化日志，configFile 为空时读取 ./config/config.yaml，配置校验失败时返回错误
+func Init(configFile string) error {
+	config.SetFile(configFile)
+	config.Init()
+	// 结构化日志
+	logger.Init(config.Conf.Log)
+	// 优雅关闭
+	lifecycle.Init(config.Conf.Shutdown)
+	// 密码哈希配置错误时拒绝启动，避免运行时退回其他算法
+	return model.ValidateHasher()
+}
+
+// InitDB 初始化数据库连接、kafka 与 casbin，数据库结构不是最新版本时返回错误
+func InitDB() (*casbin.SyncedEnforcer, error) {
+	// 数据连接初始化
+	if err := database.InitDB(); err != nil {
+		return nil, fmt.Errorf("数据库连接初始化失败：%w", err)
+	}
+	// 数据库结构需先通过 migrate up 迁移到最新版本
+	migrator, err := migrate.New(database.DB)
+	if err != nil {
+		return nil, err
+	}
+	if err := migrator.Check(); err != nil {
+		return nil, err
+	}
+	// kafka 初始化，casbin watcher 通过 kafka 收发规则变更通知，需在 casbin 之前
+	kafka.InitProducer(config.Conf.Kafka.Brokers)
+	kafka.InitConsumer(config.Conf.Kafka.Brokers)
+	// casbin 初始化
+	enforcer, err := service.NewCasbin(database.DB)
+	if err != nil {
+		return nil, fmt.Errorf("Casbin 初始化失败：%w", err)
+	}
+	return enforcer, nil
+}
+
+// NewRouter 初始化参数校验并注册全部路由
+func NewRouter(enforcer *casbin.SyncedEnforcer) *gin.Engine {
+	// 参数校验翻译
+	validator.InitValidator()
+
+	// Disable Console Color
+	// gin.DisableConsoleColor()
+	// 访问日志由 middleware.Logger 以 JSON 输出，不使用 gin 默认日志
+	r := gin.New()
+
+	// 全局中间件只在此处注册，作用于之后注册的全部路由：
+	// 请求 ID 与访问日志在最前，panic 恢复、链路、指标、CORS 与错误处理产生的日志都能关联请求，panic 的请求也会记录访问日志
+	r.Use(
+		middleware.Logger(),
+		gin.Recovery(),
+		middleware.Tracing(),
+		middleware.Metrics(),
+		middleware.Cors(),
+		middleware.ErrorHandler(),
+	)
+
+	// 替换 Gin JSON 渲染器
+	//r.JSON = jsoniter.ConfigCompatibleWithStandardLibrary
+
+	// Ping test
+	r.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	// 存活与就绪检查
+	route.HealthRoutes(r)
+	// Prometheus 指标
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	route.ApiRoutes(r, enforcer)
+	return r
+}
+
+// Serve 初始化全部依赖并启动 HTTP 服务，收到退出信号后优雅关闭
+func Serve(opts ServeOptions) error {
+	// 链路追踪
+	if err := tracing.Init(config.Conf.Tracing); err != nil {
+		return fmt.Errorf("链路追踪初始化失败：%w", err)
+	}
+	enforcer, err := InitDB()
+	if err != nil {
+		return err
+	}
+	r := NewRouter(enforcer)
+	if opts.Seed {
+		if _, err := seed.Run(database.DB, r, enforcer, false); err != nil {
+			return fmt.Errorf("数据库初始化失败：%w", err)
+		}
+	}
+	registerLifecycle(r, fmt.Sprintf(":%d", opts.Port))
+	return lifecycle.Run()
+}
+
+// 注册生命周期钩子，收到 SIGTERM 后依次：停止接收请求并等待处理中的请求、停止 kafka 消费、停止模块后台任务、发送剩余 kafka 消息、关闭数据库、上报剩余链路数据
+func registerLifecycle(r *gin.Engine, addr string) {
+	srv := &http.Server{Addr: addr, Handler: r}
+	lifecycle.Register(lifecycle.Hook{
+		Name:  "http",
+		Phase: lifecycle.PhaseHttp,
+		OnStart: func(ctx context.Context) error {
+			ln, err := net.Listen("tcp", srv.Addr)
+			if err != nil {
+				return err
+			}
+			go func() {
+				if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					slog.Error("http server error", "error", err)
+					lifecycle.Stop()
+				}
+			}()
+			slog.Info("http server listening", "addr", srv.Addr)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			if err := srv.Shutdown(ctx); err != nil {
+				// 超时后强制断开剩余连接
+				_ = srv.Close()
+				return err
+			}
+			return nil
+		},
+	})
+	lifecycle.Register(lifecycle.Hook{
+		Name:   "kafka-consumer",
+		Phase:  lifecycle.PhaseConsumer,
+		OnStop: kafka.StopConsumers,
+	})
+	for _, entry := range contract.GetAllLifecycles() {
+		lifecycle.Register(lifecycle.Hook{
+			Name:    entry.Name,
+			Phase:   lifecycle.PhaseModule,
+			OnStart: entry.Lifecycle.OnStart,
+			OnStop:  entry.Lifecycle.OnStop,
+		})
+	}
+	lifecycle.Register(lifecycle.Hook{
+		Name:   "kafka-producer",
+		Phase:  lifecycle.PhaseProducer,
+		OnStop: kafka.CloseProducers,
+	})
+	lifecycle.Register(lifecycle.Hook{
+		Name:  "database",
+		Phase: lifecycle.PhaseDatabase,
+		OnStop: func(ctx context.Context) error {
+			return database.Close()
+		},
+	})
+	lifecycle.Register(lifecycle.Hook{
+		Name:   "tracing",
+		Phase:  lifecycle.PhaseTelemetry,
+		OnStop: tracing.Shutdown,
+	})
+}
@@ -0,0 +1,65 @@
+package contract
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// HealthCheck 就绪检查项，Check 返回错误表示该依赖不可用
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// HealthChecker 健康检查提供者接口，模块实现后其检查项加入 /readyz
+type HealthChecker interface {
+	// HealthChecks 返回模块的就绪检查项
+	HealthChecks() []HealthCheck
+}
+
+// healthRegistry 健康检查提供者注册表
+var (
+	healthRegistry = make(map[string]HealthChecker)
+	healthMutex    sync.RWMutex
+)
+
+// RegisterHealthChecker 注册健康检查提供者
+// name: 提供者名称（模块名称或系统依赖名称）
+// checker: 健康检查提供者实例
+func RegisterHealthChecker(name string, checker HealthChecker) {
+	healthMutex.Lock()
+	defer healthMutex.Unlock()
+	healthRegistry[name] = checker
+}
+
+// RegisterHealthCheck 注册单个检查项，name 即检查项名称
+func RegisterHealthCheck(name string, check func(ctx context.Context) error) {
+	RegisterHealthChecker(name, singleHealthCheck{HealthCheck{Name: name, Check: check}})
+}
+
+type singleHealthCheck struct {
+	check HealthCheck
+}
+
+func (s singleHealthCheck) HealthChecks() []HealthCheck {
+	return []HealthCheck{s.check}
+}
+
+// GetAllHealthChecks 获取所有检查项，按提供者名称排序
+func GetAllHealthChecks() []HealthCheck {
+	healthMutex.RLock()
+	defer healthMutex.RUnlock()
+
+	names := make([]string, 0, len(healthRegistry))
+	for name := range healthRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var checks []HealthCheck
+	for _, name := range names {
+		checks = append(checks, healthRegistry[name].HealthChecks()...)
+	}
+	return checks
+}
@@ -0,0 +1,54 @@
+package contract
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Lifecycle 生命周期钩子接口，模块实现后在服务开始监听前执行 OnStart，关闭时执行 OnStop
+type Lifecycle interface {
+	// OnStart 启动模块后台任务
+	OnStart(ctx context.Context) error
+	// OnStop 停止模块后台任务，ctx 到期前需返回
+	OnStop(ctx context.Context) error
+}
+
+// lifecycleRegistry 生命周期钩子注册表
+var (
+	lifecycleRegistry = make(map[string]Lifecycle)
+	lifecycleMutex    sync.RWMutex
+)
+
+// RegisterLifecycle 注册生命周期钩子
+// name: 模块名称
+// lifecycle: 生命周期钩子实例
+func RegisterLifecycle(name string, lifecycle Lifecycle) {
+	lifecycleMutex.Lock()
+	defer lifecycleMutex.Unlock()
+	lifecycleRegistry[name] = lifecycle
+}
+
+// LifecycleEntry 已注册的生命周期钩子
+type LifecycleEntry struct {
+	Name      string
+	Lifecycle Lifecycle
+}
+
+// GetAllLifecycles 获取所有生命周期钩子，按模块名称排序
+func GetAllLifecycles() []LifecycleEntry {
+	lifecycleMutex.RLock()
+	defer lifecycleMutex.RUnlock()
+
+	names := make([]string, 0, len(lifecycleRegistry))
+	for name := range lifecycleRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := make([]LifecycleEntry, 0, len(names))
+	for _, name := range names {
+		entries = append(entries, LifecycleEntry{Name: name, Lifecycle: lifecycleRegistry[name]})
+	}
+	return entries
+}
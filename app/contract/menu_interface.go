@@ -1,6 +1,7 @@
 package contract
 
 import (
+	"sort"
 	"sync"
 
 	"github.com/maxlcoder/homework-backend/app/modules/core/model"
@@ -88,6 +89,19 @@ func GetAllMenuProviders() []MenuProvider {
 	return providers
 }
 
+// GetMenuProviderNames 获取所有菜单提供者名称（即模块名称），按名称排序
+func GetMenuProviderNames() []string {
+	menuMutex.RLock()
+	defer menuMutex.RUnlock()
+
+	names := make([]string, 0, len(menuRegistry))
+	for name := range menuRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // GetMenuProviderByName 按名称获取菜单提供者
 func GetMenuProviderByName(name string) MenuProvider {
 	menuMutex.RLock()
@@ -0,0 +1,45 @@
+package contract
+
+import (
+	"sort"
+	"sync"
+)
+
+// TenantDataProvider 租户数据提供者接口，模块实现后删除租户前检查其中是否仍有该租户的数据
+type TenantDataProvider interface {
+	// TenantModels 返回模块中按 tenant_id 隔离的数据表模型
+	TenantModels() []interface{}
+}
+
+// tenantDataRegistry 租户数据提供者注册表
+var (
+	tenantDataRegistry = make(map[string]TenantDataProvider)
+	tenantDataMutex    sync.RWMutex
+)
+
+// RegisterTenantDataProvider 注册租户数据提供者
+// name: 模块名称
+// provider: 租户数据提供者实例
+func RegisterTenantDataProvider(name string, provider TenantDataProvider) {
+	tenantDataMutex.Lock()
+	defer tenantDataMutex.Unlock()
+	tenantDataRegistry[name] = provider
+}
+
+// GetAllTenantModels 获取所有模块的租户数据表模型，按模块名称排序
+func GetAllTenantModels() []interface{} {
+	tenantDataMutex.RLock()
+	defer tenantDataMutex.RUnlock()
+
+	names := make([]string, 0, len(tenantDataRegistry))
+	for name := range tenantDataRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var models []interface{}
+	for _, name := range names {
+		models = append(models, tenantDataRegistry[name].TenantModels()...)
+	}
+	return models
+}
@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	core_model "github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/app/response"
+	"github.com/maxlcoder/homework-backend/pkg/tenant"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// AuditResource 审计资源：路由前缀对应的数据模型，用于记录目标数据变更前后的值
+type AuditResource struct {
+	Schema *schema.Schema
+	Omit   []string // 不记录的字段
+}
+
+// 所有资源都不记录的字段
+var auditOmitColumns = []string{"password", "mfa_secret", "updated_at"}
+
+// 不改变数据的写方法接口，不记录
+var auditIgnoredRoutes = []string{"/admin/permissions:check"}
+
+var (
+	auditResources   = map[string]AuditResource{}
+	auditResourcesMu sync.RWMutex
+	auditSchemas     sync.Map
+)
+
+// RegisterAuditResource 注册审计资源，prefix 为路由前缀（如 /admin/roles），其下带 :id 的写操作按 id 记录该模型数据的变化
+// 快照通过模型查询，按租户隔离的模型由 tenant 插件限定租户；模型字段带 sensitive 标签时按未授权打码后记录
+func RegisterAuditResource(prefix string, model interface{}, omit ...string) {
+	s, err := schema.Parse(model, &auditSchemas, schema.NamingStrategy{})
+	if err != nil {
+		panic(fmt.Sprintf("审计资源 %s 模型解析失败：%v", prefix, err))
+	}
+	auditResourcesMu.Lock()
+	defer auditResourcesMu.Unlock()
+	auditResources[strings.TrimSuffix(prefix, "/")] = AuditResource{Schema: s, Omit: omit}
+}
+
+// Table 资源对应的数据表
+func (r AuditResource) Table() string {
+	if r.Schema == nil {
+		return ""
+	}
+	return r.Schema.Table
+}
+
+// 按最长前缀匹配路由对应的资源
+func auditResourceOf(route string) (AuditResource, bool) {
+	auditResourcesMu.RLock()
+	defer auditResourcesMu.RUnlock()
+	var matched string
+	for prefix := range auditResources {
+		if (route == prefix || strings.HasPrefix(route, prefix+"/")) && len(prefix) > len(matched) {
+			matched = prefix
+		}
+	}
+	resource, ok := auditResources[matched]
+	return resource, ok && matched != ""
+}
+
+// AuditChange 字段变更前后的值，新增时 before 为空，删除时 after 为空
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// 记录响应内容，用于取新增数据的 ID
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	// 只需解析响应中的 data.id，超出部分不保留
+	if w.body.Len() < 64*1024 {
+		w.body.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// AuditMiddleware 记录写操作的操作人、租户、路由、目标 ID、变更前后差异、IP 与 User-Agent，需在登录认证之后使用
+func AuditMiddleware(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		method := c.Request.Method
+		route := c.FullPath()
+		if method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions || lo.Contains(auditIgnoredRoutes, route) {
+			c.Next()
+			return
+		}
+
+		resource, hasResource := auditResourceOf(route)
+		targetId := c.Param("id")
+		var before map[string]interface{}
+		if hasResource && targetId != "" {
+			before = auditSnapshot(c, db, resource, targetId)
+		}
+
+		writer := &auditResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		var after map[string]interface{}
+		if hasResource {
+			// 新增时目标 ID 取自响应
+			if targetId == "" && method == http.MethodPost {
+				targetId = auditCreatedId(writer.body.Bytes())
+			}
+			if targetId != "" {
+				after = auditSnapshot(c, db, resource, targetId)
+			}
+		}
+
+		// 请求被拒绝（如越权访问其他租户的数据）时不记录目标数据
+		changes := ""
+		if writer.Status() < http.StatusBadRequest {
+			changes = auditChanges(before, after)
+		}
+
+		auditLog := core_model.AuditLog{
+			AdminId:   c.GetUint("login_admin_id"),
+			RoleId:    c.GetUint("login_admin_role_id"),
+			Route:     route,
+			Path:      lo.Substring(c.Request.URL.Path, 0, 255),
+			Method:    method,
+			Resource:  resource.Table(),
+			TargetId:  targetId,
+			Changes:   changes,
+			Status:    writer.Status(),
+			Ip:        c.ClientIP(),
+			UserAgent: lo.Substring(c.Request.UserAgent(), 0, 255),
+		}
+		auditLog.TenantID = c.GetUint("login_admin_tenant_id")
+		// 租户取自登录信息，未登录的请求（如登录、找回密码）按系统任务写入
+		ctx := c.Request.Context()
+		if _, ok := tenant.FromContext(ctx); !ok {
+			ctx = tenant.System(ctx)
+		}
+		if err := db.WithContext(ctx).Create(&auditLog).Error; err != nil {
+			slog.ErrorContext(c.Request.Context(), "audit log save failed", "route", route, "error", err)
+		}
+	}
+}
+
+// 目标数据当前的值，不存在或不属于当前租户时返回 nil
+func auditSnapshot(c *gin.Context, db *gorm.DB, resource AuditResource, id string) map[string]interface{} {
+	ctx := c.Request.Context()
+	value := reflect.New(resource.Schema.ModelType)
+	result := db.WithContext(ctx).Where("id = ?", id).Limit(1).Find(value.Interface())
+	if result.Error != nil {
+		slog.ErrorContext(ctx, "audit snapshot failed", "table", resource.Table(), "error", result.Error)
+		return nil
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+	// 审计日志不保留敏感字段明文
+	response.MaskSensitive(value.Interface(), response.NewFieldGrants())
+
+	row := map[string]interface{}{}
+	for _, field := range resource.Schema.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		fieldValue := reflect.Indirect(field.ReflectValueOf(ctx, value.Elem()))
+		if fieldValue.IsValid() {
+			row[field.DBName] = fieldValue.Interface()
+		} else {
+			row[field.DBName] = nil
+		}
+	}
+	for _, column := range append(auditOmitColumns, resource.Omit...) {
+		delete(row, column)
+	}
+	return row
+}
+
+// 从统一响应 {"data": {"id": 1}} 中取新增数据的 ID
+func auditCreatedId(body []byte) string {
+	var resp struct {
+		Data struct {
+			ID json.Number `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return ""
+	}
+	return resp.Data.ID.String()
+}
+
+// 变更字段前后的值 JSON，无变化时为空
+func auditChanges(before map[string]interface{}, after map[string]interface{}) string {
+	changes := map[string]AuditChange{}
+	for _, key := range lo.Union(lo.Keys(before), lo.Keys(after)) {
+		b, a := before[key], after[key]
+		if before != nil && after != nil && fmt.Sprint(b) == fmt.Sprint(a) {
+			continue
+		}
+		changes[key] = AuditChange{Before: b, After: a}
+	}
+	if len(changes) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glebarez/sqlite"
+	core_model "github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/pkg/tenant"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 租户 2 的角色 2、租户 3 的角色 3，管理员 1
+func newAuditTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&core_model.Role{}, &core_model.Admin{}, &core_model.AuditLog{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(tenant.NewPlugin()); err != nil {
+		t.Fatal(err)
+	}
+	system := db.WithContext(tenant.System(context.Background()))
+	roles := []core_model.Role{{Name: "tenant_2_staff"}, {Name: "tenant_3_staff"}}
+	roles[0].ID, roles[0].TenantID = 2, 2
+	roles[1].ID, roles[1].TenantID = 3, 3
+	admin := core_model.Admin{Name: "admin", Email: "admin@homework.com"}
+	admin.ID = 1
+	for _, record := range []interface{}{&roles, &admin} {
+		if err := system.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// 模拟已登录租户 2 的管理员，handler 按请求修改目标数据
+func newAuditTestRouter(db *gorm.DB, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	RegisterAuditResource("/admin/roles", &core_model.Role{})
+	RegisterAuditResource("/admin/admins", &core_model.Admin{})
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(tenant.WithTenant(c.Request.Context(), 2))
+		c.Set("login_admin_id", uint(1))
+		c.Set("login_admin_tenant_id", uint(2))
+		c.Next()
+	})
+	r.Use(AuditMiddleware(db))
+	r.PUT("/admin/roles/:id", handler)
+	r.PUT("/admin/admins/:id", handler)
+	return r
+}
+
+func lastAuditLog(t *testing.T, db *gorm.DB) core_model.AuditLog {
+	t.Helper()
+	var auditLog core_model.AuditLog
+	if err := db.WithContext(tenant.System(context.Background())).Order("id desc").First(&auditLog).Error; err != nil {
+		t.Fatal(err)
+	}
+	return auditLog
+}
+
+func TestAuditSnapshotScopesTenant(t *testing.T) {
+	db := newAuditTestDB(t)
+	r := newAuditTestRouter(db, func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/admin/roles/3", nil))
+	auditLog := lastAuditLog(t, db)
+	if auditLog.TenantID != 2 || auditLog.Resource != "roles" || auditLog.TargetId != "3" {
+		t.Fatalf("got %+v", auditLog)
+	}
+	if strings.Contains(auditLog.Changes, "tenant_3_staff") {
+		t.Fatalf("不应记录其他租户的数据, got %s", auditLog.Changes)
+	}
+}
+
+func TestAuditSnapshotRecordsChanges(t *testing.T) {
+	db := newAuditTestDB(t)
+	r := newAuditTestRouter(db, func(c *gin.Context) {
+		tx := db.WithContext(c.Request.Context())
+		if c.FullPath() == "/admin/roles/:id" {
+			tx.Model(&core_model.Role{}).Where("id = ?", c.Param("id")).Update("name", "tenant_2_manager")
+		} else {
+			tx.Model(&core_model.Admin{}).Where("id = ?", c.Param("id")).Update("email", "boss@homework.com")
+		}
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/admin/roles/2", nil))
+	changes := lastAuditLog(t, db).Changes
+	if !strings.Contains(changes, `"name":{"before":"tenant_2_staff","after":"tenant_2_manager"}`) {
+		t.Fatalf("应记录本租户数据的变更, got %s", changes)
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/admin/admins/1", nil))
+	changes = lastAuditLog(t, db).Changes
+	if strings.Contains(changes, "admin@homework.com") || strings.Contains(changes, "boss@homework.com") {
+		t.Fatalf("敏感字段不应记录明文, got %s", changes)
+	}
+	if !strings.Contains(changes, `"email":{"before":"a***@homework.com","after":"b***@homework.com"}`) {
+		t.Fatalf("敏感字段应打码后记录, got %s", changes)
+	}
+}
+
+func TestAuditSkipsChangesOfRejectedRequest(t *testing.T) {
+	db := newAuditTestDB(t)
+	r := newAuditTestRouter(db, func(c *gin.Context) {
+		c.JSON(http.StatusForbidden, gin.H{})
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/admin/admins/1", nil))
+	auditLog := lastAuditLog(t, db)
+	if auditLog.Status != http.StatusForbidden || auditLog.Changes != "" {
+		t.Fatalf("被拒绝的请求不应记录目标数据, got %+v", auditLog)
+	}
+}
@@ -3,17 +3,24 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
-	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/pkg/metrics"
 	"github.com/maxlcoder/homework-backend/pkg/response"
 )
 
-func CasbinMiddleware(e *casbin.Enforcer) gin.HandlerFunc {
+// IsPersonalPath 个人中心与自身权限查询接口只涉及当前账号，登录即可访问
+func IsPersonalPath(path string) bool {
+	return path == "/admin/me" || strings.HasPrefix(path, "/admin/me/") || path == "/admin/logout" || path == "/admin/permissions:check"
+}
+
+func CasbinMiddleware(e *casbin.SyncedEnforcer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 角色信息获取
-		value, ok := c.Get("login_admin_role")
+		// 当前角色与租户由 identityHandler 从令牌中取出
+		value, ok := c.Get("login_admin_role_id")
 		if !ok {
 			response.Unauthorized(c, "请重新登录")
 			c.Abort()
@@ -21,16 +28,22 @@ func CasbinMiddleware(e *casbin.Enforcer) gin.HandlerFunc {
 		}
 		method := c.Request.Method
 		path := c.FullPath()
-		fmt.Println(path)
 
-		// 获取当前用户的角色、
-		role, ok := value.(model.Role)
-		if !ok {
-			response.Unauthorized(c, "权限不足")
+		if IsPersonalPath(path) {
+			c.Next()
+			return
+		}
+
+		roleId, ok := value.(uint)
+		if !ok || roleId == 0 {
+			response.Error(c, http.StatusForbidden, "权限不足")
 			c.Abort()
 			return
 		}
-		ok, err := e.Enforce(fmt.Sprintf("role_%d", role.ID), fmt.Sprintf("%d", role.TenantId), path, method)
+		tenantId := c.GetUint("login_admin_tenant_id")
+		start := time.Now()
+		ok, err := e.Enforce(fmt.Sprintf("role_%d", roleId), fmt.Sprintf("%d", tenantId), path, method)
+		metrics.ObserveCasbinEnforce("middleware", start, ok, err)
 		if err != nil {
 			response.Error(c, http.StatusInternalServerError, err.Error())
 			c.Abort()
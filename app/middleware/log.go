@@ -1,15 +1,60 @@
 package middleware
 
 import (
-	"fmt"
+	"log/slog"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/maxlcoder/homework-backend/pkg/logger"
 )
 
+// 上游传入的请求 ID 最大长度，超出时重新生成
+const maxRequestIdLength = 64
+
+// Logger 请求 ID 与访问日志：沿用或生成 X-Request-ID，绑定到请求 context 供 SQL、kafka 等日志关联，请求结束后记录访问日志
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		fmt.Println("Applying logger middleware")
-		// 实际项目中可以添加日志记录逻辑
+		start := time.Now()
+		requestId := c.GetHeader(logger.RequestIdHeader)
+		if requestId == "" || len(requestId) > maxRequestIdLength {
+			requestId = logger.NewRequestId()
+		}
+		c.Set("request_id", requestId)
+		c.Header(logger.RequestIdHeader, requestId)
+		c.Request = c.Request.WithContext(logger.WithRequestId(c.Request.Context(), requestId))
+
 		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", c.FullPath()),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+			slog.String("ip", c.ClientIP()),
+		}
+		if adminId := c.GetUint("login_admin_id"); adminId > 0 {
+			attrs = append(attrs, slog.Uint64("admin_id", uint64(adminId)))
+		}
+		if userId := c.GetUint("user_id"); userId > 0 {
+			attrs = append(attrs, slog.Uint64("user_id", uint64(userId)))
+		}
+		if tenantId, ok := c.Get("login_admin_tenant_id"); ok {
+			if tenantId, ok := tenantId.(uint); ok {
+				attrs = append(attrs, slog.Uint64("tenant_id", uint64(tenantId)))
+			}
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		} else if status >= 400 {
+			level = slog.LevelWarn
+		}
+		slog.LogAttrs(c.Request.Context(), level, "request", attrs...)
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
@@ -21,7 +22,10 @@ import (
 type AdminController struct {
 	BaseController
 	// 集成服务
-	adminService service.AdminServiceInterface
+	adminService      service.AdminServiceInterface
+	loginGuardService service.LoginGuardServiceInterface
+	adminMfaService   service.AdminMfaServiceInterface
+	passwordService   service.PasswordServiceInterface
 }
 
 // GetParamUint 获取uint类型参数
@@ -34,9 +38,12 @@ func (controller *AdminController) GetParamUint(c *gin.Context, param string) (u
 	return uint(id), nil
 }
 
-func NewAdminController(adminService service.AdminServiceInterface) *AdminController {
+func NewAdminController(adminService service.AdminServiceInterface, loginGuardService service.LoginGuardServiceInterface, adminMfaService service.AdminMfaServiceInterface, passwordService service.PasswordServiceInterface) *AdminController {
 	return &AdminController{
-		adminService: adminService,
+		adminService:      adminService,
+		loginGuardService: loginGuardService,
+		adminMfaService:   adminMfaService,
+		passwordService:   passwordService,
 	}
 }
 
@@ -56,12 +63,18 @@ func (controller *AdminController) Register(c *gin.Context) {
 		controller.Error(c, 400, "数据获取失败")
 		return
 	}
+	// 密码策略校验
+	if err := service.ValidatePassword(admin.Password); err != nil {
+		controller.Error(c, 400, err.Error())
+		return
+	}
 	// 密码 hash 处理
 	admin.Password, err = base_model.HashPassword(admin.Password)
 	if err != nil {
 		controller.Error(c, 400, "密码处理失败")
 		return
 	}
+	admin.PasswordChangedAt = lo.ToPtr(time.Now())
 	_, err = controller.adminService.Create(&admin, nil)
 	if err != nil {
 		controller.Error(c, 400, fmt.Errorf("注册失败：%w", err).Error())
@@ -73,13 +86,13 @@ func (controller *AdminController) Register(c *gin.Context) {
 
 func (controller *AdminController) Me(c *gin.Context) {
 	adminId, _ := c.Get("login_admin_id")
-	admin, _ := controller.adminService.FindById(adminId.(uint))
+	admin, _ := controller.adminService.WithContext(c.Request.Context()).FindById(adminId.(uint))
 	var meResponse response.MeResponse
 	copier.Copy(&meResponse, &admin)
 
 	// 补充当前账号对应角色的菜单
 	// 获取角色全部菜单
-	menus, _ := controller.adminService.GetMenusWithChildrenByRoleId(admin.RoleId)
+	menus, _ := controller.adminService.WithContext(c.Request.Context()).GetMenusWithChildrenByRoleId(c.GetUint("login_admin_role_id"))
 	// 菜单 -> tree
 	meResponse.Menus = response.TreesToResponse(menus)
 	controller.Success(c, meResponse)
@@ -93,14 +106,14 @@ func (controller *AdminController) Page(c *gin.Context) {
 	}
 
 	// 分页查询
-	admins, count, err := controller.adminService.Page(pageRequest)
+	admins, count, err := controller.adminService.WithContext(c.Request.Context()).Page(pageRequest)
 	if err != nil {
 		controller.Error(c, http.StatusBadRequest, fmt.Errorf("获取管理员列表失败：%w", err).Error())
 		return
 	}
 
 	// 分页响应
-	pageResponse := base_response.BuildPageResponse[model.Admin, response.AdminResponse](admins, count, pageRequest.Page, pageRequest.PerPage)
+	pageResponse := base_response.BuildPageResponse[model.Admin, response.AdminResponse](admins, count, pageRequest.Page, pageRequest.PerPage, controller.FieldGrants(c))
 
 	controller.Success(c, pageResponse)
 }
@@ -136,16 +149,22 @@ func (controller *AdminController) Store(c *gin.Context) {
 
 	// 密码处理
 	if admin.Password != "" {
+		// 密码策略校验
+		if err := service.ValidatePassword(admin.Password); err != nil {
+			controller.Error(c, 400, err.Error())
+			return
+		}
 		// 密码 hash 处理
 		admin.Password, err = base_model.HashPassword(admin.Password)
 		if err != nil {
 			controller.Error(c, 400, "密码处理失败")
 			return
 		}
+		admin.PasswordChangedAt = lo.ToPtr(time.Now())
 	}
 
 	// service 处理
-	createdAdmin, err := controller.adminService.Create(&admin, roles)
+	createdAdmin, err := controller.adminService.WithContext(c.Request.Context()).Create(&admin, roles)
 	if err != nil {
 		controller.Error(c, http.StatusBadRequest, fmt.Errorf("新增失败：%w", err).Error())
 		return
@@ -182,8 +201,8 @@ func (controller *AdminController) Update(c *gin.Context) {
 		return
 	}
 
-	// 查询管理员
-	admin, err := controller.adminService.FindById(id)
+	// 查询管理员，只能查询本租户的账号
+	admin, err := controller.adminService.WithContext(c.Request.Context()).FindById(id)
 	if err != nil {
 		controller.Error(c, http.StatusNotFound, "管理员不存在")
 		return
@@ -205,16 +224,22 @@ func (controller *AdminController) Update(c *gin.Context) {
 
 	// 密码处理
 	if admin.Password != "" {
+		// 密码策略校验
+		if err := service.ValidatePassword(admin.Password); err != nil {
+			controller.Error(c, 400, err.Error())
+			return
+		}
 		// 密码 hash 处理
 		admin.Password, err = base_model.HashPassword(admin.Password)
 		if err != nil {
 			controller.Error(c, 400, "密码处理失败")
 			return
 		}
+		admin.PasswordChangedAt = lo.ToPtr(time.Now())
 	}
 
 	// service 处理
-	updatedAdmin, err := controller.adminService.Update(admin, roles)
+	updatedAdmin, err := controller.adminService.WithContext(c.Request.Context()).Update(admin, roles)
 	if err != nil {
 		controller.Error(c, http.StatusBadRequest, fmt.Errorf("更新失败：%w", err).Error())
 		return
@@ -244,7 +269,7 @@ func (controller *AdminController) Destroy(c *gin.Context) {
 		return
 	}
 
-	err = controller.adminService.Delete(id)
+	err = controller.adminService.WithContext(c.Request.Context()).Delete(id)
 	if err != nil {
 		controller.Error(c, http.StatusBadRequest, fmt.Errorf("删除失败：%w", err).Error())
 		return
@@ -261,14 +286,196 @@ func (controller *AdminController) Show(c *gin.Context) {
 		return
 	}
 
-	admin, err := controller.adminService.FindById(id)
+	admin, err := controller.adminService.WithContext(c.Request.Context()).FindById(id)
 	if err != nil {
 		controller.Error(c, http.StatusNotFound, err.Error())
 		return
 	}
 
-	var adminResponse response.AdminResponse
-	copier.Copy(&adminResponse, &admin)
+	adminResponse := base_response.ConvertModelToResponse[*model.Admin, response.AdminResponse](admin, controller.FieldGrants(c))
 
 	controller.Success(c, adminResponse)
 }
+
+// Unlock 解锁因登录失败被锁定的管理员账号
+func (controller *AdminController) Unlock(c *gin.Context) {
+	id, err := controller.GetParamUint(c, "id")
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, "无效的管理员ID")
+		return
+	}
+
+	admin, err := controller.adminService.WithContext(c.Request.Context()).FindById(id)
+	if err != nil {
+		controller.Error(c, http.StatusNotFound, "管理员不存在")
+		return
+	}
+
+	operatorId := c.GetUint("login_admin_id")
+	err = controller.loginGuardService.Unlock("Admin", admin.ID, admin.Name, operatorId)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, nil)
+}
+
+// 当前登录管理员
+func (controller *AdminController) loginAdmin(c *gin.Context) (*model.Admin, error) {
+	return controller.adminService.WithContext(c.Request.Context()).FindById(c.GetUint("login_admin_id"))
+}
+
+// EnrollMfa 绑定两步验证，返回密钥与 otpauth:// 地址，需调用 ConfirmMfa 确认后生效
+func (controller *AdminController) EnrollMfa(c *gin.Context) {
+	admin, err := controller.loginAdmin(c)
+	if err != nil {
+		controller.Error(c, http.StatusUnauthorized, "当前用户信息异常")
+		return
+	}
+
+	enrollment, err := controller.adminMfaService.Enroll(admin)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, enrollment)
+}
+
+// ConfirmMfa 确认绑定，返回恢复码（仅展示一次）
+func (controller *AdminController) ConfirmMfa(c *gin.Context) {
+	var codeRequest request.AdminMfaCodeRequest
+	if errs, ok := validator.BindAndValidateFirst(c, &codeRequest); !ok {
+		controller.Error(c, http.StatusBadRequest, errs)
+		return
+	}
+
+	admin, err := controller.loginAdmin(c)
+	if err != nil {
+		controller.Error(c, http.StatusUnauthorized, "当前用户信息异常")
+		return
+	}
+
+	codes, err := controller.adminMfaService.Confirm(admin, codeRequest.Code)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, gin.H{"recovery_codes": codes})
+}
+
+// DisableMfa 关闭两步验证，所属租户强制要求时不允许关闭
+func (controller *AdminController) DisableMfa(c *gin.Context) {
+	var codeRequest request.AdminMfaCodeRequest
+	if errs, ok := validator.BindAndValidateFirst(c, &codeRequest); !ok {
+		controller.Error(c, http.StatusBadRequest, errs)
+		return
+	}
+
+	admin, err := controller.loginAdmin(c)
+	if err != nil {
+		controller.Error(c, http.StatusUnauthorized, "当前用户信息异常")
+		return
+	}
+
+	err = controller.adminMfaService.Disable(admin, codeRequest.Code)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, nil)
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (controller *AdminController) RegenerateRecoveryCodes(c *gin.Context) {
+	var codeRequest request.AdminMfaCodeRequest
+	if errs, ok := validator.BindAndValidateFirst(c, &codeRequest); !ok {
+		controller.Error(c, http.StatusBadRequest, errs)
+		return
+	}
+
+	admin, err := controller.loginAdmin(c)
+	if err != nil {
+		controller.Error(c, http.StatusUnauthorized, "当前用户信息异常")
+		return
+	}
+
+	codes, err := controller.adminMfaService.RegenerateRecoveryCodes(admin, codeRequest.Code)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, gin.H{"recovery_codes": codes})
+}
+
+// ChangePassword 修改当前管理员密码
+func (controller *AdminController) ChangePassword(c *gin.Context) {
+	var changeRequest request.PasswordChangeRequest
+	if errs, ok := validator.BindAndValidateFirst(c, &changeRequest); !ok {
+		controller.Error(c, http.StatusBadRequest, errs)
+		return
+	}
+
+	err := controller.passwordService.WithContext(c.Request.Context()).Change("Admin", c.GetUint("login_admin_id"), changeRequest.OldPassword, changeRequest.NewPassword)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, nil)
+}
+
+// ForgotPassword 找回密码，重置令牌通过通知服务发送，无论账号是否存在均返回成功
+func (controller *AdminController) ForgotPassword(c *gin.Context) {
+	var forgotRequest request.PasswordForgotRequest
+	if errs, ok := validator.BindAndValidateFirst(c, &forgotRequest); !ok {
+		controller.Error(c, http.StatusBadRequest, errs)
+		return
+	}
+
+	if err := controller.passwordService.WithContext(c.Request.Context()).Forgot("Admin", forgotRequest.Account); err != nil {
+		controller.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	controller.Success(c, nil)
+}
+
+// ResetPassword 使用重置令牌设置新密码
+func (controller *AdminController) ResetPassword(c *gin.Context) {
+	var resetRequest request.PasswordResetRequest
+	if errs, ok := validator.BindAndValidateFirst(c, &resetRequest); !ok {
+		controller.Error(c, http.StatusBadRequest, errs)
+		return
+	}
+
+	if err := controller.passwordService.WithContext(c.Request.Context()).Reset("Admin", resetRequest.Token, resetRequest.Password); err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, nil)
+}
+
+// Roles 当前管理员拥有的角色与当前使用的角色
+func (controller *AdminController) Roles(c *gin.Context) {
+	roles, err := controller.adminService.WithContext(c.Request.Context()).GetRoles(c.GetUint("login_admin_id"))
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	roleResponses := lo.Map(roles, func(role model.Role, _ int) response.RoleResponse {
+		var roleResponse response.RoleResponse
+		roleResponse.FromModel(role)
+		return roleResponse
+	})
+	controller.Success(c, gin.H{
+		"current_role_id": c.GetUint("login_admin_role_id"),
+		"roles":           roleResponses,
+	})
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/maxlcoder/homework-backend/app/modules/core/admin/response"
@@ -15,14 +16,16 @@ import (
 type AdminUserController struct {
 	BaseController
 	// 集成服务
-	adminService service.AdminServiceInterface
-	userService  service.UserServiceInterface
+	adminService      service.AdminServiceInterface
+	userService       service.UserServiceInterface
+	loginGuardService service.LoginGuardServiceInterface
 }
 
-func NewAdminUserController(adminService service.AdminServiceInterface, userService service.UserServiceInterface) *AdminUserController {
+func NewAdminUserController(adminService service.AdminServiceInterface, userService service.UserServiceInterface, loginGuardService service.LoginGuardServiceInterface) *AdminUserController {
 	return &AdminUserController{
-		adminService: adminService,
-		userService:  userService,
+		adminService:      adminService,
+		userService:       userService,
+		loginGuardService: loginGuardService,
 	}
 }
 
@@ -53,3 +56,27 @@ func (controller *AdminUserController) Page(c *gin.Context) {
 	controller.Success(c, pageResponse)
 
 }
+
+// Unlock 解锁因登录失败被锁定的用户账号
+func (controller *AdminUserController) Unlock(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, "无效的用户ID")
+		return
+	}
+
+	user, err := controller.userService.GetById(uint(id))
+	if err != nil {
+		controller.Error(c, http.StatusNotFound, "用户不存在")
+		return
+	}
+
+	operatorId := c.GetUint("login_admin_id")
+	err = controller.loginGuardService.Unlock("User", user.ID, user.Name, operatorId)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, nil)
+}
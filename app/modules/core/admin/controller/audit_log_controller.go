@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxlcoder/homework-backend/app/modules/core/admin/request"
+	"github.com/maxlcoder/homework-backend/app/modules/core/admin/response"
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/app/modules/core/service"
+	base_request "github.com/maxlcoder/homework-backend/app/request"
+	base_response "github.com/maxlcoder/homework-backend/app/response"
+	"github.com/maxlcoder/homework-backend/pkg/tenant"
+)
+
+type AuditLogController struct {
+	BaseController
+	// 集成服务
+	auditLogService service.AuditLogServiceInterface
+}
+
+func NewAuditLogController(auditLogService service.AuditLogServiceInterface) *AuditLogController {
+	return &AuditLogController{
+		auditLogService: auditLogService,
+	}
+}
+
+// Page 审计日志按当前租户隔离，平台管理员可显式查询全部租户
+func (controller *AuditLogController) Page(c *gin.Context) {
+	var pageRequest request.AuditLogPageRequest
+	if err := base_request.BindAndSetDefaults(c, &pageRequest); err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := c.Request.Context()
+	if c.Query("all_tenants") == "true" {
+		crossCtx, err := tenant.CrossTenant(ctx, c.GetUint("login_admin_id"), "审计日志跨租户查询")
+		if err != nil {
+			controller.Error(c, http.StatusForbidden, err.Error())
+			return
+		}
+		ctx = crossCtx
+	}
+
+	auditLogs, total, err := controller.auditLogService.WithContext(ctx).Page(pageRequest)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, fmt.Errorf("获取审计日志失败：%w", err).Error())
+		return
+	}
+
+	pageResponse := base_response.BuildPageResponseWithMapper(auditLogs, total, pageRequest.Page, pageRequest.PerPage, func(auditLog model.AuditLog) response.AuditLogResponse {
+		return response.ToAuditLogResponse(auditLog)
+	})
+	controller.Success(c, pageResponse)
+}
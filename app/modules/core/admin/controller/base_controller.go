@@ -2,6 +2,7 @@ package controller
 
 import (
 	"github.com/gin-gonic/gin"
+	base_response "github.com/maxlcoder/homework-backend/app/response"
 	"github.com/maxlcoder/homework-backend/pkg/response"
 )
 
@@ -19,4 +20,12 @@ func (controller *BaseController) Error(c *gin.Context, code int, msg string) {
 	response.Error(c, code, msg)
 }
 
+// FieldGrants 当前角色的字段权限，用于响应中敏感字段的处理
+func (controller *BaseController) FieldGrants(c *gin.Context) base_response.FieldGrants {
+	if grants, ok := c.Get("login_admin_fields"); ok {
+		return grants.(base_response.FieldGrants)
+	}
+	return base_response.FieldGrants{}
+}
+
 // 参数校验
@@ -1,16 +1,19 @@
 package controller
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jinzhu/copier"
+	"github.com/maxlcoder/homework-backend/app/modules/core/admin/request"
 	"github.com/maxlcoder/homework-backend/app/modules/core/admin/response"
 	model2 "github.com/maxlcoder/homework-backend/app/modules/core/model"
 	"github.com/maxlcoder/homework-backend/app/modules/core/service"
+	base_request "github.com/maxlcoder/homework-backend/app/request"
 	base_response "github.com/maxlcoder/homework-backend/app/response"
 	"github.com/maxlcoder/homework-backend/model"
-	"github.com/maxlcoder/homework-backend/pkg/validator"
+	"github.com/samber/lo"
 )
 
 type MenuController struct {
@@ -19,6 +22,16 @@ type MenuController struct {
 	menuService service.MenuServiceInterface
 }
 
+// GetParamUint 获取uint类型参数
+func (controller *MenuController) GetParamUint(c *gin.Context, param string) (uint, error) {
+	str := c.Param(param)
+	id, err := strconv.ParseUint(str, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	return uint(id), nil
+}
+
 func NewMenuController(menuService service.MenuServiceInterface) *MenuController {
 	return &MenuController{
 		menuService: menuService,
@@ -26,137 +39,175 @@ func NewMenuController(menuService service.MenuServiceInterface) *MenuController
 }
 
 func (controller *MenuController) Page(c *gin.Context) {
-	var pagination model.Pagination
-	var filter model2.MenuFilter
-	if err, ok := validator.BindQueryAndValidateAll(c, &pagination); !ok {
-		controller.Error(c, http.StatusBadRequest, err)
+	var pageRequest request.MenuPageRequest
+	if err := base_request.BindAndSetDefaults(c, &pageRequest); err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	_ = c.ShouldBindJSON(&filter)
 
-	total, roles, err := controller.menuService.GetPageByFilter(filter, pagination)
+	filter := model2.MenuFilter{
+		Name: pageRequest.Name,
+	}
+	pagination := model.Pagination{
+		Page:    pageRequest.Page,
+		PerPage: pageRequest.PerPage,
+	}
+	total, menus, err := controller.menuService.GetPageByFilter(filter, pagination)
 	if err != nil {
 		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	var pageResponse base_response.PageResponse[response.RoleResponse]
-	pageResponse.Total = total
-	pageResponse.Page = pagination.Page
-	pageResponse.PerPage = pagination.PerPage
-	var roleResponses []response.RoleResponse
-	err = copier.Copy(&roleResponses, &roles)
+	pageResponse := base_response.BuildPageResponseWithMapper(menus, total, pagination.Page, pagination.PerPage, func(menu model2.Menu) response.MenuResponse {
+		return *response.TreeToResponse(&menu)
+	})
+	controller.Success(c, pageResponse)
+}
+
+// Tree 全部菜单树，含已停用菜单
+func (controller *MenuController) Tree(c *gin.Context) {
+	menus, err := controller.menuService.Tree()
 	if err != nil {
 		controller.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	pageResponse.Data = roleResponses
-	controller.Success(c, pageResponse)
 
+	controller.Success(c, response.TreesToResponse(menus))
 }
 
-func (controller *MenuController) Store(c *gin.Context) {
-	var pagination model.Pagination
-	var filter model2.MenuFilter
-	if err, ok := validator.BindQueryAndValidateAll(c, &pagination); !ok {
-		controller.Error(c, http.StatusBadRequest, err)
+func (controller *MenuController) Show(c *gin.Context) {
+	id, err := controller.GetParamUint(c, "id")
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, "无效的菜单ID")
 		return
 	}
-	_ = c.ShouldBindJSON(&filter)
-	total, users, err := controller.menuService.GetPageByFilter(filter, pagination)
+
+	menu, err := controller.menuService.GetById(id)
 	if err != nil {
+		controller.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+
+	menuResponse := response.NewMenuDetailResponse()
+	menuResponse.FromModel(*menu)
+	controller.Success(c, menuResponse)
+}
+
+func (controller *MenuController) Store(c *gin.Context) {
+	var menuStoreRequest request.MenuStoreRequest
+	if err := base_request.BindAndSetDefaults(c, &menuStoreRequest); err != nil {
 		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	var pageResponse base_response.PageResponse[response.UserResponse]
-	pageResponse.Total = total
-	pageResponse.Page = pagination.Page
-	pageResponse.PerPage = pagination.PerPage
-	var userResponses []response.UserResponse
-	for _, user := range users {
-		userResponses = append(userResponses, response.ToUserResponse(user))
+	menu := model2.Menu{
+		Name:     menuStoreRequest.Name,
+		Number:   menuStoreRequest.Number,
+		ParentID: menuStoreRequest.ParentID,
+		Sort:     menuStoreRequest.Sort,
 	}
-	pageResponse.Data = userResponses
+	permissionIds := lo.Map(menuStoreRequest.Permissions, func(item base_request.IdRequest, index int) uint {
+		return item.Id
+	})
 
-	controller.Success(c, pageResponse)
+	createdMenu, err := controller.menuService.Create(&menu, permissionIds)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, fmt.Errorf("新增失败：%w", err).Error())
+		return
+	}
 
+	controller.Success(c, base_response.DataId{ID: createdMenu.ID})
 }
+
 func (controller *MenuController) Update(c *gin.Context) {
-	var pagination model.Pagination
-	var filter model2.MenuFilter
-	if err, ok := validator.BindQueryAndValidateAll(c, &pagination); !ok {
-		controller.Error(c, http.StatusBadRequest, err)
+	var menuUpdateRequest request.MenuUpdateRequest
+	if err := base_request.BindAndSetDefaults(c, &menuUpdateRequest); err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	_ = c.ShouldBindJSON(&filter)
-	total, users, err := controller.menuService.GetPageByFilter(filter, pagination)
+
+	id, err := controller.GetParamUint(c, "id")
 	if err != nil {
-		controller.Error(c, http.StatusBadRequest, err.Error())
+		controller.Error(c, http.StatusBadRequest, "无效的菜单ID")
+		return
 	}
 
-	var pageResponse base_response.PageResponse[response.UserResponse]
-	pageResponse.Total = total
-	pageResponse.Page = pagination.Page
-	pageResponse.PerPage = pagination.PerPage
-	var userResponses []response.UserResponse
-	for _, user := range users {
-		userResponses = append(userResponses, response.ToUserResponse(user))
+	menu := model2.Menu{
+		Name:     menuUpdateRequest.Name,
+		ParentID: menuUpdateRequest.ParentID,
+		Sort:     menuUpdateRequest.Sort,
+	}
+	menu.ID = id
+	var permissionIds *[]uint
+	if menuUpdateRequest.Permissions != nil {
+		permissionIds = lo.ToPtr(lo.Map(*menuUpdateRequest.Permissions, func(item base_request.IdRequest, index int) uint {
+			return item.Id
+		}))
 	}
-	pageResponse.Data = userResponses
 
-	controller.Success(c, pageResponse)
+	updatedMenu, err := controller.menuService.Update(&menu, permissionIds)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, fmt.Errorf("更新失败：%w", err).Error())
+		return
+	}
 
+	menuResponse := response.NewMenuDetailResponse()
+	menuResponse.FromModel(*updatedMenu)
+	controller.Success(c, menuResponse)
 }
 
 func (controller *MenuController) Destroy(c *gin.Context) {
-	var pagination model.Pagination
-	var filter model2.MenuFilter
-	if err, ok := validator.BindQueryAndValidateAll(c, &pagination); !ok {
-		controller.Error(c, http.StatusBadRequest, err)
+	id, err := controller.GetParamUint(c, "id")
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, "无效的菜单ID")
 		return
 	}
-	_ = c.ShouldBindJSON(&filter)
-	total, users, err := controller.menuService.GetPageByFilter(filter, pagination)
+
+	err = controller.menuService.Delete(id)
 	if err != nil {
-		controller.Error(c, http.StatusBadRequest, err.Error())
+		controller.Error(c, http.StatusBadRequest, fmt.Errorf("删除失败：%w", err).Error())
+		return
 	}
 
-	var pageResponse base_response.PageResponse[response.UserResponse]
-	pageResponse.Total = total
-	pageResponse.Page = pagination.Page
-	pageResponse.PerPage = pagination.PerPage
-	var userResponses []response.UserResponse
-	for _, user := range users {
-		userResponses = append(userResponses, response.ToUserResponse(user))
+	controller.Success(c, nil)
+}
+
+// Sort 批量调整菜单上级与排序
+func (controller *MenuController) Sort(c *gin.Context) {
+	var menuSortRequest request.MenuSortRequest
+	if err := base_request.BindAndSetDefaults(c, &menuSortRequest); err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
 	}
-	pageResponse.Data = userResponses
 
-	controller.Success(c, pageResponse)
+	err := controller.menuService.Sort(menuSortRequest.Items)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
 
+	controller.Success(c, nil)
 }
 
-func (controller *MenuController) Show(c *gin.Context) {
-	var pagination model.Pagination
-	var filter model2.MenuFilter
-	if err, ok := validator.BindQueryAndValidateAll(c, &pagination); !ok {
-		controller.Error(c, http.StatusBadRequest, err)
+// UpdateStatus 停用或启用菜单，停用后各角色不再拥有该菜单及子菜单的权限
+func (controller *MenuController) UpdateStatus(c *gin.Context) {
+	var menuStatusRequest request.MenuStatusRequest
+	if err := base_request.BindAndSetDefaults(c, &menuStatusRequest); err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	_ = c.ShouldBindJSON(&filter)
-	total, users, err := controller.menuService.GetPageByFilter(filter, pagination)
+
+	id, err := controller.GetParamUint(c, "id")
 	if err != nil {
-		controller.Error(c, http.StatusBadRequest, err.Error())
+		controller.Error(c, http.StatusBadRequest, "无效的菜单ID")
+		return
 	}
 
-	var pageResponse base_response.PageResponse[response.UserResponse]
-	pageResponse.Total = total
-	pageResponse.Page = pagination.Page
-	pageResponse.PerPage = pagination.PerPage
-	var userResponses []response.UserResponse
-	for _, user := range users {
-		userResponses = append(userResponses, response.ToUserResponse(user))
+	err = controller.menuService.SetDisabled(id, *menuStatusRequest.IsDisabled)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
 	}
-	pageResponse.Data = userResponses
-
-	controller.Success(c, pageResponse)
 
+	controller.Success(c, nil)
 }
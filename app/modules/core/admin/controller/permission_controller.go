@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxlcoder/homework-backend/app/middleware"
+	"github.com/maxlcoder/homework-backend/app/modules/core/admin/request"
+	"github.com/maxlcoder/homework-backend/app/modules/core/service"
+	base_request "github.com/maxlcoder/homework-backend/app/request"
+)
+
+type PermissionController struct {
+	BaseController
+	// 集成服务
+	casbinService service.CasbinServiceInterface
+}
+
+func NewPermissionController(casbinService service.CasbinServiceInterface) *PermissionController {
+	return &PermissionController{
+		casbinService: casbinService,
+	}
+}
+
+// PermissionCheckResult 接口校验结果
+type PermissionCheckResult struct {
+	Path    string `json:"path"`
+	Method  string `json:"method"`
+	Allowed bool   `json:"allowed"`
+}
+
+// Check 按当前角色与租户批量校验接口权限，与 CasbinMiddleware 的判断一致
+func (controller *PermissionController) Check(c *gin.Context) {
+	// 路由 permissions:check 中的 :check 会被 gin 解析为参数，需精确匹配
+	if c.Param("check") != ":check" {
+		controller.Error(c, http.StatusNotFound, "接口不存在")
+		return
+	}
+
+	var checkRequest request.PermissionCheckRequest
+	if err := base_request.BindAndSetDefaults(c, &checkRequest); err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	roleId := c.GetUint("login_admin_role_id")
+	tenantId := c.GetUint("login_admin_tenant_id")
+	results := make([]PermissionCheckResult, 0, len(checkRequest.Items))
+	for _, item := range checkRequest.Items {
+		allowed := middleware.IsPersonalPath(item.Path)
+		if !allowed {
+			var err error
+			allowed, err = controller.casbinService.Enforce(roleId, tenantId, item.Path, item.Method)
+			if err != nil {
+				controller.Error(c, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+		results = append(results, PermissionCheckResult{
+			Path:    item.Path,
+			Method:  item.Method,
+			Allowed: allowed,
+		})
+	}
+
+	controller.Success(c, results)
+}
@@ -14,17 +14,21 @@ import (
 	base_request "github.com/maxlcoder/homework-backend/app/request"
 	base_response "github.com/maxlcoder/homework-backend/app/response"
 	"github.com/maxlcoder/homework-backend/model"
+	"github.com/maxlcoder/homework-backend/pkg/tenant"
+	"github.com/samber/lo"
 )
 
 type RoleController struct {
 	BaseController
 	// 集成服务
-	roleService service.RoleServiceInterface
+	roleService   service.RoleServiceInterface
+	casbinService service.CasbinServiceInterface
 }
 
-func NewRoleController(roleService service.RoleServiceInterface) *RoleController {
+func NewRoleController(roleService service.RoleServiceInterface, casbinService service.CasbinServiceInterface) *RoleController {
 	return &RoleController{
-		roleService: roleService,
+		roleService:   roleService,
+		casbinService: casbinService,
 	}
 }
 
@@ -37,12 +41,22 @@ func (controller *RoleController) Page(c *gin.Context) {
 		return
 	}
 
-	// 当前管理员是否为租户
+	// 角色按当前租户隔离，平台管理员可显式查询全部租户
+	ctx := c.Request.Context()
+	if c.Query("all_tenants") == "true" {
+		crossCtx, err := tenant.CrossTenant(ctx, c.GetUint("login_admin_id"), "角色列表跨租户查询")
+		if err != nil {
+			controller.Error(c, http.StatusForbidden, err.Error())
+			return
+		}
+		ctx = crossCtx
+	}
 
 	_ = c.ShouldBindQuery(&filter)
-	total, roles, err := controller.roleService.GetPageByFilter(filter, pagination)
+	total, roles, err := controller.roleService.WithContext(ctx).GetPageByFilter(filter, pagination)
 	if err != nil {
 		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
 	}
 
 	pageResponse := base_response.BuildPageResponse[model2.Role, response.RoleResponse](roles, total, pagination.Page, pagination.PerPage)
@@ -71,7 +85,7 @@ func (controller *RoleController) Store(c *gin.Context) {
 		return
 	}
 
-	_, err = controller.roleService.CreateWithMenus(&role, menus)
+	_, err = controller.roleService.WithContext(c.Request.Context()).CreateWithMenus(&role, menus)
 	if err != nil {
 		controller.Error(c, 400, fmt.Errorf("新增失败：%w", err).Error())
 		return
@@ -111,7 +125,7 @@ func (controller *RoleController) Update(c *gin.Context) {
 		return
 	}
 
-	_, err = controller.roleService.UpdateWithMenus(&role, menus)
+	_, err = controller.roleService.WithContext(c.Request.Context()).UpdateWithMenus(&role, menus)
 	if err != nil {
 		controller.Error(c, 400, fmt.Errorf("新增失败：%w", err).Error())
 		return
@@ -120,6 +134,29 @@ func (controller *RoleController) Update(c *gin.Context) {
 
 }
 
+// EffectivePermissions 角色菜单 -> 权限 -> casbin 规则的解析结果，用于排查授权问题
+func (controller *RoleController) EffectivePermissions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, "无效的角色ID")
+		return
+	}
+
+	role, err := controller.roleService.WithContext(c.Request.Context()).GetById(uint(id))
+	if err != nil {
+		controller.Error(c, http.StatusNotFound, "角色不存在")
+		return
+	}
+
+	effective, err := controller.casbinService.EffectivePermissions(role)
+	if err != nil {
+		controller.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	controller.Success(c, effective)
+}
+
 func (controller *RoleController) Destroy(c *gin.Context) {
 	// 角色 id
 	idStr := c.Param("id")
@@ -130,7 +167,7 @@ func (controller *RoleController) Destroy(c *gin.Context) {
 
 	var role model2.Role
 	role.ID = uint(id)
-	err = controller.roleService.Delete(&role)
+	err = controller.roleService.WithContext(c.Request.Context()).Delete(&role)
 	if err != nil {
 		controller.Error(c, http.StatusBadRequest, err.Error())
 	}
@@ -146,7 +183,7 @@ func (controller *RoleController) Show(c *gin.Context) {
 		controller.Error(c, http.StatusBadRequest, err.Error())
 	}
 
-	role, err := controller.roleService.GetById(uint(id))
+	role, err := controller.roleService.WithContext(c.Request.Context()).GetById(uint(id))
 	if err != nil {
 		controller.Error(c, http.StatusNotFound, err.Error())
 	}
@@ -157,3 +194,72 @@ func (controller *RoleController) Show(c *gin.Context) {
 	controller.Success(c, roleResponse)
 
 }
+
+// Tree 当前租户的角色继承树，平台管理员可显式查询全部租户
+func (controller *RoleController) Tree(c *gin.Context) {
+	ctx := c.Request.Context()
+	if c.Query("all_tenants") == "true" {
+		crossCtx, err := tenant.CrossTenant(ctx, c.GetUint("login_admin_id"), "角色继承树跨租户查询")
+		if err != nil {
+			controller.Error(c, http.StatusForbidden, err.Error())
+			return
+		}
+		ctx = crossCtx
+	}
+
+	roles, err := controller.roleService.WithContext(ctx).Tree()
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, response.RoleTreesToResponse(roles))
+}
+
+// AttachChildren 设置下级角色，下级角色继承当前角色的全部权限
+func (controller *RoleController) AttachChildren(c *gin.Context) {
+	var roleChildrenRequest request.RoleChildrenRequest
+	if err := base_request.BindAndSetDefaults(c, &roleChildrenRequest); err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, "无效的角色ID")
+		return
+	}
+
+	childIds := lo.Map(roleChildrenRequest.Roles, func(item base_request.IdRequest, index int) uint {
+		return item.Id
+	})
+	err = controller.roleService.WithContext(c.Request.Context()).AttachChildren(uint(id), childIds)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, nil)
+}
+
+// DetachChild 解除下级角色的继承关系
+func (controller *RoleController) DetachChild(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, "无效的角色ID")
+		return
+	}
+	childId, err := strconv.ParseUint(c.Param("child_id"), 10, 64)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, "无效的下级角色ID")
+		return
+	}
+
+	err = controller.roleService.WithContext(c.Request.Context()).DetachChild(uint(id), uint(childId))
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, nil)
+}
@@ -0,0 +1,37 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxlcoder/homework-backend/app/modules/core/service"
+)
+
+type SystemController struct {
+	BaseController
+	// 集成服务
+	casbinService service.CasbinServiceInterface
+}
+
+func NewSystemController(casbinService service.CasbinServiceInterface) *SystemController {
+	return &SystemController{
+		casbinService: casbinService,
+	}
+}
+
+// ReconcileCasbin 按授权数据重建 casbin 规则，dry_run=true 时只返回差异
+func (controller *SystemController) ReconcileCasbin(c *gin.Context) {
+	// 路由 casbin:reconcile 中的 :reconcile 会被 gin 解析为参数，需精确匹配
+	if c.Param("reconcile") != ":reconcile" {
+		controller.Error(c, http.StatusNotFound, "接口不存在")
+		return
+	}
+
+	diff, err := controller.casbinService.Reconcile(c.Query("dry_run") == "true")
+	if err != nil {
+		controller.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	controller.Success(c, diff)
+}
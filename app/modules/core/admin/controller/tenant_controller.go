@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
@@ -13,6 +14,8 @@ import (
 	"github.com/maxlcoder/homework-backend/app/modules/core/service"
 	base_request "github.com/maxlcoder/homework-backend/app/request"
 	base_response "github.com/maxlcoder/homework-backend/app/response"
+	base_model "github.com/maxlcoder/homework-backend/model"
+	"github.com/samber/lo"
 )
 
 type TenantController struct {
@@ -96,8 +99,24 @@ func (controller *TenantController) Store(c *gin.Context) {
 		return
 	}
 
+	// 租户初始管理员
+	if err := service.ValidatePassword(tenantStoreRequest.AdminPassword); err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	admin := model.Admin{
+		Name:  tenantStoreRequest.AdminName,
+		Email: tenantStoreRequest.AdminEmail,
+	}
+	admin.Password, err = base_model.HashPassword(tenantStoreRequest.AdminPassword)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, "密码处理失败")
+		return
+	}
+	admin.PasswordChangedAt = lo.ToPtr(time.Now())
+
 	// service 处理
-	createdTenant, err := controller.tenantService.Create(&tenant)
+	createdTenant, err := controller.tenantService.Create(&tenant, &admin)
 	if err != nil {
 		controller.Error(c, http.StatusBadRequest, fmt.Errorf("新增失败：%w", err).Error())
 		return
@@ -176,3 +195,94 @@ func (controller *TenantController) Destroy(c *gin.Context) {
 
 	controller.Success(c, nil)
 }
+
+// UpdateMfa 设置租户是否强制管理员启用两步验证
+func (controller *TenantController) UpdateMfa(c *gin.Context) {
+	var tenantMfaRequest request.TenantMfaRequest
+	if err := base_request.BindAndSetDefaults(c, &tenantMfaRequest); err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := controller.GetParamUint(c, "id")
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, "无效的租户ID")
+		return
+	}
+
+	if _, err := controller.tenantService.FindById(id); err != nil {
+		controller.Error(c, http.StatusNotFound, "租户不存在")
+		return
+	}
+
+	err = controller.tenantService.SetMfaRequired(id, *tenantMfaRequest.Required)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, nil)
+}
+
+// UpdateStatus 变更租户状态：停用、恢复、归档
+func (controller *TenantController) UpdateStatus(c *gin.Context) {
+	var tenantStatusRequest request.TenantStatusRequest
+	if err := base_request.BindAndSetDefaults(c, &tenantStatusRequest); err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := controller.GetParamUint(c, "id")
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, "无效的租户ID")
+		return
+	}
+
+	err = controller.tenantService.SetStatus(id, tenantStatusRequest.Status)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, nil)
+}
+
+// Modules 租户模块授权情况
+func (controller *TenantController) Modules(c *gin.Context) {
+	id, err := controller.GetParamUint(c, "id")
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, "无效的租户ID")
+		return
+	}
+
+	modules, err := controller.tenantService.GetModules(id)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, modules)
+}
+
+// UpdateModules 设置租户可用模块
+func (controller *TenantController) UpdateModules(c *gin.Context) {
+	var tenantModulesRequest request.TenantModulesRequest
+	if err := base_request.BindAndSetDefaults(c, &tenantModulesRequest); err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := controller.GetParamUint(c, "id")
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, "无效的租户ID")
+		return
+	}
+
+	err = controller.tenantService.SetModules(id, tenantModulesRequest.Modules)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, nil)
+}
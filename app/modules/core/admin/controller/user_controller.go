@@ -3,6 +3,7 @@ package controller
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
@@ -13,6 +14,7 @@ import (
 	base_response "github.com/maxlcoder/homework-backend/app/response"
 	"github.com/maxlcoder/homework-backend/model"
 	"github.com/maxlcoder/homework-backend/pkg/validator"
+	"github.com/samber/lo"
 )
 
 type UserController struct {
@@ -43,12 +45,18 @@ func (controller *UserController) Register(c *gin.Context) {
 		controller.Error(c, 400, "数据获取失败")
 		return
 	}
+	// 密码策略校验
+	if err := service.ValidatePassword(user.Password); err != nil {
+		controller.Error(c, 400, err.Error())
+		return
+	}
 	// 密码 hash 处理
 	user.Password, err = model.HashPassword(user.Password)
 	if err != nil {
 		controller.Error(c, 400, "密码处理失败")
 		return
 	}
+	user.PasswordChangedAt = lo.ToPtr(time.Now())
 	_, err = controller.userService.Create(&user)
 	if err != nil {
 		controller.Error(c, 400, fmt.Errorf("注册失败：%w", err).Error())
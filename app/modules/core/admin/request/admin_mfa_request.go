@@ -0,0 +1,6 @@
+package request
+
+// AdminMfaCodeRequest 两步验证操作需提交当前验证码
+type AdminMfaCodeRequest struct {
+	Code string `json:"code" binding:"required" label:"验证码"`
+}
@@ -5,7 +5,8 @@ import (
 )
 
 type AdminUpdateRequest struct {
-	Name     string                   `json:"name" binding:"required,min=1,max=30" label:"用户名"`
-	Password string                   `json:"password" binding:"" label:"密码"`
-	Roles    []base_request.IdRequest `json:"roles" binding:"required,dive" label:"角色"`
+	Name         string                   `json:"name" binding:"required,min=1,max=30" label:"用户名"`
+	Password     string                   `json:"password" binding:"" label:"密码"`
+	DepartmentId uint                     `json:"department_id" label:"部门"`
+	Roles        []base_request.IdRequest `json:"roles" binding:"required,dive" label:"角色"`
 }
@@ -0,0 +1,17 @@
+package request
+
+import "time"
+
+// AuditLogPageRequest 审计日志列表请求
+type AuditLogPageRequest struct {
+	Page     int        `form:"page" binding:"omitempty,min=1" label:"页码" default:"1"`
+	PerPage  int        `form:"per_page" binding:"omitempty,min=1,max=100" label:"每页数量" default:"20"`
+	TenantId *uint      `form:"tenant_id" binding:"omitempty" label:"租户"` // 跨租户查询时按租户筛选
+	AdminId  *uint      `form:"admin_id" binding:"omitempty" label:"操作管理员"`
+	Method   string     `form:"method" binding:"omitempty,oneof=POST PUT PATCH DELETE" label:"请求方法"`
+	Route    string     `form:"route" binding:"omitempty,max=120" label:"路由"`
+	Resource string     `form:"resource" binding:"omitempty,max=60" label:"资源"`
+	TargetId string     `form:"target_id" binding:"omitempty,max=60" label:"目标 ID"`
+	StartAt  *time.Time `form:"start_at" time_format:"2006-01-02 15:04:05" time_location:"Local" binding:"omitempty" label:"开始时间"`
+	EndAt    *time.Time `form:"end_at" time_format:"2006-01-02 15:04:05" time_location:"Local" binding:"omitempty" label:"结束时间"`
+}
@@ -0,0 +1,44 @@
+package request
+
+import (
+	base_request "github.com/maxlcoder/homework-backend/app/request"
+)
+
+type MenuStoreRequest struct {
+	Name        string                   `json:"name" binding:"required,min=1,max=60" label:"菜单名称"`
+	Number      string                   `json:"number" binding:"required,min=1,max=60" label:"菜单编号"`
+	ParentID    uint                     `json:"parent_id" binding:"omitempty" label:"上级菜单"`
+	Sort        int                      `json:"sort" binding:"omitempty" label:"排序"`
+	Permissions []base_request.IdRequest `json:"permissions" binding:"omitempty,dive" label:"权限"`
+}
+
+// MenuUpdateRequest 菜单更新，不提交 permissions 时不修改菜单权限
+type MenuUpdateRequest struct {
+	Name        string                    `json:"name" binding:"required,min=1,max=60" label:"菜单名称"`
+	ParentID    uint                      `json:"parent_id" binding:"omitempty" label:"上级菜单"`
+	Sort        int                       `json:"sort" binding:"omitempty" label:"排序"`
+	Permissions *[]base_request.IdRequest `json:"permissions" binding:"omitempty,dive" label:"权限"`
+}
+
+// MenuSortRequest 菜单批量排序
+type MenuSortRequest struct {
+	Items []MenuSortItem `json:"items" binding:"required,min=1,max=500,dive" label:"排序项"`
+}
+
+type MenuSortItem struct {
+	ID       uint `json:"id" binding:"required,gt=0" label:"菜单"`
+	ParentID uint `json:"parent_id" binding:"omitempty" label:"上级菜单"`
+	Sort     int  `json:"sort" binding:"omitempty" label:"排序"`
+}
+
+// MenuStatusRequest 菜单停用、启用
+type MenuStatusRequest struct {
+	IsDisabled *bool `json:"is_disabled" binding:"required" label:"是否停用"`
+}
+
+// MenuPageRequest 菜单列表请求
+type MenuPageRequest struct {
+	Page    int     `form:"page" binding:"omitempty,min=1" label:"页码" default:"1"`
+	PerPage int     `form:"per_page" binding:"omitempty,min=1,max=100" label:"每页数量" default:"100"`
+	Name    *string `form:"name" json:"name" binding:"omitempty" label:"菜单名称"`
+}
@@ -0,0 +1,18 @@
+package request
+
+// PasswordChangeRequest 修改密码
+type PasswordChangeRequest struct {
+	OldPassword string `json:"old_password" binding:"required" label:"原密码"`
+	NewPassword string `json:"new_password" binding:"required" label:"新密码"`
+}
+
+// PasswordForgotRequest 找回密码，account 为用户名或邮箱
+type PasswordForgotRequest struct {
+	Account string `json:"account" binding:"required" label:"账号"`
+}
+
+// PasswordResetRequest 使用重置令牌设置新密码
+type PasswordResetRequest struct {
+	Token    string `json:"token" binding:"required" label:"重置令牌"`
+	Password string `json:"password" binding:"required" label:"新密码"`
+}
@@ -0,0 +1,12 @@
+package request
+
+// PermissionCheckRequest 批量校验当前角色能否访问接口
+type PermissionCheckRequest struct {
+	Items []PermissionCheckItem `json:"items" binding:"required,min=1,max=100,dive" label:"接口"`
+}
+
+// PermissionCheckItem 路径为路由定义，如 /admin/admins/:id
+type PermissionCheckItem struct {
+	Path   string `json:"path" binding:"required,max=255" label:"路径"`
+	Method string `json:"method" binding:"required,oneof=GET POST PUT PATCH DELETE" label:"方法"`
+}
@@ -5,6 +5,8 @@ import (
 )
 
 type RoleStoreRequest struct {
-	Name  string                   `json:"name" binding:"required,min=1,max=30" label:"角色名"`
-	Menus []base_request.IdRequest `json:"menus" binding:"required,dive" label:"菜单"`
+	Name      string                   `json:"name" binding:"required,min=1,max=30" label:"角色名"`
+	DataScope string                   `json:"data_scope" binding:"omitempty,oneof=all tenant department self" label:"数据权限"`
+	ParentID  uint                     `json:"parent_id" binding:"omitempty" label:"上级角色"`
+	Menus     []base_request.IdRequest `json:"menus" binding:"required,dive" label:"菜单"`
 }
@@ -5,6 +5,12 @@ import (
 )
 
 type RoleUpdateRequest struct {
-	Name  string                   `json:"name" binding:"required,min=1,max=30" label:"角色名"`
-	Menus []base_request.IdRequest `json:"menus" binding:"required,dive" label:"菜单"`
+	Name      string                   `json:"name" binding:"required,min=1,max=30" label:"角色名"`
+	DataScope string                   `json:"data_scope" binding:"omitempty,oneof=all tenant department self" label:"数据权限"`
+	Menus     []base_request.IdRequest `json:"menus" binding:"required,dive" label:"菜单"`
+}
+
+// RoleChildrenRequest 设置下级角色
+type RoleChildrenRequest struct {
+	Roles []base_request.IdRequest `json:"roles" binding:"required,min=1,dive" label:"下级角色"`
 }
@@ -2,4 +2,8 @@ package request
 
 type TenantStoreRequest struct {
 	Name string `json:"name" binding:"required,min=1,max=60" label:"租户名称"`
+	// 租户初始管理员
+	AdminName     string `json:"admin_name" binding:"required,min=1,max=30" label:"管理员用户名"`
+	AdminEmail    string `json:"admin_email" binding:"required,email" label:"管理员邮箱"`
+	AdminPassword string `json:"admin_password" binding:"required" label:"管理员密码"`
 }
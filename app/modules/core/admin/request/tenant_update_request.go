@@ -4,6 +4,21 @@ type TenantUpdateRequest struct {
 	Name string `json:"name" binding:"omitempty,min=1,max=60" label:"租户名称"`
 }
 
+// TenantMfaRequest 租户两步验证设置
+type TenantMfaRequest struct {
+	Required *bool `json:"required" binding:"required" label:"是否强制两步验证"`
+}
+
+// TenantStatusRequest 租户状态变更
+type TenantStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active suspended archived" label:"租户状态"`
+}
+
+// TenantModulesRequest 租户可用模块，基础模块无需提交
+type TenantModulesRequest struct {
+	Modules []string `json:"modules" binding:"dive,required" label:"模块"`
+}
+
 // TenantPageRequest 租户列表请求（公共）
 type TenantPageRequest struct {
 	Page    int     `form:"page" binding:"required,min=1" label:"页码"`
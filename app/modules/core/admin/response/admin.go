@@ -9,10 +9,12 @@ import (
 
 type AdminResponse struct {
 	response.BaseResponse
-	Name  string         `json:"name"`
-	Email string         `json:"email"`
-	Age   uint8          `json:"age"`
-	Roles []RoleResponse `json:"roles"`
+	Name         string         `json:"name"`
+	Email        string         `json:"email" sensitive:"admin.email"`
+	Age          uint8          `json:"age"`
+	DepartmentId uint           `json:"department_id"`
+	Roles        []RoleResponse `json:"roles"`
+	MfaEnabled   bool           `json:"mfa_enabled"`
 }
 
 func NewAdminResponse() *AdminResponse {
@@ -26,11 +28,12 @@ func (r *AdminResponse) FromModel(m model.Admin) {
 
 type MeResponse struct {
 	response.BaseResponse
-	Name  string          `json:"name"`
-	Email string          `json:"email"`
-	Age   uint8           `json:"age"`
-	Roles []RoleResponse  `json:"roles"`
-	Menus []*MenuResponse `json:"menus"`
+	Name       string          `json:"name"`
+	Email      string          `json:"email"`
+	Age        uint8           `json:"age"`
+	Roles      []RoleResponse  `json:"roles"`
+	Menus      []*MenuResponse `json:"menus"`
+	MfaEnabled bool            `json:"mfa_enabled"`
 }
 
 // 转换单个 tree
@@ -49,6 +52,8 @@ func TreeToResponse(menu *model.Menu) *MenuResponse {
 		Sort:       menu.Sort,
 		IsDisabled: menu.IsDisabled,
 		Number:     menu.Number,
+		Source:     menu.Source,
+		Customized: menu.Customized,
 		Children:   convertChildren(menu.Children),
 	}
 }
@@ -75,5 +80,7 @@ type MenuResponse struct {
 	Sort       int             `json:"sort"`
 	IsDisabled bool            `json:"is_disabled"`
 	Number     string          `json:"number"`
+	Source     string          `json:"source"`
+	Customized bool            `json:"customized"`
 	Children   []*MenuResponse `json:"children"`
 }
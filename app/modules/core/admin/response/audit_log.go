@@ -0,0 +1,47 @@
+package response
+
+import (
+	"encoding/json"
+
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/app/response"
+)
+
+// AuditLogResponse 审计日志，changes 为变更字段的 {"before": ..., "after": ...}
+type AuditLogResponse struct {
+	response.BaseResponse
+	TenantID  uint            `json:"tenant_id"`
+	AdminId   uint            `json:"admin_id"`
+	RoleId    uint            `json:"role_id"`
+	Route     string          `json:"route"`
+	Path      string          `json:"path"`
+	Method    string          `json:"method"`
+	Resource  string          `json:"resource"`
+	TargetId  string          `json:"target_id"`
+	Changes   json.RawMessage `json:"changes"`
+	Status    int             `json:"status"`
+	Ip        string          `json:"ip"`
+	UserAgent string          `json:"user_agent"`
+}
+
+func ToAuditLogResponse(m model.AuditLog) AuditLogResponse {
+	r := AuditLogResponse{
+		TenantID:  m.TenantID,
+		AdminId:   m.AdminId,
+		RoleId:    m.RoleId,
+		Route:     m.Route,
+		Path:      m.Path,
+		Method:    m.Method,
+		Resource:  m.Resource,
+		TargetId:  m.TargetId,
+		Changes:   json.RawMessage("null"),
+		Status:    m.Status,
+		Ip:        m.Ip,
+		UserAgent: m.UserAgent,
+	}
+	r.FromBaseModel(m.BaseModel)
+	if m.Changes != "" {
+		r.Changes = json.RawMessage(m.Changes)
+	}
+	return r
+}
@@ -0,0 +1,45 @@
+package response
+
+import (
+	"github.com/jinzhu/copier"
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/app/response"
+)
+
+type PermissionResponse struct {
+	response.BaseResponse
+	Name   string `json:"name"`
+	Path   string `json:"path"`
+	Method string `json:"method"`
+}
+
+// MenuDetailResponse 菜单详情，含关联权限
+type MenuDetailResponse struct {
+	response.BaseResponse
+	Name        string               `json:"name"`
+	ParentID    uint                 `json:"parent_id"`
+	Sort        int                  `json:"sort"`
+	IsDisabled  bool                 `json:"is_disabled"`
+	Number      string               `json:"number"`
+	Source      string               `json:"source"`
+	Customized  bool                 `json:"customized"`
+	Permissions []PermissionResponse `json:"permissions"`
+}
+
+func NewMenuDetailResponse() *MenuDetailResponse {
+	return &MenuDetailResponse{}
+}
+
+func (r *MenuDetailResponse) FromModel(m model.Menu) {
+	copier.Copy(&r.BaseResponse, &m)
+	copier.Copy(r, &m)
+	r.Permissions = make([]PermissionResponse, 0, len(m.Permissions))
+	for _, permission := range m.Permissions {
+		var permissionResponse PermissionResponse
+		permissionResponse.FromBaseModel(permission.BaseModel)
+		permissionResponse.Name = permission.Name
+		permissionResponse.Path = permission.PATH
+		permissionResponse.Method = permission.Method
+		r.Permissions = append(r.Permissions, permissionResponse)
+	}
+}
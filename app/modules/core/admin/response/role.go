@@ -8,7 +8,9 @@ import (
 
 type RoleResponse struct {
 	response.BaseResponse
-	Name string `json:"name"`
+	Name      string `json:"name"`
+	DataScope string `json:"data_scope"`
+	ParentID  uint   `json:"parent_id"`
 }
 
 func NewRoleResponse() *RoleResponse {
@@ -19,3 +21,29 @@ func (r *RoleResponse) FromModel(m model.Role) {
 	copier.Copy(&r.BaseResponse, &m)
 	copier.Copy(r, &m)
 }
+
+// RoleTreeResponse 角色继承树
+type RoleTreeResponse struct {
+	response.BaseResponse
+	Name      string              `json:"name"`
+	DataScope string              `json:"data_scope"`
+	TenantID  uint                `json:"tenant_id"`
+	ParentID  uint                `json:"parent_id"`
+	Children  []*RoleTreeResponse `json:"children"`
+}
+
+func RoleTreesToResponse(roles []*model.Role) []*RoleTreeResponse {
+	responses := make([]*RoleTreeResponse, 0, len(roles))
+	for _, role := range roles {
+		r := &RoleTreeResponse{
+			Name:      role.Name,
+			DataScope: role.DataScope,
+			TenantID:  role.TenantID,
+			ParentID:  role.ParentID,
+			Children:  RoleTreesToResponse(role.Children),
+		}
+		r.FromBaseModel(role.BaseModel)
+		responses = append(responses, r)
+	}
+	return responses
+}
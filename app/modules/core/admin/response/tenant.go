@@ -8,7 +8,9 @@ import (
 // TenantResponse 租户响应结构（公共）
 type TenantResponse struct {
 	response.BaseResponse
-	Name string `json:"name"`
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	MfaRequired bool   `json:"mfa_required"`
 }
 
 // 转换函数 - 将 Model 转换为 Response
@@ -16,6 +18,8 @@ func ToTenantResponse(m model.Tenant) TenantResponse {
 	var r TenantResponse
 	r.FromBaseModel(m.BaseModel)
 	r.Name = m.Name
+	r.Status = m.Status
+	r.MfaRequired = m.MfaRequired
 	return r
 }
 
@@ -3,6 +3,7 @@ package controller
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jinzhu/copier"
@@ -14,18 +15,21 @@ import (
 	base_response "github.com/maxlcoder/homework-backend/app/response"
 	"github.com/maxlcoder/homework-backend/model"
 	"github.com/maxlcoder/homework-backend/pkg/validator"
+	"github.com/samber/lo"
 )
 
 type UserController struct {
 	controller.BaseController
 	// 集成服务
-	userService service.UserServiceInterface
+	userService     service.UserServiceInterface
+	passwordService service.PasswordServiceInterface
 }
 
 // 初始化 controller，并注入服务
-func NewUserController(userService service.UserServiceInterface) *UserController {
+func NewUserController(userService service.UserServiceInterface, passwordService service.PasswordServiceInterface) *UserController {
 	return &UserController{
-		userService: userService,
+		userService:     userService,
+		passwordService: passwordService,
 	}
 }
 
@@ -44,12 +48,18 @@ func (controller *UserController) Register(c *gin.Context) {
 		controller.Error(c, 400, "数据获取失败")
 		return
 	}
+	// 密码策略校验
+	if err := service.ValidatePassword(user.Password); err != nil {
+		controller.Error(c, 400, err.Error())
+		return
+	}
 	// 密码 hash 处理
 	user.Password, err = model.HashPassword(user.Password)
 	if err != nil {
 		controller.Error(c, 400, "密码处理失败")
 		return
 	}
+	user.PasswordChangedAt = lo.ToPtr(time.Now())
 	_, err = controller.userService.Create(&user)
 	if err != nil {
 		controller.Error(c, 400, fmt.Errorf("注册失败：%w", err).Error())
@@ -70,3 +80,52 @@ func (controller *UserController) Me(c *gin.Context) {
 	userResponse := response.ToUserResponse(user)
 	controller.Success(c, userResponse)
 }
+
+// ChangePassword 修改当前用户密码
+func (controller *UserController) ChangePassword(c *gin.Context) {
+	var changeRequest request.PasswordChangeRequest
+	if errs, ok := validator.BindAndValidateFirst(c, &changeRequest); !ok {
+		controller.Error(c, http.StatusBadRequest, errs)
+		return
+	}
+
+	err := controller.passwordService.WithContext(c.Request.Context()).Change("User", c.GetUint("user_id"), changeRequest.OldPassword, changeRequest.NewPassword)
+	if err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, nil)
+}
+
+// ForgotPassword 找回密码，重置令牌通过通知服务发送，无论账号是否存在均返回成功
+func (controller *UserController) ForgotPassword(c *gin.Context) {
+	var forgotRequest request.PasswordForgotRequest
+	if errs, ok := validator.BindAndValidateFirst(c, &forgotRequest); !ok {
+		controller.Error(c, http.StatusBadRequest, errs)
+		return
+	}
+
+	if err := controller.passwordService.WithContext(c.Request.Context()).Forgot("User", forgotRequest.Account); err != nil {
+		controller.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	controller.Success(c, nil)
+}
+
+// ResetPassword 使用重置令牌设置新密码
+func (controller *UserController) ResetPassword(c *gin.Context) {
+	var resetRequest request.PasswordResetRequest
+	if errs, ok := validator.BindAndValidateFirst(c, &resetRequest); !ok {
+		controller.Error(c, http.StatusBadRequest, errs)
+		return
+	}
+
+	if err := controller.passwordService.WithContext(c.Request.Context()).Reset("User", resetRequest.Token, resetRequest.Password); err != nil {
+		controller.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	controller.Success(c, nil)
+}
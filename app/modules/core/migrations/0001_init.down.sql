@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS `tenants`;
+DROP TABLE IF EXISTS `role_permissions`;
+DROP TABLE IF EXISTS `role_menus`;
+DROP TABLE IF EXISTS `roles`;
+DROP TABLE IF EXISTS `menu_permissions`;
+DROP TABLE IF EXISTS `menus`;
+DROP TABLE IF EXISTS `permissions`;
+DROP TABLE IF EXISTS `admin_roles`;
+DROP TABLE IF EXISTS `admins`;
@@ -0,0 +1,86 @@
+-- core 模块基线表结构，与引入版本化迁移前 AutoMigrate 创建的表一致，已存在的表执行时跳过
+
+CREATE TABLE IF NOT EXISTS `admins` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `name` varchar(30) NOT NULL DEFAULT '',
+  `email` varchar(60) NOT NULL DEFAULT '',
+  `age` tinyint unsigned NOT NULL DEFAULT 0,
+  `password` varchar(100) NOT NULL DEFAULT '',
+  `role_id` bigint unsigned COMMENT '当前角色 ID',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `admin_roles` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `admin_id` bigint unsigned NOT NULL DEFAULT 0,
+  `role_id` bigint unsigned NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uq_admin_role` (`admin_id`,`role_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `permissions` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `name` varchar(60) NOT NULL DEFAULT '',
+  `path` varchar(60) NOT NULL DEFAULT '',
+  `method` varchar(10) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `menus` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `name` varchar(60) NOT NULL DEFAULT '',
+  `parent_id` bigint unsigned NOT NULL DEFAULT 0,
+  `sort` bigint NOT NULL DEFAULT 0,
+  `is_disabled` boolean DEFAULT false,
+  `number` varchar(191) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_menus_number` (`number`)
+);
+
+CREATE TABLE IF NOT EXISTS `menu_permissions` (
+  `menu_id` bigint unsigned NOT NULL DEFAULT 0,
+  `permission_id` bigint unsigned NOT NULL DEFAULT 0,
+  UNIQUE INDEX `uq_menu_permission` (`menu_id`,`permission_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `roles` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '租户 ID',
+  `name` varchar(60) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `role_menus` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `role_id` bigint unsigned NOT NULL DEFAULT 0,
+  `menu_id` bigint unsigned NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `role_permissions` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `role_id` bigint unsigned NOT NULL DEFAULT 0,
+  `permission_id` bigint unsigned NOT NULL DEFAULT 0,
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `tenants` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `name` varchar(60) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`),
+  CONSTRAINT `uni_tenants_name` UNIQUE (`name`)
+);
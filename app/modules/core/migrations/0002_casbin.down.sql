@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `casbin_policy_versions`;
+DROP TABLE IF EXISTS `casbin_rule`;
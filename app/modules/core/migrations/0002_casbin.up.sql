@@ -0,0 +1,22 @@
+-- casbin 规则表（gorm-adapter）与多实例规则变更版本号表，已由 adapter / watcher 创建的数据库执行时跳过已存在的表
+
+CREATE TABLE IF NOT EXISTS `casbin_rule` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `ptype` varchar(100),
+  `v0` varchar(100),
+  `v1` varchar(100),
+  `v2` varchar(100),
+  `v3` varchar(100),
+  `v4` varchar(100),
+  `v5` varchar(100),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_casbin_rule` (`ptype`, `v0`, `v1`, `v2`, `v3`, `v4`, `v5`)
+);
+
+CREATE TABLE IF NOT EXISTS `casbin_policy_versions` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `version` bigint NOT NULL DEFAULT 0,
+  `instance` varchar(100) NOT NULL DEFAULT '' COMMENT '最近一次变更的实例',
+  `updated_at` datetime(3) NOT NULL,
+  PRIMARY KEY (`id`)
+);
@@ -0,0 +1,21 @@
+ALTER TABLE `tenants`
+  DROP COLUMN `status`,
+  DROP COLUMN `mfa_required`;
+
+ALTER TABLE `roles`
+  DROP INDEX `idx_roles_parent_id`,
+  DROP COLUMN `data_scope`,
+  DROP COLUMN `parent_id`;
+
+ALTER TABLE `menus`
+  DROP COLUMN `source`,
+  DROP COLUMN `customized`;
+
+ALTER TABLE `admins`
+  DROP INDEX `idx_admins_department_id`,
+  DROP COLUMN `password_changed_at`,
+  DROP COLUMN `must_change_password`,
+  DROP COLUMN `department_id`,
+  DROP COLUMN `mfa_enabled`,
+  DROP COLUMN `mfa_secret`,
+  DROP COLUMN `mfa_last_step`;
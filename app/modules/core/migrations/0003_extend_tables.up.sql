@@ -0,0 +1,23 @@
+-- 为基线表补充字段与索引，AutoMigrate 创建的数据库与全新数据库均通过此迁移升级
+
+ALTER TABLE `admins`
+  ADD COLUMN `password_changed_at` datetime(3) NULL DEFAULT null COMMENT '密码修改时间',
+  ADD COLUMN `must_change_password` boolean NOT NULL DEFAULT false COMMENT '下次登录需修改密码',
+  ADD COLUMN `department_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '所属部门 ID',
+  ADD COLUMN `mfa_enabled` boolean NOT NULL DEFAULT false COMMENT '是否启用两步验证',
+  ADD COLUMN `mfa_secret` varchar(64) NOT NULL DEFAULT '' COMMENT 'TOTP 密钥，绑定确认前为待确认状态',
+  ADD COLUMN `mfa_last_step` bigint NOT NULL DEFAULT 0 COMMENT '最近一次通过验证的时间步，防止验证码重放',
+  ADD INDEX `idx_admins_department_id` (`department_id`);
+
+ALTER TABLE `menus`
+  ADD COLUMN `source` varchar(10) NOT NULL DEFAULT 'code' COMMENT '来源 code 代码定义 custom 手动创建',
+  ADD COLUMN `customized` boolean NOT NULL DEFAULT false COMMENT '代码定义的菜单已手动调整，初始化时不再覆盖名称、上级与排序';
+
+ALTER TABLE `roles`
+  ADD COLUMN `data_scope` varchar(20) NOT NULL DEFAULT 'all' COMMENT '数据权限 all 全部 tenant 本租户 department 本部门 self 本人',
+  ADD COLUMN `parent_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '上级角色 ID，继承上级角色的全部权限',
+  ADD INDEX `idx_roles_parent_id` (`parent_id`);
+
+ALTER TABLE `tenants`
+  ADD COLUMN `status` varchar(20) NOT NULL DEFAULT 'active' COMMENT '状态 active 正常 suspended 停用 archived 归档',
+  ADD COLUMN `mfa_required` boolean NOT NULL DEFAULT false COMMENT '是否强制管理员启用两步验证';
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS `password_reset_tokens`;
+DROP TABLE IF EXISTS `password_histories`;
+DROP TABLE IF EXISTS `admin_recovery_codes`;
+DROP TABLE IF EXISTS `audit_logs`;
+DROP TABLE IF EXISTS `login_audits`;
+DROP TABLE IF EXISTS `account_locks`;
+DROP TABLE IF EXISTS `login_attempts`;
+DROP TABLE IF EXISTS `revoked_tokens`;
+DROP TABLE IF EXISTS `refresh_tokens`;
+DROP TABLE IF EXISTS `departments`;
+DROP TABLE IF EXISTS `tenant_menus`;
+DROP TABLE IF EXISTS `tenant_users`;
+DROP TABLE IF EXISTS `tenant_admins`;
+DROP TABLE IF EXISTS `menu_fields`;
+DROP TABLE IF EXISTS `users`;
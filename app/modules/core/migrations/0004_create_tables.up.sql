@@ -0,0 +1,183 @@
+-- 新增账号安全、租户、部门、审计等功能所需的表
+
+CREATE TABLE `users` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `name` varchar(30) NOT NULL DEFAULT '',
+  `email` varchar(60) NOT NULL DEFAULT '',
+  `age` tinyint unsigned NOT NULL DEFAULT 0,
+  `password` varchar(100) NOT NULL DEFAULT '',
+  `password_changed_at` datetime(3) NULL DEFAULT null COMMENT '密码修改时间',
+  `must_change_password` boolean NOT NULL DEFAULT false COMMENT '下次登录需修改密码',
+  PRIMARY KEY (`id`),
+  CONSTRAINT `uni_users_name` UNIQUE (`name`),
+  CONSTRAINT `uni_users_email` UNIQUE (`email`)
+);
+
+CREATE TABLE `menu_fields` (
+  `menu_id` bigint unsigned NOT NULL DEFAULT 0,
+  `field` varchar(60) NOT NULL DEFAULT '' COMMENT '字段权限编号',
+  UNIQUE INDEX `uq_menu_field` (`menu_id`,`field`)
+);
+
+CREATE TABLE `tenant_admins` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '租户 ID',
+  `admin_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '管理员 ID',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE `tenant_users` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '租户 ID',
+  `user_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '用户 ID',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE `tenant_menus` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '租户 ID',
+  `menu_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '菜单 ID',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_tenant_menu` (`tenant_id`,`menu_id`)
+);
+
+CREATE TABLE `departments` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '租户 ID',
+  `name` varchar(60) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE `refresh_tokens` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `user_type` varchar(20) NOT NULL DEFAULT '' COMMENT '用户类型',
+  `user_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '用户 ID',
+  `family_id` varchar(64) NOT NULL DEFAULT '' COMMENT '令牌家族 ID（登录会话）',
+  `token_hash` varchar(64) NOT NULL DEFAULT '' COMMENT '令牌哈希',
+  `expires_at` datetime(3) NOT NULL COMMENT '过期时间',
+  `used_at` datetime(3) NULL DEFAULT null COMMENT '轮换使用时间',
+  `revoked_at` datetime(3) NULL DEFAULT null COMMENT '吊销时间',
+  PRIMARY KEY (`id`),
+  INDEX `idx_user` (`user_type`,`user_id`),
+  INDEX `idx_refresh_tokens_family_id` (`family_id`),
+  UNIQUE INDEX `idx_refresh_tokens_token_hash` (`token_hash`)
+);
+
+CREATE TABLE `revoked_tokens` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `jti` varchar(64) NOT NULL DEFAULT '' COMMENT '访问令牌 ID',
+  `expires_at` datetime(3) NOT NULL COMMENT '令牌过期时间',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_revoked_tokens_jti` (`jti`),
+  INDEX `idx_revoked_tokens_expires_at` (`expires_at`)
+);
+
+CREATE TABLE `login_attempts` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `key` varchar(150) NOT NULL DEFAULT '' COMMENT '计数键',
+  `count` bigint NOT NULL DEFAULT 0 COMMENT '失败次数',
+  `last_failed_at` datetime(3) NOT NULL COMMENT '最后失败时间',
+  `expires_at` datetime(3) NOT NULL COMMENT '计数过期时间',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_login_attempts_key` (`key`),
+  INDEX `idx_login_attempts_expires_at` (`expires_at`)
+);
+
+CREATE TABLE `account_locks` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `user_type` varchar(20) NOT NULL DEFAULT '' COMMENT '用户类型',
+  `user_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '用户 ID',
+  `locked_until` datetime(3) NULL DEFAULT null COMMENT '锁定截止时间，为空表示需手动解锁',
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `uq_account_lock` (`user_type`,`user_id`)
+);
+
+CREATE TABLE `login_audits` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `user_type` varchar(20) NOT NULL DEFAULT '' COMMENT '用户类型',
+  `user_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '用户 ID',
+  `name` varchar(60) NOT NULL DEFAULT '' COMMENT '登录名',
+  `ip` varchar(60) NOT NULL DEFAULT '' COMMENT '来源 IP',
+  `event` varchar(20) NOT NULL DEFAULT '' COMMENT '事件',
+  `operator_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '操作管理员 ID',
+  PRIMARY KEY (`id`),
+  INDEX `idx_login_audit_user` (`user_type`,`user_id`)
+);
+
+CREATE TABLE `audit_logs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '租户 ID',
+  `admin_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '操作管理员 ID',
+  `role_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '操作时的角色 ID',
+  `route` varchar(120) NOT NULL DEFAULT '' COMMENT '路由定义',
+  `path` varchar(255) NOT NULL DEFAULT '' COMMENT '请求路径',
+  `method` varchar(10) NOT NULL DEFAULT '' COMMENT '请求方法',
+  `resource` varchar(60) NOT NULL DEFAULT '' COMMENT '资源表',
+  `target_id` varchar(60) NOT NULL DEFAULT '' COMMENT '目标 ID',
+  `changes` text COMMENT '变更字段前后值 JSON',
+  `status` bigint NOT NULL DEFAULT 0 COMMENT '响应状态码',
+  `ip` varchar(60) NOT NULL DEFAULT '' COMMENT '来源 IP',
+  `user_agent` varchar(255) NOT NULL DEFAULT '' COMMENT 'User-Agent',
+  PRIMARY KEY (`id`),
+  INDEX `idx_audit_logs_admin_id` (`admin_id`),
+  INDEX `idx_audit_logs_route` (`route`),
+  INDEX `idx_audit_log_target` (`resource`,`target_id`)
+);
+
+CREATE TABLE `admin_recovery_codes` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `admin_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '管理员 ID',
+  `code_hash` varchar(64) NOT NULL DEFAULT '' COMMENT '恢复码哈希',
+  `used_at` datetime(3) NULL DEFAULT null COMMENT '使用时间',
+  PRIMARY KEY (`id`),
+  INDEX `idx_admin_recovery_codes_admin_id` (`admin_id`)
+);
+
+CREATE TABLE `password_histories` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `user_type` varchar(20) NOT NULL DEFAULT '' COMMENT '用户类型',
+  `user_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '用户 ID',
+  `password_hash` varchar(100) NOT NULL DEFAULT '' COMMENT '密码哈希',
+  PRIMARY KEY (`id`),
+  INDEX `idx_password_history_user` (`user_type`,`user_id`)
+);
+
+CREATE TABLE `password_reset_tokens` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `user_type` varchar(20) NOT NULL DEFAULT '' COMMENT '用户类型',
+  `user_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '用户 ID',
+  `token_hash` varchar(64) NOT NULL DEFAULT '' COMMENT '令牌哈希',
+  `expires_at` datetime(3) NOT NULL COMMENT '过期时间',
+  `used_at` datetime(3) NULL DEFAULT null COMMENT '使用时间',
+  PRIMARY KEY (`id`),
+  INDEX `idx_password_reset_user` (`user_type`,`user_id`),
+  UNIQUE INDEX `idx_password_reset_tokens_token_hash` (`token_hash`)
+);
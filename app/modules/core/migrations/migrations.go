@@ -0,0 +1,8 @@
+package migrations
+
+import "embed"
+
+// FS core 模块数据库迁移脚本，文件名为 <版本号>_<名称>.up.sql / <版本号>_<名称>.down.sql
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+
+	base_model "github.com/maxlcoder/homework-backend/model"
+)
+
+// AdminRecoveryCode 两步验证恢复码，只存储哈希值，每个恢复码仅可使用一次
+type AdminRecoveryCode struct {
+	base_model.BaseModel
+	AdminId  uint       `gorm:"not null;default:0;index;comment:管理员 ID"`
+	CodeHash string     `gorm:"size:64;not null;default:'';comment:恢复码哈希"`
+	UsedAt   *time.Time `gorm:"default:null;comment:使用时间"`
+}
@@ -4,16 +4,24 @@ import (
 	"time"
 
 	base_model "github.com/maxlcoder/homework-backend/model"
+	"github.com/maxlcoder/homework-backend/pkg/datascope"
+	"gorm.io/gorm/clause"
 )
 
 type Admin struct {
 	base_model.BaseModel
-	Name     string  `gorm:"size:30;not null;default:''"`
-	Email    string  `gorm:"size:60;not null;default:''"`
-	Age      uint8   `gorm:"not null;default:0"`
-	Password string  `gorm:"size:100;not null;default:''"`
-	RoleId   uint    `gorm:"comment:当前角色 ID"`
-	Roles    []*Role `gorm:"many2many:admin_roles;"`
+	Name               string     `gorm:"size:30;not null;default:''"`
+	Email              string     `gorm:"size:60;not null;default:''" sensitive:"admin.email"`
+	Age                uint8      `gorm:"not null;default:0"`
+	Password           string     `gorm:"size:100;not null;default:''"`
+	PasswordChangedAt  *time.Time `gorm:"default:null;comment:密码修改时间"`
+	MustChangePassword bool       `gorm:"not null;default:false;comment:下次登录需修改密码"`
+	RoleId             uint       `gorm:"comment:当前角色 ID"`
+	DepartmentId       uint       `gorm:"not null;default:0;index;comment:所属部门 ID"`
+	Roles              []*Role    `gorm:"many2many:admin_roles;"`
+	MfaEnabled         bool       `gorm:"not null;default:false;comment:是否启用两步验证"`
+	MfaSecret          string     `gorm:"size:64;not null;default:'';comment:TOTP 密钥，绑定确认前为待确认状态"`
+	MfaLastStep        int64      `gorm:"not null;default:0;comment:最近一次通过验证的时间步，防止验证码重放"`
 }
 
 type AdminRole struct {
@@ -31,6 +39,14 @@ type AdminFilter struct {
 	UpdatedAt *time.Time
 }
 
+// DataScope 管理员列表的数据权限：本人、本部门，本租户按租户管理员关联过滤
+func (Admin) DataScope(operator datascope.Operator) (clause.Expression, error) {
+	if operator.Scope == datascope.ScopeTenant {
+		return datascope.TenantOwnerExpression(operator, "id"), nil
+	}
+	return datascope.OwnerExpression(operator, "id", "department_id")
+}
+
 func (a *Admin) GetId() uint {
 	return a.ID
 }
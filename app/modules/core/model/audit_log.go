@@ -0,0 +1,21 @@
+package model
+
+import (
+	base_model "github.com/maxlcoder/homework-backend/model"
+)
+
+// AuditLog 管理后台写操作审计日志，按操作时的租户隔离
+type AuditLog struct {
+	base_model.BaseTenantModel
+	AdminId   uint   `gorm:"not null;default:0;index;comment:操作管理员 ID"`
+	RoleId    uint   `gorm:"not null;default:0;comment:操作时的角色 ID"`
+	Route     string `gorm:"size:120;not null;default:'';index;comment:路由定义"`
+	Path      string `gorm:"size:255;not null;default:'';comment:请求路径"`
+	Method    string `gorm:"size:10;not null;default:'';comment:请求方法"`
+	Resource  string `gorm:"size:60;not null;default:'';index:idx_audit_log_target;comment:资源表"`
+	TargetId  string `gorm:"size:60;not null;default:'';index:idx_audit_log_target;comment:目标 ID"`
+	Changes   string `gorm:"type:text;comment:变更字段前后值 JSON"`
+	Status    int    `gorm:"not null;default:0;comment:响应状态码"`
+	Ip        string `gorm:"size:60;not null;default:'';comment:来源 IP"`
+	UserAgent string `gorm:"size:255;not null;default:'';comment:User-Agent"`
+}
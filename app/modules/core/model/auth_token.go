@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	base_model "github.com/maxlcoder/homework-backend/model"
+)
+
+// RefreshToken 刷新令牌，只存储哈希值；同一次登录轮换出的令牌属于同一个家族
+type RefreshToken struct {
+	base_model.BaseModel
+	UserType  string     `gorm:"size:20;not null;default:'';index:idx_user;comment:用户类型"`
+	UserId    uint       `gorm:"not null;default:0;index:idx_user;comment:用户 ID"`
+	FamilyId  string     `gorm:"size:64;not null;default:'';index;comment:令牌家族 ID（登录会话）"`
+	TokenHash string     `gorm:"size:64;not null;default:'';uniqueIndex;comment:令牌哈希"`
+	ExpiresAt time.Time  `gorm:"not null;comment:过期时间"`
+	UsedAt    *time.Time `gorm:"default:null;comment:轮换使用时间"`
+	RevokedAt *time.Time `gorm:"default:null;comment:吊销时间"`
+}
+
+// RevokedToken 已吊销的访问令牌，过期后即可清理
+type RevokedToken struct {
+	base_model.BaseModel
+	Jti       string    `gorm:"size:64;not null;default:'';uniqueIndex;comment:访问令牌 ID"`
+	ExpiresAt time.Time `gorm:"not null;index;comment:令牌过期时间"`
+}
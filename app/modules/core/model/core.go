@@ -1,23 +1,33 @@
 package model
 
-import "gorm.io/gorm"
-
+// Models core 模块全部数据表模型，表结构变更需同步新增 migrations 中的迁移文件
 func Models() []interface{} {
 	return []interface{}{
-		// 初始化 wms 数据库
 		&Admin{},
+		&User{},
 		&AdminRole{},
 		&Permission{},
 		&Menu{},
 		&MenuPermission{},
+		&MenuField{},
 		&Role{},
 		&RoleMenu{},
 		&RolePermission{},
 
 		&Tenant{},
-	}
-}
+		&TenantAdmin{},
+		&TenantUser{},
+		&TenantMenu{},
+		&Department{},
 
-func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(Models()...)
+		&RefreshToken{},
+		&RevokedToken{},
+		&LoginAttempt{},
+		&AccountLock{},
+		&LoginAudit{},
+		&AuditLog{},
+		&AdminRecoveryCode{},
+		&PasswordHistory{},
+		&PasswordResetToken{},
+	}
 }
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	base_model "github.com/maxlcoder/homework-backend/model"
+)
+
+// 登录审计事件
+const (
+	LoginEventFailed    = "failed"    // 密码错误或账号不存在
+	LoginEventThrottled = "throttled" // 触发退避限制
+	LoginEventLocked    = "locked"    // 账号被锁定
+	LoginEventUnlocked  = "unlocked"  // 管理员解锁
+)
+
+// LoginAttempt 登录失败计数（database 存储时使用，多实例共享）
+type LoginAttempt struct {
+	base_model.BaseModel
+	Key          string    `gorm:"size:150;not null;default:'';uniqueIndex;comment:计数键"`
+	Count        int       `gorm:"not null;default:0;comment:失败次数"`
+	LastFailedAt time.Time `gorm:"not null;comment:最后失败时间"`
+	ExpiresAt    time.Time `gorm:"not null;index;comment:计数过期时间"`
+}
+
+// AccountLock 账号锁定
+type AccountLock struct {
+	base_model.BaseModel
+	UserType    string     `gorm:"size:20;not null;default:'';uniqueIndex:uq_account_lock;comment:用户类型"`
+	UserId      uint       `gorm:"not null;default:0;uniqueIndex:uq_account_lock;comment:用户 ID"`
+	LockedUntil *time.Time `gorm:"default:null;comment:锁定截止时间，为空表示需手动解锁"`
+}
+
+// LoginAudit 登录安全审计记录
+type LoginAudit struct {
+	base_model.BaseModel
+	UserType   string `gorm:"size:20;not null;default:'';index:idx_login_audit_user;comment:用户类型"`
+	UserId     uint   `gorm:"not null;default:0;index:idx_login_audit_user;comment:用户 ID"`
+	Name       string `gorm:"size:60;not null;default:'';comment:登录名"`
+	Ip         string `gorm:"size:60;not null;default:'';comment:来源 IP"`
+	Event      string `gorm:"size:20;not null;default:'';comment:事件"`
+	OperatorId uint   `gorm:"not null;default:0;comment:操作管理员 ID"`
+}
@@ -6,6 +6,12 @@ import (
 	base_model "github.com/maxlcoder/homework-backend/model"
 )
 
+// 菜单来源：代码中 MenuProvider 定义的菜单初始化时同步，手动创建的菜单只能通过接口维护
+const (
+	MenuSourceCode   = "code"
+	MenuSourceCustom = "custom"
+)
+
 type Menu struct {
 	base_model.BaseModel
 	Name        string        `gorm:"size:60;not null;default:''"`
@@ -13,8 +19,11 @@ type Menu struct {
 	Sort        int           `gorm:"not null;default:0"`
 	IsDisabled  bool          `gorm:"default:0"`
 	Number      string        `gorm:"not null;default:'';uniqueIndex"`
+	Source      string        `gorm:"size:10;not null;default:'code';comment:来源 code 代码定义 custom 手动创建"`
+	Customized  bool          `gorm:"not null;default:false;comment:代码定义的菜单已手动调整，初始化时不再覆盖名称、上级与排序"`
 	Children    []*Menu       `gorm:"-"`
 	Permissions []*Permission `gorm:"-"`
+	Fields      []string      `gorm:"-"` // 字段权限编号，对应响应结构体的 sensitive 标签
 }
 
 type MenuFilter struct {
@@ -28,3 +37,9 @@ type MenuPermission struct {
 	MenuID       uint `gorm:"not null;default:0;uniqueIndex:uq_menu_permission"`
 	PermissionID uint `gorm:"not null;default:0;uniqueIndex:uq_menu_permission"`
 }
+
+// MenuField 菜单关联的字段权限
+type MenuField struct {
+	MenuID uint   `gorm:"not null;default:0;uniqueIndex:uq_menu_field"`
+	Field  string `gorm:"size:60;not null;default:'';uniqueIndex:uq_menu_field;comment:字段权限编号"`
+}
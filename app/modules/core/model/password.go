@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	base_model "github.com/maxlcoder/homework-backend/model"
+)
+
+// PasswordHistory 历史密码哈希，用于禁止重复使用最近的密码
+type PasswordHistory struct {
+	base_model.BaseModel
+	UserType     string `gorm:"size:20;not null;default:'';index:idx_password_history_user;comment:用户类型"`
+	UserId       uint   `gorm:"not null;default:0;index:idx_password_history_user;comment:用户 ID"`
+	PasswordHash string `gorm:"size:100;not null;default:'';comment:密码哈希"`
+}
+
+// PasswordResetToken 找回密码的重置令牌，只存储哈希值
+type PasswordResetToken struct {
+	base_model.BaseModel
+	UserType  string     `gorm:"size:20;not null;default:'';index:idx_password_reset_user;comment:用户类型"`
+	UserId    uint       `gorm:"not null;default:0;index:idx_password_reset_user;comment:用户 ID"`
+	TokenHash string     `gorm:"size:64;not null;default:'';uniqueIndex;comment:令牌哈希"`
+	ExpiresAt time.Time  `gorm:"not null;comment:过期时间"`
+	UsedAt    *time.Time `gorm:"default:null;comment:使用时间"`
+}
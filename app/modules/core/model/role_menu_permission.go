@@ -7,9 +7,11 @@ import (
 )
 
 type Role struct {
-	base_model.BaseModel
-	Name     string `gorm:"size:60;not null;default:''"`
-	TenantId uint   `gorm:"not null;default:0;comment:租户 ID"`
+	base_model.BaseTenantModel
+	Name      string  `gorm:"size:60;not null;default:''"`
+	DataScope string  `gorm:"size:20;not null;default:'all';comment:数据权限 all 全部 tenant 本租户 department 本部门 self 本人"`
+	ParentID  uint    `gorm:"not null;default:0;index;comment:上级角色 ID，继承上级角色的全部权限"`
+	Children  []*Role `gorm:"-"`
 }
 
 type RoleFilter struct {
@@ -6,9 +6,18 @@ import (
 	model2 "github.com/maxlcoder/homework-backend/model"
 )
 
+// 租户状态：停用的租户暂停登录与访问，归档后只能删除
+const (
+	TenantStatusActive    = "active"
+	TenantStatusSuspended = "suspended"
+	TenantStatusArchived  = "archived"
+)
+
 type Tenant struct {
 	model2.BaseModel
-	Name string `gorm:"size:60;not null;default:'';unique"`
+	Name        string `gorm:"size:60;not null;default:'';unique"`
+	Status      string `gorm:"size:20;not null;default:'active';comment:状态 active 正常 suspended 停用 archived 归档"`
+	MfaRequired bool   `gorm:"not null;default:false;comment:是否强制管理员启用两步验证"`
 }
 
 type TenantFilter struct {
@@ -29,3 +38,16 @@ type TenantAdmin struct {
 	TenantId uint `gorm:"not null;default:0;comment:租户 ID"`
 	AdminId  uint `gorm:"not null;default:0;comment:管理员 ID"`
 }
+
+// TenantMenu 租户可用菜单（模块授权），基础模块菜单默认可用不在此记录
+type TenantMenu struct {
+	model2.BaseModel
+	TenantId uint `gorm:"not null;default:0;uniqueIndex:idx_tenant_menu;comment:租户 ID"`
+	MenuId   uint `gorm:"not null;default:0;uniqueIndex:idx_tenant_menu;comment:菜单 ID"`
+}
+
+// Department 部门，管理员归属部门后可按本部门范围授予数据权限
+type Department struct {
+	model2.BaseTenantModel
+	Name string `gorm:"size:60;not null;default:''"`
+}
@@ -8,10 +8,12 @@ import (
 
 type User struct {
 	model2.BaseModel
-	Name     string `gorm:"size:30;unique;not null;default:''"`
-	Email    string `gorm:"size:60;unique;not null;default:''"`
-	Age      uint8  `gorm:"not null;default:0"`
-	Password string `gorm:"size:100;not null;default:''"`
+	Name               string     `gorm:"size:30;unique;not null;default:''"`
+	Email              string     `gorm:"size:60;unique;not null;default:''"`
+	Age                uint8      `gorm:"not null;default:0"`
+	Password           string     `gorm:"size:100;not null;default:''"`
+	PasswordChangedAt  *time.Time `gorm:"default:null;comment:密码修改时间"`
+	MustChangePassword bool       `gorm:"not null;default:false;comment:下次登录需修改密码"`
 }
 
 type UserFilter struct {
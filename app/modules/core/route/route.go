@@ -1,42 +1,50 @@
 package route
 
 import (
-	"fmt"
+	"context"
+	"log/slog"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
 	"github.com/maxlcoder/homework-backend/app/contract"
+	"github.com/maxlcoder/homework-backend/app/middleware"
 	admin_controller "github.com/maxlcoder/homework-backend/app/modules/core/admin/controller"
-	admin_middleware "github.com/maxlcoder/homework-backend/app/modules/core/admin/middleware"
 	api_controller "github.com/maxlcoder/homework-backend/app/modules/core/api/controller"
-	api_middleware "github.com/maxlcoder/homework-backend/app/modules/core/api/middleware"
-	"github.com/maxlcoder/homework-backend/app/modules/core/model"
 	core_model "github.com/maxlcoder/homework-backend/app/modules/core/model"
 	"github.com/maxlcoder/homework-backend/app/modules/core/service"
+	"github.com/maxlcoder/homework-backend/app/route/auth"
+	"github.com/maxlcoder/homework-backend/config"
 	"gorm.io/gorm"
 )
 
 type AdminController struct {
-	UserController   *admin_controller.AdminUserController
-	AdminController  *admin_controller.AdminController
-	RoleController   *admin_controller.RoleController
-	TenantController *admin_controller.TenantController
-	Handler          *jwt.GinJWTMiddleware
+	UserController       *admin_controller.AdminUserController
+	AdminController      *admin_controller.AdminController
+	RoleController       *admin_controller.RoleController
+	TenantController     *admin_controller.TenantController
+	MenuController       *admin_controller.MenuController
+	SystemController     *admin_controller.SystemController
+	PermissionController *admin_controller.PermissionController
+	AuditLogController   *admin_controller.AuditLogController
+	Handler              *jwt.GinJWTMiddleware
 }
 
 type ApiController struct {
 	UserController *api_controller.UserController
+	Handler        *jwt.GinJWTMiddleware
 }
 
 type CoreModule struct {
 	DB              *gorm.DB
-	Enforcer        *casbin.Enforcer
+	Enforcer        *casbin.SyncedEnforcer
 	AdminController *AdminController
 	ApiController   *ApiController
 	initialized     bool
 	ApiHandler      *jwt.GinJWTMiddleware
 	AdminHandler    *jwt.GinJWTMiddleware
+	auditLogService service.AuditLogServiceInterface
+	stopRetention   context.CancelFunc
 }
 
 // Name 返回模块名称，实现RouteModule接口
@@ -44,6 +52,39 @@ func (m *CoreModule) Name() string {
 	return "CoreModule"
 }
 
+// HealthChecks 返回核心模块的就绪检查项，实现HealthChecker接口
+func (m *CoreModule) HealthChecks() []contract.HealthCheck {
+	casbinService := service.NewCasbinService(m.DB, m.Enforcer)
+	return []contract.HealthCheck{
+		{
+			Name: "casbin",
+			Check: func(ctx context.Context) error {
+				return casbinService.CheckLoaded()
+			},
+		},
+	}
+}
+
+// OnStart 启动审计日志定期清理，实现Lifecycle接口
+func (m *CoreModule) OnStart(ctx context.Context) error {
+	conf := config.GetConfig()
+	if conf == nil || m.auditLogService == nil {
+		return nil
+	}
+	retentionCtx, cancel := context.WithCancel(context.Background())
+	m.stopRetention = cancel
+	m.auditLogService.StartRetention(retentionCtx, conf.Audit.Retention, conf.Audit.PurgeInterval)
+	return nil
+}
+
+// OnStop 停止审计日志定期清理，实现Lifecycle接口
+func (m *CoreModule) OnStop(ctx context.Context) error {
+	if m.stopRetention != nil {
+		m.stopRetention()
+	}
+	return nil
+}
+
 // GetMenus 返回核心模块的菜单定义，实现MenuProvider接口
 func (m *CoreModule) GetMenus() []core_model.Menu {
 	return []core_model.Menu{
@@ -111,6 +152,22 @@ func (m *CoreModule) GetMenus() []core_model.Menu {
 								},
 							},
 						},
+						{
+							Number: "admin-unlock",
+							Name:   "解锁",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "解锁",
+									PATH:   "/admin/admins/:id/unlock",
+									Method: "POST",
+								},
+							},
+						},
+						{
+							Number: "admin-email",
+							Name:   "查看邮箱",
+							Fields: []string{"admin.email"},
+						},
 					},
 				},
 				{
@@ -172,6 +229,44 @@ func (m *CoreModule) GetMenus() []core_model.Menu {
 								},
 							},
 						},
+						{
+							Number: "role-tree",
+							Name:   "角色继承树",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "角色继承树",
+									PATH:   "/admin/roles/tree",
+									Method: "GET",
+								},
+							},
+						},
+						{
+							Number: "role-children",
+							Name:   "下级角色",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "设置下级角色",
+									PATH:   "/admin/roles/:id/children",
+									Method: "POST",
+								},
+								{
+									Name:   "解除下级角色",
+									PATH:   "/admin/roles/:id/children/:child_id",
+									Method: "DELETE",
+								},
+							},
+						},
+						{
+							Number: "role-effective-permissions",
+							Name:   "生效权限",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "生效权限",
+									PATH:   "/admin/roles/:id/effective-permissions",
+									Method: "GET",
+								},
+							},
+						},
 					},
 				},
 				{
@@ -233,6 +328,172 @@ func (m *CoreModule) GetMenus() []core_model.Menu {
 								},
 							},
 						},
+						{
+							Number: "tenant-mfa",
+							Name:   "两步验证设置",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "两步验证设置",
+									PATH:   "/admin/tenants/:id/mfa",
+									Method: "PUT",
+								},
+							},
+						},
+						{
+							Number: "tenant-status",
+							Name:   "租户状态",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "租户状态",
+									PATH:   "/admin/tenants/:id/status",
+									Method: "PUT",
+								},
+							},
+						},
+						{
+							Number: "tenant-modules",
+							Name:   "模块授权",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "模块授权情况",
+									PATH:   "/admin/tenants/:id/modules",
+									Method: "GET",
+								},
+								{
+									Name:   "模块授权",
+									PATH:   "/admin/tenants/:id/modules",
+									Method: "PUT",
+								},
+							},
+						},
+					},
+				},
+				{
+					Number: "menu-management",
+					Name:   "菜单管理",
+					Children: []*core_model.Menu{
+						{
+							Number: "menu-list",
+							Name:   "列表",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "列表",
+									PATH:   "/admin/menus",
+									Method: "GET",
+								},
+							},
+						},
+						{
+							Number: "menu-tree",
+							Name:   "菜单树",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "菜单树",
+									PATH:   "/admin/menus/tree",
+									Method: "GET",
+								},
+							},
+						},
+						{
+							Number: "menu-add",
+							Name:   "新增",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "新增",
+									PATH:   "/admin/menus",
+									Method: "POST",
+								},
+							},
+						},
+						{
+							Number: "menu-update",
+							Name:   "更新",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "更新",
+									PATH:   "/admin/menus/:id",
+									Method: "PUT",
+								},
+							},
+						},
+						{
+							Number: "menu-detail",
+							Name:   "详情",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "详情",
+									PATH:   "/admin/menus/:id",
+									Method: "GET",
+								},
+							},
+						},
+						{
+							Number: "menu-delete",
+							Name:   "删除",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "删除",
+									PATH:   "/admin/menus/:id",
+									Method: "DELETE",
+								},
+							},
+						},
+						{
+							Number: "menu-sort",
+							Name:   "排序",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "排序",
+									PATH:   "/admin/menus/sort",
+									Method: "PUT",
+								},
+							},
+						},
+						{
+							Number: "menu-status",
+							Name:   "停用启用",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "停用启用",
+									PATH:   "/admin/menus/:id/status",
+									Method: "PUT",
+								},
+							},
+						},
+					},
+				},
+				{
+					Number: "audit-log",
+					Name:   "审计日志",
+					Children: []*core_model.Menu{
+						{
+							Number: "audit-log-list",
+							Name:   "审计日志列表",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "审计日志列表",
+									PATH:   "/admin/audit-logs",
+									Method: "GET",
+								},
+							},
+						},
+					},
+				},
+				{
+					Number: "system-maintenance",
+					Name:   "系统维护",
+					Children: []*core_model.Menu{
+						{
+							Number: "casbin-reconcile",
+							Name:   "权限规则重建",
+							Permissions: []*core_model.Permission{
+								{
+									Name:   "权限规则重建",
+									PATH:   "/admin/system/casbin:reconcile",
+									Method: "POST",
+								},
+							},
+						},
 					},
 				},
 			},
@@ -244,27 +505,38 @@ func (m *CoreModule) GetMenus() []core_model.Menu {
 func (m *CoreModule) Init() contract.Module {
 	if !m.initialized {
 
-		// 初始化表
-		model.AutoMigrate(m.DB)
+		// 数据表由 migrate 命令按版本迁移，启动时只检查是否为最新
 
 		// 初始化仓库
 
 		userService := service.NewUserService(m.DB) // 初始化控制器
-		menuService := service.NewMenuService(m.DB)
+		menuService := service.NewMenuService(m.DB, m.Enforcer)
 		// 初始化服务
 		adminService := service.NewAdminService(m.DB)
 		roleService := service.NewRoleService(m.DB, m.Enforcer, menuService)
-		tenantService := service.NewTenantService(m.DB)
+		tenantService := service.NewTenantService(m.DB, m.Enforcer)
+		loginGuardService := service.NewLoginGuardService(m.DB)
+		adminMfaService := service.NewAdminMfaService(m.DB)
+		passwordService := service.NewPasswordService(m.DB)
+		casbinService := service.NewCasbinService(m.DB, m.Enforcer)
+		// 审计日志按保留期限定期清理，随服务启动（见 OnStart）
+		auditLogService := service.NewAuditLogService(m.DB)
+		m.auditLogService = auditLogService
 
 		m.ApiController = &ApiController{
-			UserController: api_controller.NewUserController(userService),
+			UserController: api_controller.NewUserController(userService, passwordService),
+			Handler:        m.ApiHandler,
 		}
 		m.AdminController = &AdminController{
-			UserController:   admin_controller.NewAdminUserController(adminService, userService),
-			AdminController:  admin_controller.NewAdminController(adminService),
-			RoleController:   admin_controller.NewRoleController(roleService),
-			TenantController: admin_controller.NewTenantController(tenantService),
-			Handler:          m.AdminHandler,
+			UserController:       admin_controller.NewAdminUserController(adminService, userService, loginGuardService),
+			AdminController:      admin_controller.NewAdminController(adminService, loginGuardService, adminMfaService, passwordService),
+			RoleController:       admin_controller.NewRoleController(roleService, casbinService),
+			TenantController:     admin_controller.NewTenantController(tenantService),
+			MenuController:       admin_controller.NewMenuController(menuService),
+			SystemController:     admin_controller.NewSystemController(casbinService),
+			PermissionController: admin_controller.NewPermissionController(casbinService),
+			AuditLogController:   admin_controller.NewAuditLogController(auditLogService),
+			Handler:              m.AdminHandler,
 		}
 		m.initialized = true
 	}
@@ -273,22 +545,18 @@ func (m *CoreModule) Init() contract.Module {
 
 // RegisterRoutes 注册模块路由，实现RouteModule接口
 func (m *CoreModule) RegisterRoutes(apiGroup *gin.RouterGroup, apiAuthGroup *gin.RouterGroup, adminGroup *gin.RouterGroup, adminAuthGroup *gin.RouterGroup, module interface{}) {
-	fmt.Println("Registering Core Module routes")
+	slog.Info("registering module routes", "module", "core")
 
 	// 确保模块已初始化
 	m.Init()
 
-	// 添加 API模块级中间件
 	apiGroup = apiGroup.Group("")
-	apiGroup.Use(api_middleware.Logger())
 	if m.ApiController != nil {
 		m.ApiController.RegisterRoutes(apiGroup, apiAuthGroup)
 	}
 
 	// 注册Admin路由 - 后台接口
 	adminGroup = adminGroup.Group("")
-	// 应用Admin子模块的中间件
-	adminGroup.Use(admin_middleware.Logger())
 	if m.AdminController != nil {
 		m.AdminController.RegisterRoutes(adminGroup, adminAuthGroup)
 	}
@@ -296,8 +564,16 @@ func (m *CoreModule) RegisterRoutes(apiGroup *gin.RouterGroup, apiAuthGroup *gin
 
 // RegisterRoutes 注册API认证路由
 func (ctrl *ApiController) RegisterRoutes(group *gin.RouterGroup, authGroup *gin.RouterGroup) {
+	// 登录与令牌刷新
+	group.POST("login", auth.LoginHandler(ctrl.Handler))
+	group.POST("refresh-token", auth.RefreshHandler[core_model.User](ctrl.Handler))
+	group.POST("password/forgot", ctrl.UserController.ForgotPassword) // 找回密码
+	group.POST("password/reset", ctrl.UserController.ResetPassword)   // 重置密码
+
 	// 注册认证后才能访问的路由
 	group.GET("me", ctrl.UserController.Me) // 个人信息
+	authGroup.POST("logout", auth.LogoutHandler())
+	authGroup.PUT("me/password", ctrl.UserController.ChangePassword) // 修改密码
 }
 
 // RegisterRoutes 注册管理员认证路由
@@ -305,34 +581,82 @@ func (ctrl *AdminController) RegisterRoutes(group *gin.RouterGroup, authGroup *g
 
 	// 注册管理员相关路由
 	group.POST("admins:register", ctrl.AdminController.Register) // 注册
-	group.POST("login", ctrl.Handler.LoginHandler)
+	group.POST("login", auth.LoginHandler(ctrl.Handler))
+	group.POST("login/mfa", auth.MfaLoginHandler(ctrl.Handler))           // 两步验证
+	group.POST("login/mfa/enroll", auth.MfaEnrollHandler(ctrl.Handler))   // 登录时绑定两步验证
+	group.POST("login/mfa/confirm", auth.MfaConfirmHandler(ctrl.Handler)) // 确认绑定并登录
+	group.POST("refresh-token", auth.RefreshHandler[core_model.Admin](ctrl.Handler))
+	group.POST("password/forgot", ctrl.AdminController.ForgotPassword) // 找回密码
+	group.POST("password/reset", ctrl.AdminController.ResetPassword)   // 重置密码
+	group.GET(".well-known/jwks.json", auth.JWKSHandler(ctrl.Handler)) // 签名公钥
 
 	// ---------- 业务功能 ----------
 
-	authGroup.GET("users", ctrl.UserController.Page) // 用户列表
+	authGroup.GET("users", ctrl.UserController.Page)               // 用户列表
+	authGroup.POST("users/:id/unlock", ctrl.UserController.Unlock) // 解锁
 
 	// ---------- 平台功能 ----------
 	// ------------ 个人中心 ------------
 	authGroup.GET("me", ctrl.AdminController.Me)
+	authGroup.POST("logout", auth.LogoutHandler())
+	authGroup.PUT("me/password", ctrl.AdminController.ChangePassword)                     // 修改密码
+	authGroup.POST("me/mfa", ctrl.AdminController.EnrollMfa)                              // 绑定两步验证
+	authGroup.POST("me/mfa/confirm", ctrl.AdminController.ConfirmMfa)                     // 确认绑定
+	authGroup.DELETE("me/mfa", ctrl.AdminController.DisableMfa)                           // 关闭两步验证
+	authGroup.POST("me/mfa/recovery-codes", ctrl.AdminController.RegenerateRecoveryCodes) // 重新生成恢复码
+	authGroup.GET("me/roles", ctrl.AdminController.Roles)                                 // 我的角色
+	authGroup.POST("me/roles/:id", auth.SwitchRoleHandler(ctrl.Handler))                  // 切换角色：POST /admin/me/roles/:id:switch
+
+	authGroup.POST("permissions:check", ctrl.PermissionController.Check) // 接口权限校验
 
 	// ------------ 管理员管理 ------------
-	authGroup.GET("admins", ctrl.AdminController.Page)           // 分页列表
-	authGroup.GET("admins/:id", ctrl.AdminController.Show)       // 详情
-	authGroup.POST("admins", ctrl.AdminController.Store)         // 新增
-	authGroup.PUT("admins/:id", ctrl.AdminController.Update)     // 更新
-	authGroup.DELETE("admins/:id", ctrl.AdminController.Destroy) // 删除
+	authGroup.GET("admins", ctrl.AdminController.Page)               // 分页列表
+	authGroup.GET("admins/:id", ctrl.AdminController.Show)           // 详情
+	authGroup.POST("admins", ctrl.AdminController.Store)             // 新增
+	authGroup.PUT("admins/:id", ctrl.AdminController.Update)         // 更新
+	authGroup.DELETE("admins/:id", ctrl.AdminController.Destroy)     // 删除
+	authGroup.POST("admins/:id/unlock", ctrl.AdminController.Unlock) // 解锁
 
 	// ------------ 角色管理 ------------
-	authGroup.GET("roles", ctrl.RoleController.Page)           // 分页列表
-	authGroup.GET("roles/:id", ctrl.RoleController.Show)       // 详情
-	authGroup.POST("roles", ctrl.RoleController.Store)         // 新增
-	authGroup.PUT("roles/:id", ctrl.RoleController.Update)     // 更新
-	authGroup.DELETE("roles/:id", ctrl.RoleController.Destroy) // 删除
+	authGroup.GET("roles", ctrl.RoleController.Page)                                           // 分页列表
+	authGroup.GET("roles/:id", ctrl.RoleController.Show)                                       // 详情
+	authGroup.POST("roles", ctrl.RoleController.Store)                                         // 新增
+	authGroup.PUT("roles/:id", ctrl.RoleController.Update)                                     // 更新
+	authGroup.DELETE("roles/:id", ctrl.RoleController.Destroy)                                 // 删除
+	authGroup.GET("roles/:id/effective-permissions", ctrl.RoleController.EffectivePermissions) // 生效权限
+	authGroup.GET("roles/tree", ctrl.RoleController.Tree)                                      // 角色继承树
+	authGroup.POST("roles/:id/children", ctrl.RoleController.AttachChildren)                   // 设置下级角色
+	authGroup.DELETE("roles/:id/children/:child_id", ctrl.RoleController.DetachChild)          // 解除下级角色
 
 	// ------------ 租户管理 ------------
-	authGroup.GET("tenants", ctrl.TenantController.Page)           // 分页列表
-	authGroup.GET("tenants/:id", ctrl.TenantController.Show)       // 详情
-	authGroup.POST("tenants", ctrl.TenantController.Store)         // 新增
-	authGroup.PUT("tenants/:id", ctrl.TenantController.Update)     // 更新
-	authGroup.DELETE("tenants/:id", ctrl.TenantController.Destroy) // 删除
+	authGroup.GET("tenants", ctrl.TenantController.Page)                      // 分页列表
+	authGroup.GET("tenants/:id", ctrl.TenantController.Show)                  // 详情
+	authGroup.POST("tenants", ctrl.TenantController.Store)                    // 新增
+	authGroup.PUT("tenants/:id", ctrl.TenantController.Update)                // 更新
+	authGroup.DELETE("tenants/:id", ctrl.TenantController.Destroy)            // 删除
+	authGroup.PUT("tenants/:id/mfa", ctrl.TenantController.UpdateMfa)         // 两步验证设置
+	authGroup.PUT("tenants/:id/status", ctrl.TenantController.UpdateStatus)   // 状态变更
+	authGroup.GET("tenants/:id/modules", ctrl.TenantController.Modules)       // 模块授权情况
+	authGroup.PUT("tenants/:id/modules", ctrl.TenantController.UpdateModules) // 模块授权
+
+	// ------------ 菜单管理 ------------
+	authGroup.GET("menus", ctrl.MenuController.Page)                    // 分页列表
+	authGroup.GET("menus/tree", ctrl.MenuController.Tree)               // 菜单树
+	authGroup.GET("menus/:id", ctrl.MenuController.Show)                // 详情
+	authGroup.POST("menus", ctrl.MenuController.Store)                  // 新增
+	authGroup.PUT("menus/sort", ctrl.MenuController.Sort)               // 排序
+	authGroup.PUT("menus/:id", ctrl.MenuController.Update)              // 更新
+	authGroup.DELETE("menus/:id", ctrl.MenuController.Destroy)          // 删除
+	authGroup.PUT("menus/:id/status", ctrl.MenuController.UpdateStatus) // 停用、启用
+
+	// ------------ 审计日志 ------------
+	authGroup.GET("audit-logs", ctrl.AuditLogController.Page) // 分页列表
+	middleware.RegisterAuditResource("/admin/admins", &core_model.Admin{})
+	middleware.RegisterAuditResource("/admin/roles", &core_model.Role{})
+	middleware.RegisterAuditResource("/admin/tenants", &core_model.Tenant{})
+	middleware.RegisterAuditResource("/admin/menus", &core_model.Menu{})
+	middleware.RegisterAuditResource("/admin/users", &core_model.User{})
+
+	// ------------ 系统维护 ------------
+	authGroup.POST("system/casbin:reconcile", ctrl.SystemController.ReconcileCasbin) // 权限规则重建
 }
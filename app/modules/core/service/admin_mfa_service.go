@@ -0,0 +1,323 @@
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image/png"
+	"strings"
+	"time"
+
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/config"
+	"github.com/maxlcoder/homework-backend/pkg/tenant"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/hotp"
+	"github.com/pquerna/otp/totp"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrMfaNotEnrolled    = errors.New("尚未绑定两步验证")
+	ErrMfaAlreadyEnabled = errors.New("两步验证已启用")
+	ErrMfaMandatory      = errors.New("所属租户要求启用两步验证，无法关闭")
+	ErrInvalidMfaCode    = errors.New("验证码错误")
+)
+
+// TOTP 参数（RFC 6238），与主流验证器 App 默认值一致
+const (
+	mfaPeriod = 30
+	mfaSkew   = 1
+)
+
+// MfaEnrollment 绑定信息，Uri 为 otpauth:// 地址，QrCode 为二维码图片（data URI）
+type MfaEnrollment struct {
+	Secret string `json:"secret"`
+	Uri    string `json:"uri"`
+	QrCode string `json:"qr_code"`
+}
+
+type AdminMfaServiceInterface interface {
+	// IsRequired 登录是否需要两步验证：已启用或所属租户强制要求
+	IsRequired(admin *model.Admin) (bool, error)
+	// IsMandatory 所属租户是否强制要求两步验证
+	IsMandatory(adminId uint) (bool, error)
+	// Enroll 生成待确认的 TOTP 密钥
+	Enroll(admin *model.Admin) (*MfaEnrollment, error)
+	// Confirm 校验验证码后启用两步验证，返回恢复码
+	Confirm(admin *model.Admin, code string) ([]string, error)
+	// Verify 校验 TOTP 验证码或恢复码
+	Verify(admin *model.Admin, code string) error
+	// Disable 关闭两步验证
+	Disable(admin *model.Admin, code string) error
+	// RegenerateRecoveryCodes 重新生成恢复码，旧恢复码全部作废
+	RegenerateRecoveryCodes(admin *model.Admin, code string) ([]string, error)
+}
+
+type AdminMfaService struct {
+	db *gorm.DB
+}
+
+func NewAdminMfaService(db *gorm.DB) AdminMfaServiceInterface {
+	return &AdminMfaService{
+		db: db,
+	}
+}
+
+// 配置缺省值
+func mfaConfig() config.MfaConfig {
+	mfaConfig := config.MfaConfig{}
+	if conf := config.GetConfig(); conf != nil {
+		mfaConfig = conf.Mfa
+	}
+	if mfaConfig.Issuer == "" {
+		mfaConfig.Issuer = "Homework"
+	}
+	if mfaConfig.PendingTimeout <= 0 {
+		mfaConfig.PendingTimeout = 5 * time.Minute
+	}
+	if mfaConfig.RecoveryCodes <= 0 {
+		mfaConfig.RecoveryCodes = 10
+	}
+	return mfaConfig
+}
+
+// MfaPendingTimeout 登录待验证令牌有效期
+func MfaPendingTimeout() time.Duration {
+	return mfaConfig().PendingTimeout
+}
+
+func (u *AdminMfaService) IsRequired(admin *model.Admin) (bool, error) {
+	if admin.MfaEnabled {
+		return true, nil
+	}
+	return u.IsMandatory(admin.ID)
+}
+
+func (u *AdminMfaService) IsMandatory(adminId uint) (bool, error) {
+	// 管理员所属租户：直接关联的租户与所拥有角色的租户，角色跨租户查询
+	var count int64
+	err := u.db.Model(&model.Tenant{}).
+		Where("mfa_required = ?", true).
+		Where(u.db.Where("id IN (?)", u.db.Model(&model.TenantAdmin{}).Select("tenant_id").Where("admin_id = ?", adminId)).
+			Or("id IN (?)", u.db.WithContext(tenant.System(u.db.Statement.Context)).Model(&model.Role{}).Select("roles.tenant_id").
+				Joins("JOIN admin_roles ON admin_roles.role_id = roles.id").
+				Where("admin_roles.admin_id = ?", adminId))).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("租户两步验证配置查询失败: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (u *AdminMfaService) Enroll(admin *model.Admin) (*MfaEnrollment, error) {
+	if admin.MfaEnabled {
+		return nil, ErrMfaAlreadyEnabled
+	}
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      mfaConfig().Issuer,
+		AccountName: admin.Name,
+		Period:      mfaPeriod,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("两步验证密钥生成失败: %w", err)
+	}
+	err = u.db.Model(admin).Updates(map[string]interface{}{
+		"mfa_secret":    key.Secret(),
+		"mfa_last_step": 0,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("两步验证密钥保存失败: %w", err)
+	}
+
+	enrollment := &MfaEnrollment{
+		Secret: key.Secret(),
+		Uri:    key.URL(),
+	}
+	image, err := key.Image(200, 200)
+	if err == nil {
+		var buf bytes.Buffer
+		if png.Encode(&buf, image) == nil {
+			enrollment.QrCode = "data:image/png;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+		}
+	}
+	return enrollment, nil
+}
+
+func (u *AdminMfaService) Confirm(admin *model.Admin, code string) ([]string, error) {
+	if admin.MfaEnabled {
+		return nil, ErrMfaAlreadyEnabled
+	}
+	if admin.MfaSecret == "" {
+		return nil, ErrMfaNotEnrolled
+	}
+	if err := u.verifyTotp(admin, code); err != nil {
+		return nil, err
+	}
+	var codes []string
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(admin).Update("mfa_enabled", true).Error; err != nil {
+			return err
+		}
+		var err error
+		codes, err = replaceRecoveryCodes(tx, admin.ID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("两步验证启用失败: %w", err)
+	}
+	return codes, nil
+}
+
+func (u *AdminMfaService) Verify(admin *model.Admin, code string) error {
+	if !admin.MfaEnabled {
+		return ErrMfaNotEnrolled
+	}
+	code = strings.TrimSpace(code)
+	if len(code) == int(otp.DigitsSix) {
+		return u.verifyTotp(admin, code)
+	}
+	return u.useRecoveryCode(admin, code)
+}
+
+func (u *AdminMfaService) Disable(admin *model.Admin, code string) error {
+	mandatory, err := u.IsMandatory(admin.ID)
+	if err != nil {
+		return err
+	}
+	if mandatory {
+		return ErrMfaMandatory
+	}
+	if err := u.Verify(admin, code); err != nil {
+		return err
+	}
+	err = u.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(admin).Updates(map[string]interface{}{
+			"mfa_enabled":   false,
+			"mfa_secret":    "",
+			"mfa_last_step": 0,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("admin_id = ?", admin.ID).Delete(&model.AdminRecoveryCode{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("两步验证关闭失败: %w", err)
+	}
+	return nil
+}
+
+func (u *AdminMfaService) RegenerateRecoveryCodes(admin *model.Admin, code string) ([]string, error) {
+	if !admin.MfaEnabled {
+		return nil, ErrMfaNotEnrolled
+	}
+	// 仅接受 TOTP 验证码，避免用恢复码换取新的恢复码
+	if err := u.verifyTotp(admin, code); err != nil {
+		return nil, err
+	}
+	var codes []string
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = replaceRecoveryCodes(tx, admin.ID)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("恢复码生成失败: %w", err)
+	}
+	return codes, nil
+}
+
+// 校验 TOTP 验证码，允许前后一个时间步的时钟偏差，同一时间步只能使用一次
+func (u *AdminMfaService) verifyTotp(admin *model.Admin, code string) error {
+	current := time.Now().Unix() / mfaPeriod
+	for step := current - mfaSkew; step <= current+mfaSkew; step++ {
+		if step <= admin.MfaLastStep {
+			continue
+		}
+		expected, err := hotp.GenerateCodeCustom(admin.MfaSecret, uint64(step), hotp.ValidateOpts{
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err != nil {
+			return fmt.Errorf("验证码校验失败: %w", err)
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) != 1 {
+			continue
+		}
+		// 条件更新，并发请求中只有一个能使用该时间步
+		result := u.db.Model(&model.Admin{}).
+			Where("id = ?", admin.ID).
+			Where("mfa_last_step < ?", step).
+			Update("mfa_last_step", step)
+		if result.Error != nil {
+			return fmt.Errorf("验证码校验失败: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidMfaCode
+		}
+		admin.MfaLastStep = step
+		return nil
+	}
+	return ErrInvalidMfaCode
+}
+
+func (u *AdminMfaService) useRecoveryCode(admin *model.Admin, code string) error {
+	result := u.db.Model(&model.AdminRecoveryCode{}).
+		Where("admin_id = ?", admin.ID).
+		Where("code_hash = ?", hashRecoveryCode(code)).
+		Where("used_at IS NULL").
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("恢复码校验失败: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidMfaCode
+	}
+	return nil
+}
+
+// 作废旧恢复码并生成新的一组，明文只在生成时返回一次
+func replaceRecoveryCodes(tx *gorm.DB, adminId uint) ([]string, error) {
+	if err := tx.Where("admin_id = ?", adminId).Delete(&model.AdminRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+	count := mfaConfig().RecoveryCodes
+	codes := make([]string, 0, count)
+	records := make([]model.AdminRecoveryCode, 0, count)
+	for i := 0; i < count; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		records = append(records, model.AdminRecoveryCode{
+			AdminId:  adminId,
+			CodeHash: hashRecoveryCode(code),
+		})
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// 恢复码格式 xxxxx-xxxxx
+func newRecoveryCode() (string, error) {
+	b := make([]byte, 5)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := hex.EncodeToString(b)
+	return code[:5] + "-" + code[5:], nil
+}
+
+func hashRecoveryCode(code string) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(code))))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/hotp"
+)
+
+const testMfaSecret = "JBSWY3DPEHPK3PXP"
+
+func totpCode(t *testing.T, step int64) string {
+	t.Helper()
+	code, err := hotp.GenerateCodeCustom(testMfaSecret, uint64(step), hotp.ValidateOpts{
+		Digits:    otp.DigitsSix,
+		Algorithm: otp.AlgorithmSHA1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func newMfaAdmin(t *testing.T) (*AdminMfaService, *model.Admin) {
+	t.Helper()
+	db := newTestDB(t, &model.Admin{}, &model.AdminRecoveryCode{})
+	admin := &model.Admin{Name: "admin", MfaEnabled: true, MfaSecret: testMfaSecret}
+	if err := db.Create(admin).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &AdminMfaService{db: db}, admin
+}
+
+func TestVerifyTotpRejectsReplay(t *testing.T) {
+	service, admin := newMfaAdmin(t)
+	step := time.Now().Unix() / mfaPeriod
+	code := totpCode(t, step)
+
+	if err := service.Verify(admin, code); err != nil {
+		t.Fatalf("有效验证码应通过, got %v", err)
+	}
+	if err := service.Verify(admin, code); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Fatalf("同一时间步的验证码不能重复使用, got %v", err)
+	}
+
+	// 并发请求持有旧的管理员数据，由条件更新拒绝
+	stale := *admin
+	stale.MfaLastStep = 0
+	if err := service.Verify(&stale, code); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Fatalf("并发重放应拒绝, got %v", err)
+	}
+
+	// 已使用时间步之前的验证码同样拒绝
+	if err := service.Verify(admin, totpCode(t, step-1)); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Fatalf("早于已使用时间步的验证码应拒绝, got %v", err)
+	}
+	if err := service.Verify(admin, totpCode(t, step+1)); err != nil {
+		t.Fatalf("时钟偏差范围内的下一个时间步应通过, got %v", err)
+	}
+}
+
+func TestVerifyTotpRejectsOutsideSkew(t *testing.T) {
+	service, admin := newMfaAdmin(t)
+	step := time.Now().Unix() / mfaPeriod
+	if err := service.Verify(admin, totpCode(t, step-mfaSkew-2)); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Fatalf("超出时钟偏差的验证码应拒绝, got %v", err)
+	}
+}
+
+func TestRecoveryCodeSingleUse(t *testing.T) {
+	service, admin := newMfaAdmin(t)
+	codes, err := replaceRecoveryCodes(service.db, admin.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) == 0 {
+		t.Fatal("应生成恢复码")
+	}
+	if err := service.Verify(admin, codes[0]); err != nil {
+		t.Fatalf("恢复码应通过, got %v", err)
+	}
+	if err := service.Verify(admin, codes[0]); !errors.Is(err, ErrInvalidMfaCode) {
+		t.Fatalf("恢复码只能使用一次, got %v", err)
+	}
+}
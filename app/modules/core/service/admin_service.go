@@ -1,17 +1,22 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/maxlcoder/homework-backend/app/modules/core/admin/request"
 	"github.com/maxlcoder/homework-backend/app/modules/core/model"
 	base_model "github.com/maxlcoder/homework-backend/model"
+	"github.com/maxlcoder/homework-backend/pkg/tenant"
 	"github.com/maxlcoder/homework-backend/repository"
 	"github.com/samber/lo"
 	"gorm.io/gorm"
 )
 
 type AdminServiceInterface interface {
+	// WithContext 绑定请求 context，列表按当前操作人的数据权限过滤
+	WithContext(ctx context.Context) AdminServiceInterface
 	Page(pageRequest request.AdminPageRequest) ([]model.Admin, int64, error)
 	Create(admin *model.Admin, roles []model.Role) (*model.Admin, error)
 	Update(admin *model.Admin, roles []model.Role) (*model.Admin, error)
@@ -19,6 +24,9 @@ type AdminServiceInterface interface {
 	FindById(id uint) (*model.Admin, error)
 	GetMenusByRoleId(roleId uint) ([]*model.Menu, error)
 	GetMenusWithChildrenByRoleId(roleId uint) ([]*model.Menu, error)
+	GetRoles(adminId uint) ([]model.Role, error)
+	ActiveRole(admin *model.Admin) (*model.Role, error)
+	SwitchRole(adminId uint, roleId uint) (*model.Role, error)
 }
 
 type AdminService struct {
@@ -31,11 +39,18 @@ func NewAdminService(db *gorm.DB) AdminServiceInterface {
 	}
 }
 
+func (u *AdminService) WithContext(ctx context.Context) AdminServiceInterface {
+	return &AdminService{
+		db: u.db.WithContext(ctx),
+	}
+}
+
 func (u *AdminService) Page(pageRequest request.AdminPageRequest) ([]model.Admin, int64, error) {
 	cond := repository.ConditionScope{
 		Preloads: []string{
 			"Roles",
 		},
+		DataScope: true,
 		Scopes: []func(*gorm.DB) *gorm.DB{
 			func(db *gorm.DB) *gorm.DB {
 				if pageRequest.Name != nil && len(*pageRequest.Name) > 0 {
@@ -94,6 +109,9 @@ func (u *AdminService) Create(admin *model.Admin, roles []model.Role) (*model.Ad
 	if roleCount != int64(len(roles)) {
 		return nil, fmt.Errorf("角色参数校验失败，请检查")
 	}
+	if err := u.checkDepartment(admin.DepartmentId); err != nil {
+		return nil, err
+	}
 
 	err = repository.NewBaseRepository[model.Admin](u.db).Create(admin, nil)
 	if err != nil {
@@ -103,6 +121,38 @@ func (u *AdminService) Create(admin *model.Admin, roles []model.Role) (*model.Ad
 	return admin, nil
 }
 
+// 部门需存在且属于当前租户
+func (u *AdminService) checkDepartment(departmentId uint) error {
+	if departmentId == 0 {
+		return nil
+	}
+	var count int64
+	if err := u.db.Model(&model.Department{}).Where("id = ?", departmentId).Count(&count).Error; err != nil {
+		return fmt.Errorf("部门查询失败: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("部门不存在，请检查")
+	}
+	return nil
+}
+
+// 租户管理员只能操作通过 tenant_admins 关联到本租户的账号，平台租户不限制
+func (u *AdminService) checkTenant(id uint) error {
+	tenantId, ok := tenant.FromContext(u.db.Statement.Context)
+	if !ok || tenantId == tenant.PlatformTenantId {
+		return nil
+	}
+	var count int64
+	err := u.db.Model(&model.TenantAdmin{}).Where("tenant_id = ? AND admin_id = ?", tenantId, id).Count(&count).Error
+	if err != nil {
+		return fmt.Errorf("账号查询失败: %w", err)
+	}
+	if count == 0 {
+		return fmt.Errorf("账号不存在")
+	}
+	return nil
+}
+
 func (u *AdminService) Update(admin *model.Admin, roles []model.Role) (*model.Admin, error) {
 	// 判断是否存在已经适用的名称（排除自身）
 	filter := model.AdminFilter{
@@ -145,6 +195,12 @@ func (u *AdminService) Update(admin *model.Admin, roles []model.Role) (*model.Ad
 	if roleCount != int64(len(roles)) {
 		return nil, fmt.Errorf("角色参数校验失败，请检查")
 	}
+	if err := u.checkDepartment(admin.DepartmentId); err != nil {
+		return nil, err
+	}
+	if err := u.checkTenant(admin.ID); err != nil {
+		return nil, err
+	}
 
 	err = u.db.Transaction(func(tx *gorm.DB) error {
 		// 删除之前的 admin_roles 关联
@@ -170,6 +226,9 @@ func (u *AdminService) Update(admin *model.Admin, roles []model.Role) (*model.Ad
 }
 
 func (u *AdminService) Delete(id uint) error {
+	if err := u.checkTenant(id); err != nil {
+		return err
+	}
 	err := u.db.Transaction(func(tx *gorm.DB) error {
 		// 删除 admins
 		repository.NewBaseRepository[model.Admin](u.db).DeleteById(id, tx)
@@ -187,6 +246,9 @@ func (u *AdminService) Delete(id uint) error {
 }
 
 func (u *AdminService) FindById(id uint) (*model.Admin, error) {
+	if err := u.checkTenant(id); err != nil {
+		return nil, err
+	}
 	filter := model.AdminFilter{
 		ID: &id,
 	}
@@ -207,13 +269,19 @@ func (u *AdminService) FindById(id uint) (*model.Admin, error) {
 }
 
 func (u *AdminService) GetMenusByRoleId(roleId uint) ([]*model.Menu, error) {
+	// 含上级角色的菜单
+	roleIds, err := roleChainIds(u.db, roleId)
+	if err != nil {
+		return nil, err
+	}
 	var menus []model.Menu
 	subQuery := u.db.Model(&model.RoleMenu{}).
 		Select("1").
 		Where("role_menus.menu_id = menus.id").
-		Where("role_id = ?", roleId)
+		Where("role_id IN ?", roleIds)
 
-	err := u.db.Where("EXISTS (?)", subQuery).Find(&menus).Error
+	// 已停用的菜单不显示
+	err = u.db.Where("EXISTS (?)", subQuery).Where("is_disabled = ?", false).Order("sort").Order("id").Find(&menus).Error
 	if err != nil {
 		return nil, err
 	}
@@ -242,3 +310,74 @@ func buildMenuTree(menus []*model.Menu, parentId uint) []*model.Menu {
 	}
 	return tree
 }
+
+// GetRoles 管理员拥有的全部角色，停用或归档租户下的角色不可用
+func (u *AdminService) GetRoles(adminId uint) ([]model.Role, error) {
+	var roles []model.Role
+	// 管理员可在多个租户下拥有角色，按系统任务跨租户查询
+	err := u.db.WithContext(tenant.System(u.db.Statement.Context)).Model(&model.Role{}).
+		Joins("JOIN admin_roles ON admin_roles.role_id = roles.id").
+		Joins("LEFT JOIN tenants ON tenants.id = roles.tenant_id").
+		Where("admin_roles.admin_id = ?", adminId).
+		Where("roles.tenant_id = 0 OR tenants.status = ?", model.TenantStatusActive).
+		Order("roles.id").
+		Find(&roles).Error
+	if err != nil {
+		return nil, fmt.Errorf("角色查询失败: %w", err)
+	}
+	return roles, nil
+}
+
+// ActiveRole 登录时的当前角色：上次使用的角色仍在 admin_roles 中则沿用，否则取第一个角色
+func (u *AdminService) ActiveRole(admin *model.Admin) (*model.Role, error) {
+	roles, err := u.GetRoles(admin.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(roles) == 0 {
+		// 有角色但所属租户均不可用时禁止登录
+		var count int64
+		if err := u.db.Model(&model.AdminRole{}).Where("admin_id = ?", admin.ID).Count(&count).Error; err != nil {
+			return nil, fmt.Errorf("角色查询失败: %w", err)
+		}
+		if count > 0 {
+			return nil, ErrTenantSuspended
+		}
+		return nil, nil
+	}
+	if role, ok := lo.Find(roles, func(role model.Role) bool {
+		return role.ID == admin.RoleId
+	}); ok {
+		return &role, nil
+	}
+	role := roles[0]
+	if err := u.db.Model(&model.Admin{}).Where("id = ?", admin.ID).Update("role_id", role.ID).Error; err != nil {
+		return nil, fmt.Errorf("当前角色设置失败: %w", err)
+	}
+	admin.RoleId = role.ID
+	return &role, nil
+}
+
+// SwitchRole 切换当前角色，只能切换到 admin_roles 中已分配的角色
+func (u *AdminService) SwitchRole(adminId uint, roleId uint) (*model.Role, error) {
+	var role model.Role
+	// 可切换到其他租户下的角色，按系统任务跨租户查询
+	err := u.db.WithContext(tenant.System(u.db.Statement.Context)).Model(&model.Role{}).
+		Joins("JOIN admin_roles ON admin_roles.role_id = roles.id").
+		Where("admin_roles.admin_id = ?", adminId).
+		Where("roles.id = ?", roleId).
+		First(&role).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("未分配该角色")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("角色查询失败: %w", err)
+	}
+	if err := CheckTenantActive(u.db, role.TenantID); err != nil {
+		return nil, err
+	}
+	if err := u.db.Model(&model.Admin{}).Where("id = ?", adminId).Update("role_id", roleId).Error; err != nil {
+		return nil, fmt.Errorf("角色切换失败: %w", err)
+	}
+	return &role, nil
+}
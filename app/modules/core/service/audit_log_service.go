@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/maxlcoder/homework-backend/app/modules/core/admin/request"
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	base_model "github.com/maxlcoder/homework-backend/model"
+	"github.com/maxlcoder/homework-backend/pkg/tenant"
+	"github.com/maxlcoder/homework-backend/repository"
+	"gorm.io/gorm"
+)
+
+// 每批清理的审计日志条数，避免长时间锁表
+const auditPurgeBatch = 1000
+
+type AuditLogServiceInterface interface {
+	// WithContext 绑定请求 context，审计日志按 context 中的租户隔离
+	WithContext(ctx context.Context) AuditLogServiceInterface
+	Page(pageRequest request.AuditLogPageRequest) ([]model.AuditLog, int64, error)
+	// Purge 删除 before 之前的审计日志
+	Purge(before time.Time) (int64, error)
+	// StartRetention 按保留时长定期清理审计日志，retention 为 0 时不清理，ctx 取消后停止
+	StartRetention(ctx context.Context, retention time.Duration, interval time.Duration)
+}
+
+type AuditLogService struct {
+	db *gorm.DB
+}
+
+func NewAuditLogService(db *gorm.DB) AuditLogServiceInterface {
+	return &AuditLogService{
+		db: db,
+	}
+}
+
+func (u *AuditLogService) WithContext(ctx context.Context) AuditLogServiceInterface {
+	return &AuditLogService{
+		db: u.db.WithContext(ctx),
+	}
+}
+
+func (u *AuditLogService) Page(pageRequest request.AuditLogPageRequest) ([]model.AuditLog, int64, error) {
+	cond := repository.ConditionScope{
+		Scopes: []func(*gorm.DB) *gorm.DB{
+			func(db *gorm.DB) *gorm.DB {
+				if pageRequest.TenantId != nil {
+					db = db.Where("tenant_id = ?", *pageRequest.TenantId)
+				}
+				if pageRequest.AdminId != nil {
+					db = db.Where("admin_id = ?", *pageRequest.AdminId)
+				}
+				if pageRequest.Method != "" {
+					db = db.Where("method = ?", pageRequest.Method)
+				}
+				if pageRequest.Route != "" {
+					db = repository.LikeScope("route", pageRequest.Route)(db)
+				}
+				if pageRequest.Resource != "" {
+					db = db.Where("resource = ?", pageRequest.Resource)
+				}
+				if pageRequest.TargetId != "" {
+					db = db.Where("target_id = ?", pageRequest.TargetId)
+				}
+				if pageRequest.StartAt != nil {
+					db = db.Where("created_at >= ?", *pageRequest.StartAt)
+				}
+				if pageRequest.EndAt != nil {
+					db = db.Where("created_at <= ?", *pageRequest.EndAt)
+				}
+				return db
+			},
+		},
+		Order: []string{"id DESC"},
+	}
+
+	pagination := base_model.Pagination{
+		Page:    pageRequest.Page,
+		PerPage: pageRequest.PerPage,
+	}
+	total, auditLogs, err := repository.NewBaseRepository[model.AuditLog](u.db).Page(cond, pagination)
+	if err != nil {
+		return nil, 0, fmt.Errorf("审计日志查询失败: %w", err)
+	}
+	return auditLogs, total, nil
+}
+
+func (u *AuditLogService) Purge(before time.Time) (int64, error) {
+	var total int64
+	for {
+		var ids []uint
+		if err := u.db.Model(&model.AuditLog{}).Where("created_at < ?", before).Limit(auditPurgeBatch).Pluck("id", &ids).Error; err != nil {
+			return total, fmt.Errorf("审计日志查询失败: %w", err)
+		}
+		if len(ids) == 0 {
+			return total, nil
+		}
+		result := u.db.Where("id IN ?", ids).Delete(&model.AuditLog{})
+		if result.Error != nil {
+			return total, fmt.Errorf("审计日志清理失败: %w", result.Error)
+		}
+		total += result.RowsAffected
+		if len(ids) < auditPurgeBatch {
+			return total, nil
+		}
+	}
+}
+
+func (u *AuditLogService) StartRetention(ctx context.Context, retention time.Duration, interval time.Duration) {
+	if retention <= 0 {
+		return
+	}
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			count, err := u.WithContext(tenant.System(ctx)).Purge(time.Now().Add(-retention))
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				slog.Error("audit log purge failed", "error", err)
+			} else if count > 0 {
+				slog.Info("审计日志已清理", "count", count)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
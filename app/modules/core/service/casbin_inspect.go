@@ -0,0 +1,201 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/pkg/metrics"
+	"github.com/samber/lo"
+)
+
+// EffectivePermission 角色的一条接口权限及其来源，用于排查授权问题
+type EffectivePermission struct {
+	Name    string   `json:"name"`
+	Path    string   `json:"path"`
+	Method  string   `json:"method"`
+	Menus   []string `json:"menus"`   // 授予该权限的菜单编号
+	Stored  bool     `json:"stored"`  // role_permissions 中存在
+	Rule    bool     `json:"rule"`    // 角色所属租户域中存在对应 casbin p 规则
+	Allowed bool     `json:"allowed"` // enforcer 实际校验结果
+	// 授予该权限的上级角色
+	InheritedFrom []string `json:"inherited_from"`
+}
+
+// RoleEffectivePermissions 角色菜单 -> 权限 -> casbin 规则的解析结果
+type RoleEffectivePermissions struct {
+	RoleId      uint                  `json:"role_id"`
+	Subject     string                `json:"subject"`
+	Domain      string                `json:"domain"`
+	Menus       []string              `json:"menus"`
+	Ancestors   []string              `json:"ancestors"` // 上级角色，由近及远
+	Permissions []EffectivePermission `json:"permissions"`
+	// 该角色在其他租户域中的 p 规则，正常情况下应为空
+	ForeignPolicies [][]string `json:"foreign_policies"`
+	// 该角色的 g 规则，即拥有该角色的管理员及继承该角色的下级角色
+	Groupings [][]string `json:"groupings"`
+}
+
+// Enforce 使用 CasbinMiddleware 相同的参数校验角色能否访问接口，path 为路由定义（如 /admin/admins/:id）
+func (u *CasbinService) Enforce(roleId uint, tenantId uint, path string, method string) (bool, error) {
+	if roleId == 0 {
+		return false, nil
+	}
+	start := time.Now()
+	allowed, err := u.enforcer.Enforce(roleSubject(roleId), tenantDomain(tenantId), path, method)
+	metrics.ObserveCasbinEnforce("check", start, allowed, err)
+	return allowed, err
+}
+
+func (u *CasbinService) EffectivePermissions(role *model.Role) (*RoleEffectivePermissions, error) {
+	subject := roleSubject(role.ID)
+	domain := tenantDomain(role.TenantID)
+	result := &RoleEffectivePermissions{
+		RoleId:          role.ID,
+		Subject:         subject,
+		Domain:          domain,
+		Menus:           []string{},
+		Ancestors:       []string{},
+		Permissions:     []EffectivePermission{},
+		ForeignPolicies: [][]string{},
+		Groupings:       [][]string{},
+	}
+
+	// 角色菜单，超管拥有全部菜单
+	menuQuery := u.db.Model(&model.Menu{})
+	if role.ID != superRoleId {
+		menuQuery = menuQuery.Where("id IN (?)", u.db.Model(&model.RoleMenu{}).Select("menu_id").Where("role_id = ?", role.ID))
+	}
+	var menus []model.Menu
+	if err := menuQuery.Order("id").Find(&menus).Error; err != nil {
+		return nil, fmt.Errorf("角色菜单查询失败: %w", err)
+	}
+	menuNumbers := lo.SliceToMap(menus, func(item model.Menu) (uint, string) {
+		return item.ID, item.Number
+	})
+	result.Menus = lo.Map(menus, func(item model.Menu, index int) string {
+		return item.Number
+	})
+
+	// 菜单对应的权限
+	var menuPermissions []model.MenuPermission
+	if len(menus) > 0 {
+		if err := u.db.Where("menu_id IN ?", lo.Keys(menuNumbers)).Find(&menuPermissions).Error; err != nil {
+			return nil, fmt.Errorf("菜单权限查询失败: %w", err)
+		}
+	}
+	permissionMenus := map[uint][]string{}
+	for _, item := range menuPermissions {
+		permissionMenus[item.PermissionID] = append(permissionMenus[item.PermissionID], menuNumbers[item.MenuID])
+	}
+
+	// 已授予的权限，超管拥有全部权限
+	var storedIds []uint
+	if role.ID == superRoleId {
+		if err := u.db.Model(&model.Permission{}).Pluck("id", &storedIds).Error; err != nil {
+			return nil, fmt.Errorf("权限查询失败: %w", err)
+		}
+	} else {
+		if err := u.db.Model(&model.RolePermission{}).Where("role_id = ?", role.ID).Pluck("permission_id", &storedIds).Error; err != nil {
+			return nil, fmt.Errorf("角色权限查询失败: %w", err)
+		}
+	}
+
+	// 上级角色的权限，effective 为沿继承链的并集
+	chain, err := roleChainIds(u.db, role.ID)
+	if err != nil {
+		return nil, err
+	}
+	permissionAncestors := map[uint][]string{}
+	for _, ancestorId := range chain[1:] {
+		result.Ancestors = append(result.Ancestors, roleSubject(ancestorId))
+		var ancestorIds []uint
+		if err := u.db.Model(&model.RolePermission{}).Where("role_id = ?", ancestorId).Pluck("permission_id", &ancestorIds).Error; err != nil {
+			return nil, fmt.Errorf("角色权限查询失败: %w", err)
+		}
+		for _, id := range ancestorIds {
+			permissionAncestors[id] = append(permissionAncestors[id], roleSubject(ancestorId))
+		}
+	}
+
+	// casbin 规则
+	policies, err := u.enforcer.GetFilteredPolicy(0, subject)
+	if err != nil {
+		return nil, fmt.Errorf("casbin 规则读取失败: %w", err)
+	}
+	// 路径、方法 -> 规则是否存在
+	rules := map[[2]string]bool{}
+	for _, policy := range policies {
+		if len(policy) < 4 {
+			continue
+		}
+		if policy[1] != domain {
+			result.ForeignPolicies = append(result.ForeignPolicies, policy)
+			continue
+		}
+		rules[[2]string{policy[2], policy[3]}] = true
+	}
+	groupings, err := u.enforcer.GetFilteredGroupingPolicy(1, subject)
+	if err != nil {
+		return nil, fmt.Errorf("casbin 规则读取失败: %w", err)
+	}
+	result.Groupings = append(result.Groupings, groupings...)
+
+	var permissions []model.Permission
+	permissionIds := lo.Union(lo.Keys(permissionMenus), storedIds, lo.Keys(permissionAncestors))
+	if len(permissionIds) > 0 {
+		if err := u.db.Where("id IN ?", permissionIds).Find(&permissions).Error; err != nil {
+			return nil, fmt.Errorf("权限查询失败: %w", err)
+		}
+	}
+	for _, permission := range permissions {
+		key := [2]string{permission.PATH, permission.Method}
+		allowed, err := u.enforcer.Enforce(subject, domain, permission.PATH, permission.Method)
+		if err != nil {
+			return nil, fmt.Errorf("casbin 校验失败: %w", err)
+		}
+		granting := permissionMenus[permission.ID]
+		if granting == nil {
+			granting = []string{}
+		}
+		inherited := permissionAncestors[permission.ID]
+		if inherited == nil {
+			inherited = []string{}
+		}
+		result.Permissions = append(result.Permissions, EffectivePermission{
+			Name:          permission.Name,
+			Path:          permission.PATH,
+			Method:        permission.Method,
+			Menus:         granting,
+			Stored:        lo.Contains(storedIds, permission.ID),
+			Rule:          rules[key],
+			Allowed:       allowed,
+			InheritedFrom: inherited,
+		})
+		delete(rules, key)
+	}
+	// 只存在于 casbin 中、没有授权数据对应的规则
+	for key := range rules {
+		path, method := key[0], key[1]
+		allowed, err := u.enforcer.Enforce(subject, domain, path, method)
+		if err != nil {
+			return nil, fmt.Errorf("casbin 校验失败: %w", err)
+		}
+		result.Permissions = append(result.Permissions, EffectivePermission{
+			Path:          path,
+			Method:        method,
+			Menus:         []string{},
+			Rule:          true,
+			Allowed:       allowed,
+			InheritedFrom: []string{},
+		})
+	}
+	sort.Slice(result.Permissions, func(i, j int) bool {
+		if result.Permissions[i].Path != result.Permissions[j].Path {
+			return result.Permissions[i].Path < result.Permissions[j].Path
+		}
+		return result.Permissions[i].Method < result.Permissions[j].Method
+	})
+	return result, nil
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/pkg/tenant"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// 超管角色，拥有全部权限
+const superRoleId uint = 1
+
+// casbin 规则约定：p = role_<角色 ID>, <租户 ID>, 路径, 方法；g = admin_<管理员 ID>, role_<角色 ID>, <租户 ID>
+// 角色继承：g = role_<下级角色 ID>, role_<上级角色 ID>, <租户 ID>
+// 租户 ID 取角色所属租户，平台角色为 0，与 CasbinMiddleware 的校验参数一致
+func tenantDomain(tenantId uint) string {
+	return strconv.FormatUint(uint64(tenantId), 10)
+}
+
+func roleSubject(roleId uint) string {
+	return "role_" + strconv.FormatUint(uint64(roleId), 10)
+}
+
+func adminSubject(adminId uint) string {
+	return "admin_" + strconv.FormatUint(uint64(adminId), 10)
+}
+
+// policyChanges 事务内收集的 casbin 规则变更，事务提交后再写入 enforcer，事务回滚时直接丢弃
+// enforcer 的规则立即生效且会写入 casbin_rule，不能随数据库事务回滚
+type policyChanges struct {
+	ops []func(enforcer *casbin.SyncedEnforcer) error
+}
+
+// 重置角色在域内的 p 规则
+func (c *policyChanges) resetPermissions(roleId uint, domain string, rules [][]string) {
+	c.ops = append(c.ops, func(enforcer *casbin.SyncedEnforcer) error {
+		if _, err := enforcer.RemoveFilteredPolicy(0, roleSubject(roleId), domain); err != nil {
+			return err
+		}
+		if len(rules) == 0 {
+			return nil
+		}
+		_, err := enforcer.AddPermissionsForUser(roleSubject(roleId), rules...)
+		return err
+	})
+}
+
+func (c *policyChanges) removeFilteredPolicy(fieldIndex int, fieldValues ...string) {
+	c.ops = append(c.ops, func(enforcer *casbin.SyncedEnforcer) error {
+		_, err := enforcer.RemoveFilteredPolicy(fieldIndex, fieldValues...)
+		return err
+	})
+}
+
+func (c *policyChanges) addGroupingPolicy(params ...string) {
+	c.ops = append(c.ops, func(enforcer *casbin.SyncedEnforcer) error {
+		_, err := enforcer.AddGroupingPolicy(params)
+		return err
+	})
+}
+
+func (c *policyChanges) removeGroupingPolicy(params ...string) {
+	c.ops = append(c.ops, func(enforcer *casbin.SyncedEnforcer) error {
+		_, err := enforcer.RemoveGroupingPolicy(params)
+		return err
+	})
+}
+
+func (c *policyChanges) removeFilteredGroupingPolicy(fieldIndex int, fieldValues ...string) {
+	c.ops = append(c.ops, func(enforcer *casbin.SyncedEnforcer) error {
+		_, err := enforcer.RemoveFilteredGroupingPolicy(fieldIndex, fieldValues...)
+		return err
+	})
+}
+
+// 按收集顺序写入 enforcer，失败时授权数据已提交，可通过规则重建修复
+func (c *policyChanges) apply(enforcer *casbin.SyncedEnforcer) error {
+	for _, op := range c.ops {
+		if err := op(enforcer); err != nil {
+			return fmt.Errorf("角色Casbin授权失败，请执行规则重建: %w", err)
+		}
+	}
+	return nil
+}
+
+// CasbinDiff 当前 casbin_rule 与数据库授权数据的差异
+type CasbinDiff struct {
+	DryRun          bool       `json:"dry_run"`
+	AddPolicies     [][]string `json:"add_policies"`
+	RemovePolicies  [][]string `json:"remove_policies"`
+	AddGroupings    [][]string `json:"add_groupings"`
+	RemoveGroupings [][]string `json:"remove_groupings"`
+}
+
+// Changed 是否存在差异
+func (d *CasbinDiff) Changed() bool {
+	return len(d.AddPolicies)+len(d.RemovePolicies)+len(d.AddGroupings)+len(d.RemoveGroupings) > 0
+}
+
+type CasbinServiceInterface interface {
+	// Reconcile 按 roles / role_permissions / admin_roles / 角色继承重建 casbin 规则，dryRun 时只返回差异
+	Reconcile(dryRun bool) (*CasbinDiff, error)
+	// Enforce 校验角色能否访问接口
+	Enforce(roleId uint, tenantId uint, path string, method string) (bool, error)
+	// EffectivePermissions 解析角色菜单、权限与 casbin 规则
+	EffectivePermissions(role *model.Role) (*RoleEffectivePermissions, error)
+	// CheckLoaded 就绪检查：enforcer 已加载规则
+	CheckLoaded() error
+}
+
+type CasbinService struct {
+	db       *gorm.DB
+	enforcer *casbin.SyncedEnforcer
+}
+
+func NewCasbinService(db *gorm.DB, enforcer *casbin.SyncedEnforcer) CasbinServiceInterface {
+	// casbin 规则按全部租户的授权数据重建，按系统任务处理
+	return &CasbinService{
+		db:       db.WithContext(tenant.System(db.Statement.Context)),
+		enforcer: enforcer,
+	}
+}
+
+func (u *CasbinService) CheckLoaded() error {
+	if u.enforcer == nil {
+		return errors.New("casbin enforcer 未初始化")
+	}
+	policies, err := u.enforcer.GetPolicy()
+	if err != nil {
+		return err
+	}
+	// 超管角色始终有规则，为空说明规则未加载
+	if len(policies) == 0 {
+		return errors.New("casbin 规则未加载")
+	}
+	return nil
+}
+
+// 期望的 p 规则：超管角色拥有全部权限，其他角色取 role_permissions
+func (u *CasbinService) expectedPolicies() ([][]string, error) {
+	type rolePermission struct {
+		RoleId   uint
+		TenantId uint
+		Path     string
+		Method   string
+	}
+	var rows []rolePermission
+	err := u.db.Model(&model.RolePermission{}).
+		Select("roles.id AS role_id", "roles.tenant_id", "permissions.path", "permissions.method").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Where("roles.id <> ?", superRoleId).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("角色权限查询失败: %w", err)
+	}
+	policies := lo.Map(rows, func(item rolePermission, index int) []string {
+		return []string{roleSubject(item.RoleId), tenantDomain(item.TenantId), item.Path, item.Method}
+	})
+
+	var permissions []model.Permission
+	if err := u.db.Find(&permissions).Error; err != nil {
+		return nil, fmt.Errorf("权限查询失败: %w", err)
+	}
+	for _, permission := range permissions {
+		policies = append(policies, []string{roleSubject(superRoleId), tenantDomain(0), permission.PATH, permission.Method})
+	}
+	return policies, nil
+}
+
+// 期望的 g 规则：管理员与角色的关联、角色继承
+func (u *CasbinService) expectedGroupings() ([][]string, error) {
+	type adminRole struct {
+		AdminId  uint
+		RoleId   uint
+		TenantId uint
+	}
+	var rows []adminRole
+	err := u.db.Model(&model.AdminRole{}).
+		Select("admin_roles.admin_id", "admin_roles.role_id", "roles.tenant_id").
+		Joins("JOIN roles ON roles.id = admin_roles.role_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("管理员角色查询失败: %w", err)
+	}
+	groupings := lo.Map(rows, func(item adminRole, index int) []string {
+		return []string{adminSubject(item.AdminId), roleSubject(item.RoleId), tenantDomain(item.TenantId)}
+	})
+
+	var roles []model.Role
+	if err := u.db.Select("id", "tenant_id", "parent_id").Where("parent_id <> ?", 0).Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("角色查询失败: %w", err)
+	}
+	for _, role := range roles {
+		groupings = append(groupings, []string{roleSubject(role.ID), roleSubject(role.ParentID), tenantDomain(role.TenantID)})
+	}
+	return groupings, nil
+}
+
+// 规则差异：expected 中缺少的需新增，actual 中多余的需删除
+func diffRules(expected [][]string, actual [][]string) ([][]string, [][]string) {
+	key := func(rule []string) string {
+		return strings.Join(rule, "\x00")
+	}
+	expected = lo.UniqBy(expected, key)
+	expectedKeys := lo.SliceToMap(expected, func(rule []string) (string, struct{}) {
+		return key(rule), struct{}{}
+	})
+	actualKeys := lo.SliceToMap(actual, func(rule []string) (string, struct{}) {
+		return key(rule), struct{}{}
+	})
+	add := lo.Filter(expected, func(rule []string, index int) bool {
+		_, ok := actualKeys[key(rule)]
+		return !ok
+	})
+	remove := lo.Filter(actual, func(rule []string, index int) bool {
+		_, ok := expectedKeys[key(rule)]
+		return !ok
+	})
+	return add, remove
+}
+
+func (u *CasbinService) Reconcile(dryRun bool) (*CasbinDiff, error) {
+	expectedPolicies, err := u.expectedPolicies()
+	if err != nil {
+		return nil, err
+	}
+	expectedGroupings, err := u.expectedGroupings()
+	if err != nil {
+		return nil, err
+	}
+	actualPolicies, err := u.enforcer.GetPolicy()
+	if err != nil {
+		return nil, fmt.Errorf("casbin 规则读取失败: %w", err)
+	}
+	actualGroupings, err := u.enforcer.GetGroupingPolicy()
+	if err != nil {
+		return nil, fmt.Errorf("casbin 规则读取失败: %w", err)
+	}
+
+	diff := &CasbinDiff{DryRun: dryRun}
+	diff.AddPolicies, diff.RemovePolicies = diffRules(expectedPolicies, actualPolicies)
+	diff.AddGroupings, diff.RemoveGroupings = diffRules(expectedGroupings, actualGroupings)
+	if dryRun || !diff.Changed() {
+		return diff, nil
+	}
+
+	if len(diff.RemovePolicies) > 0 {
+		if _, err := u.enforcer.RemovePolicies(diff.RemovePolicies); err != nil {
+			return nil, fmt.Errorf("casbin 规则删除失败: %w", err)
+		}
+	}
+	if len(diff.AddPolicies) > 0 {
+		if _, err := u.enforcer.AddPolicies(diff.AddPolicies); err != nil {
+			return nil, fmt.Errorf("casbin 规则新增失败: %w", err)
+		}
+	}
+	if len(diff.RemoveGroupings) > 0 {
+		if _, err := u.enforcer.RemoveGroupingPolicies(diff.RemoveGroupings); err != nil {
+			return nil, fmt.Errorf("casbin 规则删除失败: %w", err)
+		}
+	}
+	if len(diff.AddGroupings) > 0 {
+		if _, err := u.enforcer.AddGroupingPolicies(diff.AddGroupings); err != nil {
+			return nil, fmt.Errorf("casbin 规则新增失败: %w", err)
+		}
+	}
+	return diff, nil
+}
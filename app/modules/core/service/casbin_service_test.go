@@ -0,0 +1,109 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"gorm.io/gorm"
+)
+
+func newTestEnforcer(t *testing.T) *casbin.SyncedEnforcer {
+	t.Helper()
+	enforcer, err := casbin.NewSyncedEnforcer("../../../../config/rbac_with_domains_model.conf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enforcer
+}
+
+// 超管角色 1、租户 2 的角色 2 及其下级角色 3，管理员 2 拥有角色 2
+func seedCasbinData(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	roles := []model.Role{{Name: "super_admin"}, {Name: "tenant_admin"}, {Name: "staff", ParentID: 2}}
+	roles[0].ID, roles[1].ID, roles[2].ID = 1, 2, 3
+	roles[1].TenantID, roles[2].TenantID = 2, 2
+	permissions := []model.Permission{{PATH: "/admin/roles", Method: "GET"}, {PATH: "/admin/roles", Method: "POST"}}
+	records := []interface{}{
+		&roles,
+		&permissions,
+		&[]model.RolePermission{{RoleID: 2, PermissionID: 1}},
+		&[]model.AdminRole{{AdminId: 1, RoleId: 1}, {AdminId: 2, RoleId: 2}},
+	}
+	for _, record := range records {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDiffRules(t *testing.T) {
+	expected := [][]string{{"role_2", "2", "/a", "GET"}, {"role_2", "2", "/a", "GET"}, {"role_2", "2", "/b", "GET"}}
+	actual := [][]string{{"role_2", "2", "/b", "GET"}, {"role_2", "0", "/b", "GET"}}
+	add, remove := diffRules(expected, actual)
+	if !reflect.DeepEqual(add, [][]string{{"role_2", "2", "/a", "GET"}}) {
+		t.Errorf("新增规则应去重且只含缺少的规则, got %v", add)
+	}
+	if !reflect.DeepEqual(remove, [][]string{{"role_2", "0", "/b", "GET"}}) {
+		t.Errorf("应删除多余的规则, got %v", remove)
+	}
+}
+
+func TestCasbinReconcile(t *testing.T) {
+	db := newTestDB(t, &model.Role{}, &model.Permission{}, &model.RolePermission{}, &model.AdminRole{})
+	seedCasbinData(t, db)
+	enforcer := newTestEnforcer(t)
+	// 历史数据：租户角色的规则写在平台域，另有一条已收回的授权
+	enforcer.AddPolicies([][]string{
+		{"role_2", "0", "/admin/roles", "GET"},
+		{"role_2", "2", "/admin/roles", "POST"},
+	})
+	enforcer.AddGroupingPolicy("admin_2", "role_2", "2")
+	service := NewCasbinService(db, enforcer)
+
+	diff, err := service.Reconcile(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantAdd := [][]string{
+		{"role_2", "2", "/admin/roles", "GET"},
+		{"role_1", "0", "/admin/roles", "GET"},
+		{"role_1", "0", "/admin/roles", "POST"},
+	}
+	if !reflect.DeepEqual(diff.AddPolicies, wantAdd) {
+		t.Errorf("add policies got %v", diff.AddPolicies)
+	}
+	wantRemove := [][]string{{"role_2", "0", "/admin/roles", "GET"}, {"role_2", "2", "/admin/roles", "POST"}}
+	if !reflect.DeepEqual(diff.RemovePolicies, wantRemove) {
+		t.Errorf("remove policies got %v", diff.RemovePolicies)
+	}
+	wantGroupings := [][]string{{"admin_1", "role_1", "0"}, {"role_3", "role_2", "2"}}
+	if !reflect.DeepEqual(diff.AddGroupings, wantGroupings) {
+		t.Errorf("add groupings got %v", diff.AddGroupings)
+	}
+	if len(diff.RemoveGroupings) != 0 {
+		t.Errorf("remove groupings got %v", diff.RemoveGroupings)
+	}
+	// dry-run 不修改规则
+	if ok, _ := enforcer.Enforce("admin_2", "2", "/admin/roles", "GET"); ok {
+		t.Fatal("dry-run 不应修改规则")
+	}
+
+	if _, err := service.Reconcile(false); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := enforcer.Enforce("admin_2", "2", "/admin/roles", "GET"); !ok {
+		t.Error("重建后租户角色应在租户域内生效")
+	}
+	if ok, _ := enforcer.Enforce("admin_2", "2", "/admin/roles", "POST"); ok {
+		t.Error("已收回的授权应删除")
+	}
+	diff, err = service.Reconcile(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.Changed() {
+		t.Errorf("重建后不应再有差异, got %+v", diff)
+	}
+}
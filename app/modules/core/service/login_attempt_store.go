@@ -0,0 +1,154 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AttemptStore 登录失败计数存储，单实例可用内存，多实例需使用共享存储
+type AttemptStore interface {
+	// Get 返回窗口期内的失败次数与最后失败时间
+	Get(key string) (int, time.Time, error)
+	// Incr 失败次数加一，window 为计数窗口，返回累计次数
+	Incr(key string, window time.Duration) (int, error)
+	// Reset 清除计数
+	Reset(key string) error
+}
+
+type memoryAttempt struct {
+	count        int
+	lastFailedAt time.Time
+	expiresAt    time.Time
+}
+
+// 内存计数清理间隔，过期的计数在新增失败时按间隔统一清理
+const memoryAttemptSweepInterval = time.Minute
+
+// MemoryAttemptStore 进程内计数存储
+type MemoryAttemptStore struct {
+	mu        sync.Mutex
+	attempts  map[string]*memoryAttempt
+	lastSweep time.Time
+}
+
+func NewMemoryAttemptStore() *MemoryAttemptStore {
+	return &MemoryAttemptStore{
+		attempts: make(map[string]*memoryAttempt),
+	}
+}
+
+func (s *MemoryAttemptStore) Get(key string) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt, ok := s.attempts[key]
+	if !ok {
+		return 0, time.Time{}, nil
+	}
+	if attempt.expiresAt.Before(time.Now()) {
+		delete(s.attempts, key)
+		return 0, time.Time{}, nil
+	}
+	return attempt.count, attempt.lastFailedAt, nil
+}
+
+func (s *MemoryAttemptStore) Incr(key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+	attempt, ok := s.attempts[key]
+	if !ok || attempt.expiresAt.Before(now) {
+		attempt = &memoryAttempt{}
+		s.attempts[key] = attempt
+	}
+	attempt.count++
+	attempt.lastFailedAt = now
+	attempt.expiresAt = now.Add(window)
+	return attempt.count, nil
+}
+
+func (s *MemoryAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+// 清理过期计数，避免大量不同账号或 IP 的失败记录常驻内存，调用方需持有锁
+func (s *MemoryAttemptStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memoryAttemptSweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, attempt := range s.attempts {
+		if attempt.expiresAt.Before(now) {
+			delete(s.attempts, key)
+		}
+	}
+}
+
+// DatabaseAttemptStore 数据库计数存储，多实例共享
+type DatabaseAttemptStore struct {
+	db *gorm.DB
+}
+
+func NewDatabaseAttemptStore(db *gorm.DB) *DatabaseAttemptStore {
+	return &DatabaseAttemptStore{
+		db: db,
+	}
+}
+
+func (s *DatabaseAttemptStore) Get(key string) (int, time.Time, error) {
+	var attempt model.LoginAttempt
+	err := s.db.Where("`key` = ?", key).Where("expires_at > ?", time.Now()).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, time.Time{}, nil
+	}
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	return attempt.Count, attempt.LastFailedAt, nil
+}
+
+func (s *DatabaseAttemptStore) Incr(key string, window time.Duration) (int, error) {
+	var count int
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var attempt model.LoginAttempt
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("`key` = ?", key).First(&attempt).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			attempt = model.LoginAttempt{Key: key}
+		} else if err != nil {
+			return err
+		}
+		if attempt.ExpiresAt.Before(now) {
+			attempt.Count = 0
+		}
+		attempt.Count++
+		attempt.LastFailedAt = now
+		attempt.ExpiresAt = now.Add(window)
+		count = attempt.Count
+		if attempt.ID > 0 {
+			return tx.Save(&attempt).Error
+		}
+		// 并发首次插入时唯一索引冲突，退化为累加
+		return tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"count":          gorm.Expr("`count` + 1"),
+				"last_failed_at": attempt.LastFailedAt,
+				"expires_at":     attempt.ExpiresAt,
+			}),
+		}).Create(&attempt).Error
+	})
+	return count, err
+}
+
+func (s *DatabaseAttemptStore) Reset(key string) error {
+	return s.db.Where("`key` = ?", key).Delete(&model.LoginAttempt{}).Error
+}
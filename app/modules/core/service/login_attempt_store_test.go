@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryAttemptStoreWindow(t *testing.T) {
+	store := NewMemoryAttemptStore()
+	for i := 1; i <= 3; i++ {
+		count, err := store.Incr("admin:1", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != i {
+			t.Fatalf("第 %d 次失败计数应为 %d, got %d", i, i, count)
+		}
+	}
+	if count, _, _ := store.Get("admin:1"); count != 3 {
+		t.Fatalf("got %d", count)
+	}
+	if err := store.Reset("admin:1"); err != nil {
+		t.Fatal(err)
+	}
+	if count, _, _ := store.Get("admin:1"); count != 0 {
+		t.Fatalf("重置后计数应为 0, got %d", count)
+	}
+
+	// 窗口过期后重新计数
+	store.Incr("admin:2", -time.Second)
+	if count, _ := store.Incr("admin:2", time.Minute); count != 1 {
+		t.Fatalf("窗口过期后应重新计数, got %d", count)
+	}
+}
+
+func TestMemoryAttemptStoreSweep(t *testing.T) {
+	store := NewMemoryAttemptStore()
+	for _, key := range []string{"ip:1", "ip:2", "ip:3"} {
+		store.Incr(key, -time.Second)
+	}
+	store.lastSweep = time.Time{}
+	store.Incr("ip:4", time.Minute)
+	if len(store.attempts) != 1 {
+		t.Fatalf("过期计数应被清理, got %d", len(store.attempts))
+	}
+
+	// 清理间隔内不重复遍历
+	store.Incr("ip:5", -time.Second)
+	store.Incr("ip:6", time.Minute)
+	if len(store.attempts) != 3 {
+		t.Fatalf("got %d", len(store.attempts))
+	}
+	store.lastSweep = time.Now().Add(-memoryAttemptSweepInterval)
+	store.Incr("ip:6", time.Minute)
+	if len(store.attempts) != 2 {
+		t.Fatalf("间隔到期后应清理过期计数, got %d", len(store.attempts))
+	}
+}
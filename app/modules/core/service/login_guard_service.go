@@ -0,0 +1,219 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrAccountLocked = errors.New("账号已锁定，请联系管理员解锁")
+
+// LoginThrottledError 登录过于频繁，需要等待 RetryAfter 后重试
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("登录尝试过于频繁，请 %d 秒后重试", int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+// LoginAttemptInfo 一次登录尝试的上下文
+type LoginAttemptInfo struct {
+	UserType string
+	UserId   uint // 账号不存在时为 0
+	Name     string
+	Ip       string
+}
+
+type LoginGuardServiceInterface interface {
+	// Check 登录前检查账号是否处于退避期、IP 失败次数是否超限
+	Check(attempt LoginAttemptInfo) error
+	// IsLocked 账号是否被锁定
+	IsLocked(userType string, userId uint) bool
+	// Fail 记录一次失败，达到阈值后锁定账号，返回是否已锁定
+	Fail(attempt LoginAttemptInfo) (bool, error)
+	// Succeed 登录成功，清除账号失败计数
+	Succeed(attempt LoginAttemptInfo) error
+	// Unlock 管理员解锁账号
+	Unlock(userType string, userId uint, name string, operatorId uint) error
+}
+
+// 默认进程内计数存储，单实例部署时使用
+var memoryAttemptStore = NewMemoryAttemptStore()
+
+type LoginGuardService struct {
+	db    *gorm.DB
+	store AttemptStore
+}
+
+func NewLoginGuardService(db *gorm.DB) LoginGuardServiceInterface {
+	var store AttemptStore = memoryAttemptStore
+	if loginGuardConfig().Store == "database" {
+		store = NewDatabaseAttemptStore(db)
+	}
+	return NewLoginGuardServiceWithStore(db, store)
+}
+
+// NewLoginGuardServiceWithStore 使用自定义共享存储（如 Redis 实现）
+func NewLoginGuardServiceWithStore(db *gorm.DB, store AttemptStore) LoginGuardServiceInterface {
+	return &LoginGuardService{
+		db:    db,
+		store: store,
+	}
+}
+
+// 配置缺省值
+func loginGuardConfig() config.LoginGuardConfig {
+	guardConfig := config.LoginGuardConfig{}
+	if conf := config.GetConfig(); conf != nil {
+		guardConfig = conf.LoginGuard
+	}
+	if guardConfig.MaxFailures <= 0 {
+		guardConfig.MaxFailures = 5
+	}
+	if guardConfig.MaxIpFailures <= 0 {
+		guardConfig.MaxIpFailures = 50
+	}
+	if guardConfig.Window <= 0 {
+		guardConfig.Window = 15 * time.Minute
+	}
+	if guardConfig.BaseDelay <= 0 {
+		guardConfig.BaseDelay = time.Second
+	}
+	if guardConfig.MaxDelay <= 0 {
+		guardConfig.MaxDelay = 5 * time.Minute
+	}
+	return guardConfig
+}
+
+func accountAttemptKey(userType string, name string) string {
+	return "account:" + userType + ":" + name
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// 指数退避：第 n 次失败后需等待 base * 2^(n-1)
+func backoffDelay(failures int, guardConfig config.LoginGuardConfig) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	delay := guardConfig.BaseDelay
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if delay >= guardConfig.MaxDelay {
+			return guardConfig.MaxDelay
+		}
+	}
+	return delay
+}
+
+func (u *LoginGuardService) Check(attempt LoginAttemptInfo) error {
+	guardConfig := loginGuardConfig()
+	now := time.Now()
+
+	// IP 维度不退避，窗口内失败次数达到上限后，到窗口结束前拒绝该 IP 的全部登录
+	count, lastFailedAt, err := u.store.Get(ipAttemptKey(attempt.Ip))
+	if err != nil {
+		return fmt.Errorf("登录校验失败: %w", err)
+	}
+	if count >= guardConfig.MaxIpFailures {
+		if wait := lastFailedAt.Add(guardConfig.Window).Sub(now); wait > 0 {
+			u.audit(attempt, model.LoginEventThrottled, 0)
+			return &LoginThrottledError{RetryAfter: wait}
+		}
+	}
+
+	// 账号维度：每次失败后指数退避
+	count, lastFailedAt, err = u.store.Get(accountAttemptKey(attempt.UserType, attempt.Name))
+	if err != nil {
+		return fmt.Errorf("登录校验失败: %w", err)
+	}
+	if wait := lastFailedAt.Add(backoffDelay(count, guardConfig)).Sub(now); wait > 0 {
+		u.audit(attempt, model.LoginEventThrottled, 0)
+		return &LoginThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+func (u *LoginGuardService) IsLocked(userType string, userId uint) bool {
+	var lock model.AccountLock
+	err := u.db.Where("user_type = ?", userType).Where("user_id = ?", userId).First(&lock).Error
+	if err != nil {
+		return false
+	}
+	if lock.LockedUntil != nil && lock.LockedUntil.Before(time.Now()) {
+		// 锁定到期自动解除
+		u.db.Delete(&lock)
+		return false
+	}
+	return true
+}
+
+func (u *LoginGuardService) Fail(attempt LoginAttemptInfo) (bool, error) {
+	guardConfig := loginGuardConfig()
+	if _, err := u.store.Incr(ipAttemptKey(attempt.Ip), guardConfig.Window); err != nil {
+		return false, err
+	}
+	count, err := u.store.Incr(accountAttemptKey(attempt.UserType, attempt.Name), guardConfig.Window)
+	if err != nil {
+		return false, err
+	}
+	u.audit(attempt, model.LoginEventFailed, 0)
+
+	// 不存在的账号只计数，不锁定
+	if attempt.UserId == 0 || count < guardConfig.MaxFailures {
+		return false, nil
+	}
+	lock := model.AccountLock{
+		UserType: attempt.UserType,
+		UserId:   attempt.UserId,
+	}
+	if guardConfig.LockDuration > 0 {
+		lockedUntil := time.Now().Add(guardConfig.LockDuration)
+		lock.LockedUntil = &lockedUntil
+	}
+	err = u.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_type"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"locked_until", "updated_at"}),
+	}).Create(&lock).Error
+	if err != nil {
+		return false, fmt.Errorf("账号锁定失败: %w", err)
+	}
+	u.audit(attempt, model.LoginEventLocked, 0)
+	return true, nil
+}
+
+func (u *LoginGuardService) Succeed(attempt LoginAttemptInfo) error {
+	return u.store.Reset(accountAttemptKey(attempt.UserType, attempt.Name))
+}
+
+func (u *LoginGuardService) Unlock(userType string, userId uint, name string, operatorId uint) error {
+	err := u.db.Where("user_type = ?", userType).Where("user_id = ?", userId).Delete(&model.AccountLock{}).Error
+	if err != nil {
+		return fmt.Errorf("账号解锁失败: %w", err)
+	}
+	if err := u.store.Reset(accountAttemptKey(userType, name)); err != nil {
+		return fmt.Errorf("账号解锁失败: %w", err)
+	}
+	u.audit(LoginAttemptInfo{UserType: userType, UserId: userId, Name: name}, model.LoginEventUnlocked, operatorId)
+	return nil
+}
+
+func (u *LoginGuardService) audit(attempt LoginAttemptInfo, event string, operatorId uint) {
+	u.db.Create(&model.LoginAudit{
+		UserType:   attempt.UserType,
+		UserId:     attempt.UserId,
+		Name:       attempt.Name,
+		Ip:         attempt.Ip,
+		Event:      event,
+		OperatorId: operatorId,
+	})
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/config"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	guardConfig := config.LoginGuardConfig{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	cases := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{50, 10 * time.Second},
+	}
+	for _, c := range cases {
+		if got := backoffDelay(c.failures, guardConfig); got != c.want {
+			t.Errorf("第 %d 次失败后应等待 %s, got %s", c.failures, c.want, got)
+		}
+	}
+}
+
+// 内存数据库，仅创建用到的表
+func newTestDB(t *testing.T, models ...interface{}) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func newLoginGuardTestDB(t *testing.T) *gorm.DB {
+	return newTestDB(t, &model.AccountLock{}, &model.LoginAudit{})
+}
+
+func TestLoginGuardLocksAfterMaxFailures(t *testing.T) {
+	db := newLoginGuardTestDB(t)
+	guard := NewLoginGuardServiceWithStore(db, NewMemoryAttemptStore())
+	attempt := LoginAttemptInfo{UserType: "Admin", UserId: 1, Name: "admin", Ip: "127.0.0.1"}
+
+	// 默认 5 次失败后锁定
+	for i := 1; i < 5; i++ {
+		locked, err := guard.Fail(attempt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if locked {
+			t.Fatalf("第 %d 次失败不应锁定", i)
+		}
+	}
+	if guard.IsLocked("Admin", 1) {
+		t.Fatal("未达到阈值不应锁定")
+	}
+	locked, err := guard.Fail(attempt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !locked || !guard.IsLocked("Admin", 1) {
+		t.Fatal("达到阈值应锁定")
+	}
+
+	// 不存在的账号只计数，不锁定
+	unknown := LoginAttemptInfo{UserType: "Admin", Name: "nobody", Ip: "127.0.0.2"}
+	for i := 0; i < 6; i++ {
+		if locked, _ := guard.Fail(unknown); locked {
+			t.Fatal("不存在的账号不应锁定")
+		}
+	}
+
+	if err := guard.Unlock("Admin", 1, "admin", 1); err != nil {
+		t.Fatal(err)
+	}
+	if guard.IsLocked("Admin", 1) {
+		t.Fatal("解锁后不应锁定")
+	}
+}
+
+func TestLoginGuardLockExpires(t *testing.T) {
+	db := newLoginGuardTestDB(t)
+	guard := NewLoginGuardServiceWithStore(db, NewMemoryAttemptStore())
+	lockedUntil := time.Now().Add(-time.Minute)
+	if err := db.Create(&model.AccountLock{UserType: "Admin", UserId: 2, LockedUntil: &lockedUntil}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if guard.IsLocked("Admin", 2) {
+		t.Fatal("锁定到期应自动解除")
+	}
+}
+
+func TestLoginGuardThrottles(t *testing.T) {
+	db := newLoginGuardTestDB(t)
+	guard := NewLoginGuardServiceWithStore(db, NewMemoryAttemptStore())
+	attempt := LoginAttemptInfo{UserType: "Admin", UserId: 1, Name: "admin", Ip: "127.0.0.1"}
+	if err := guard.Check(attempt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := guard.Fail(attempt); err != nil {
+		t.Fatal(err)
+	}
+	var throttled *LoginThrottledError
+	if err := guard.Check(attempt); !errors.As(err, &throttled) || throttled.RetryAfter <= 0 {
+		t.Fatalf("失败后应进入退避期, got %v", err)
+	}
+	if err := guard.Succeed(attempt); err != nil {
+		t.Fatal(err)
+	}
+	// IP 维度不退避，账号计数清除后即可重试
+	if err := guard.Check(attempt); err != nil {
+		t.Fatalf("IP 失败次数未达上限时不应拒绝, got %v", err)
+	}
+}
+
+func TestLoginGuardIpLimit(t *testing.T) {
+	db := newLoginGuardTestDB(t)
+	guard := NewLoginGuardServiceWithStore(db, NewMemoryAttemptStore())
+	maxIpFailures := loginGuardConfig().MaxIpFailures
+	// 同一 IP 尝试不同账号，每个账号只失败一次，不触发账号退避
+	for i := 0; i < maxIpFailures; i++ {
+		attempt := LoginAttemptInfo{UserType: "Admin", Name: fmt.Sprintf("admin%d", i), Ip: "127.0.0.1"}
+		if err := guard.Check(attempt); err != nil {
+			t.Fatalf("第 %d 次尝试: IP 失败次数未达上限时不应拒绝, got %v", i+1, err)
+		}
+		if _, err := guard.Fail(attempt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var throttled *LoginThrottledError
+	attempt := LoginAttemptInfo{UserType: "Admin", Name: "other", Ip: "127.0.0.1"}
+	if err := guard.Check(attempt); !errors.As(err, &throttled) || throttled.RetryAfter <= 0 {
+		t.Fatalf("IP 失败次数达到上限后应拒绝, got %v", err)
+	}
+	attempt.Ip = "127.0.0.2"
+	if err := guard.Check(attempt); err != nil {
+		t.Fatalf("其他 IP 不受影响, got %v", err)
+	}
+}
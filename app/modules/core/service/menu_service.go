@@ -3,63 +3,166 @@ package service
 import (
 	"fmt"
 
+	"github.com/casbin/casbin/v2"
+	"github.com/maxlcoder/homework-backend/app/modules/core/admin/request"
 	"github.com/maxlcoder/homework-backend/app/modules/core/model"
 	base_model "github.com/maxlcoder/homework-backend/model"
+	"github.com/maxlcoder/homework-backend/pkg/tenant"
 	"github.com/maxlcoder/homework-backend/repository"
+	"github.com/samber/lo"
 	"gorm.io/gorm"
 )
 
 type MenuServiceInterface interface {
-	Create(menu *model.Menu) (*model.Menu, error)
+	Create(menu *model.Menu, permissionIds []uint) (*model.Menu, error)
+	// Update 更新名称、上级与排序，permissionIds 为 nil 时不修改菜单权限
+	Update(menu *model.Menu, permissionIds *[]uint) (*model.Menu, error)
+	Delete(id uint) error
 	GetById(id uint) (*model.Menu, error)
 	GetPageByFilter(modelFilter model.MenuFilter, pagination base_model.Pagination) (int64, []model.Menu, error)
-	//GetPermissionsByMenuId(id uint) ([]model.Permission, error)
+	// Tree 全部菜单树，含已停用菜单
+	Tree() ([]*model.Menu, error)
+	// Sort 批量调整上级与排序，用于拖拽排序
+	Sort(items []request.MenuSortItem) error
+	// SetDisabled 停用或启用菜单及其子菜单，停用后菜单隐藏且各角色不再拥有其权限
+	SetDisabled(id uint, disabled bool) error
 	GetPermissionsByMenuIds(ids []uint) ([]model.Permission, error)
-	//GetMenusByRoleId(roleId uint) ([]model.Menu, error)
 }
 
 type MenuService struct {
-	db *gorm.DB
+	db       *gorm.DB
+	enforcer *casbin.SyncedEnforcer
 }
 
-func NewMenuService(db *gorm.DB) MenuServiceInterface {
+func NewMenuService(db *gorm.DB, enforcer *casbin.SyncedEnforcer) MenuServiceInterface {
+	// 菜单管理为平台功能，菜单变更需同步各租户角色的授权，按系统任务处理
 	return &MenuService{
-		db: db,
+		db:       db.WithContext(tenant.System(db.Statement.Context)),
+		enforcer: enforcer,
 	}
 }
 
-func (u *MenuService) Create(menu *model.Menu) (*model.Menu, error) {
-	// 判断是否存在已经适用的名称
-	filter := model.MenuFilter{
-		Name: &menu.Name,
+func (u *MenuService) Create(menu *model.Menu, permissionIds []uint) (*model.Menu, error) {
+	// 编号唯一
+	var count int64
+	if err := u.db.Model(&model.Menu{}).Where("number = ?", menu.Number).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("菜单查询失败: %w", err)
 	}
-	cond := repository.ConditionScope{
-		StructCond: filter,
+	if count > 0 {
+		return nil, fmt.Errorf("菜单编号已存在，请检查")
 	}
-	findUser, _ := repository.NewBaseRepository[model.Menu](u.db).FindBy(cond)
-	if findUser != nil {
-		return nil, fmt.Errorf("当前用户名不可用，请检查")
+	if err := u.checkParent(0, menu.ParentID); err != nil {
+		return nil, err
 	}
-	err := repository.NewBaseRepository[model.Menu](u.db).Create(menu, nil)
+	if err := u.checkPermissions(permissionIds); err != nil {
+		return nil, err
+	}
+
+	// 手动创建的菜单不受初始化同步影响
+	menu.Source = model.MenuSourceCustom
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewBaseRepository[model.Menu](u.db).Create(menu, tx); err != nil {
+			return fmt.Errorf("菜单创建失败: %w", err)
+		}
+		// 超管角色拥有全部菜单
+		if err := tx.Create(&model.RoleMenu{RoleID: superRoleId, MenuID: menu.ID}).Error; err != nil {
+			return fmt.Errorf("角色菜单创建失败: %w", err)
+		}
+		return setMenuPermissions(tx, menu.ID, permissionIds)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("用户创建失败: %w", err)
+		return nil, err
 	}
 	return menu, nil
 }
 
-func (u *MenuService) Update() {
-	//TODO implement me
-	panic("implement me")
-}
+func (u *MenuService) Update(menu *model.Menu, permissionIds *[]uint) (*model.Menu, error) {
+	find, err := u.GetById(menu.ID)
+	if err != nil {
+		return nil, err
+	}
+	// 代码定义菜单的权限以代码为准
+	if permissionIds != nil && find.Source == model.MenuSourceCode {
+		return nil, fmt.Errorf("代码定义的菜单权限不能修改")
+	}
+	if err := u.checkParent(menu.ID, menu.ParentID); err != nil {
+		return nil, err
+	}
+	if permissionIds != nil {
+		if err := u.checkPermissions(*permissionIds); err != nil {
+			return nil, err
+		}
+	}
 
-func (u *MenuService) Delete() {
-	//TODO implement me
-	panic("implement me")
+	// 代码定义的菜单手动调整后，初始化时不再覆盖
+	menu.Customized = find.Source == model.MenuSourceCode
+	changes := &policyChanges{}
+	err = u.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Menu{}).Where("id = ?", menu.ID).
+			Select("name", "parent_id", "sort", "customized").
+			Updates(menu).Error
+		if err != nil {
+			return fmt.Errorf("菜单更新失败: %w", err)
+		}
+		if permissionIds == nil {
+			return nil
+		}
+		if err := setMenuPermissions(tx, menu.ID, *permissionIds); err != nil {
+			return err
+		}
+		// 拥有该菜单的角色按新的菜单权限重新授权
+		return syncRoles(tx, changes, []uint{menu.ID})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := changes.apply(u.enforcer); err != nil {
+		return nil, err
+	}
+	return u.GetById(menu.ID)
 }
 
-func (u *MenuService) List() {
-	//TODO implement me
-	panic("implement me")
+func (u *MenuService) Delete(id uint) error {
+	menu, err := u.GetById(id)
+	if err != nil {
+		return err
+	}
+	// 代码定义的菜单初始化时会重新创建，只能停用
+	if menu.Source == model.MenuSourceCode {
+		return fmt.Errorf("代码定义的菜单不能删除，可停用")
+	}
+	var count int64
+	if err := u.db.Model(&model.Menu{}).Where("parent_id = ?", id).Count(&count).Error; err != nil {
+		return fmt.Errorf("菜单查询失败: %w", err)
+	}
+	if count > 0 {
+		return fmt.Errorf("存在子菜单，无法删除")
+	}
+
+	changes := &policyChanges{}
+	err = u.db.Transaction(func(tx *gorm.DB) error {
+		var roleIds []uint
+		if err := tx.Model(&model.RoleMenu{}).Where("menu_id = ?", id).Pluck("role_id", &roleIds).Error; err != nil {
+			return fmt.Errorf("角色菜单查询失败: %w", err)
+		}
+		if err := tx.Where("menu_id = ?", id).Delete(&model.RoleMenu{}).Error; err != nil {
+			return fmt.Errorf("角色菜单处理失败: %w", err)
+		}
+		if err := tx.Where("menu_id = ?", id).Delete(&model.MenuPermission{}).Error; err != nil {
+			return fmt.Errorf("菜单权限处理失败: %w", err)
+		}
+		if err := tx.Where("menu_id = ?", id).Delete(&model.MenuField{}).Error; err != nil {
+			return fmt.Errorf("菜单字段权限处理失败: %w", err)
+		}
+		if err := repository.NewBaseRepository[model.Menu](u.db).DeleteById(id, tx); err != nil {
+			return fmt.Errorf("菜单删除失败: %w", err)
+		}
+		return syncRoleIds(tx, changes, roleIds)
+	})
+	if err != nil {
+		return err
+	}
+	return changes.apply(u.enforcer)
 }
 
 func (u *MenuService) GetById(id uint) (*model.Menu, error) {
@@ -69,36 +172,281 @@ func (u *MenuService) GetById(id uint) (*model.Menu, error) {
 	cond := repository.ConditionScope{
 		StructCond: filter,
 	}
-	user, err := repository.NewBaseRepository[model.Menu](u.db).FindBy(cond)
+	menu, err := repository.NewBaseRepository[model.Menu](u.db).FindBy(cond)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("菜单不存在")
 	}
-	return user, nil
-}
-
-func (u *MenuService) GetByObject() {
-	//TODO implement me
-	panic("implement me")
+	err = u.db.Where("id IN (?)", u.db.Model(&model.MenuPermission{}).Select("permission_id").Where("menu_id = ?", id)).
+		Find(&menu.Permissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("菜单权限查询失败: %w", err)
+	}
+	return menu, nil
 }
 
 func (u *MenuService) GetPageByFilter(modelFilter model.MenuFilter, pagination base_model.Pagination) (int64, []model.Menu, error) {
 
 	cond := repository.ConditionScope{
-		StructCond: modelFilter,
+		Scopes: []func(*gorm.DB) *gorm.DB{
+			func(db *gorm.DB) *gorm.DB {
+				if modelFilter.Name != nil {
+					return repository.LikeScope("name", *modelFilter.Name)(db)
+				}
+				return db
+			},
+		},
+		Order: []string{"sort", "id"},
 	}
 
 	total, menus, err := repository.NewBaseRepository[model.Menu](u.db).Page(cond, pagination)
 	if err != nil {
-		return 0, nil, fmt.Errorf("用户分页查询失败: %w", err)
+		return 0, nil, fmt.Errorf("菜单分页查询失败: %w", err)
 	}
 	return total, menus, nil
 }
 
+func (u *MenuService) Tree() ([]*model.Menu, error) {
+	var menus []model.Menu
+	if err := u.db.Order("sort").Order("id").Find(&menus).Error; err != nil {
+		return nil, fmt.Errorf("菜单查询失败: %w", err)
+	}
+	tree := buildMenuTree(lo.ToSlicePtr(menus), 0)
+	if tree == nil {
+		tree = make([]*model.Menu, 0)
+	}
+	return tree, nil
+}
+
+func (u *MenuService) Sort(items []request.MenuSortItem) error {
+	var menus []model.Menu
+	if err := u.db.Select("id", "parent_id", "source").Find(&menus).Error; err != nil {
+		return fmt.Errorf("菜单查询失败: %w", err)
+	}
+	parents := lo.SliceToMap(menus, func(item model.Menu) (uint, uint) {
+		return item.ID, item.ParentID
+	})
+	sources := lo.SliceToMap(menus, func(item model.Menu) (uint, string) {
+		return item.ID, item.Source
+	})
+	// 先应用全部调整，再整体校验，允许同一批次内互相移动
+	for _, item := range items {
+		if _, ok := parents[item.ID]; !ok {
+			return fmt.Errorf("菜单 %d 不存在", item.ID)
+		}
+		if _, ok := parents[item.ParentID]; item.ParentID != 0 && !ok {
+			return fmt.Errorf("上级菜单 %d 不存在", item.ParentID)
+		}
+		parents[item.ID] = item.ParentID
+	}
+	for _, item := range items {
+		if hasParentCycle(parents, item.ID) {
+			return fmt.Errorf("菜单 %d 不能移动到自身或子菜单下", item.ID)
+		}
+	}
+
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range items {
+			err := tx.Model(&model.Menu{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+				"parent_id":  item.ParentID,
+				"sort":       item.Sort,
+				"customized": sources[item.ID] == model.MenuSourceCode,
+			}).Error
+			if err != nil {
+				return fmt.Errorf("菜单排序失败: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (u *MenuService) SetDisabled(id uint, disabled bool) error {
+	if _, err := u.GetById(id); err != nil {
+		return err
+	}
+	var menus []model.Menu
+	if err := u.db.Select("id", "parent_id").Find(&menus).Error; err != nil {
+		return fmt.Errorf("菜单查询失败: %w", err)
+	}
+	// 子菜单随上级一起停用或启用
+	menuIds := []uint{id}
+	for i := 0; i < len(menuIds); i++ {
+		for _, menu := range menus {
+			if menu.ParentID == menuIds[i] {
+				menuIds = append(menuIds, menu.ID)
+			}
+		}
+	}
+
+	changes := &policyChanges{}
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Menu{}).Where("id IN ?", menuIds).Update("is_disabled", disabled).Error; err != nil {
+			return fmt.Errorf("菜单状态更新失败: %w", err)
+		}
+		return syncRoles(tx, changes, menuIds)
+	})
+	if err != nil {
+		return err
+	}
+	return changes.apply(u.enforcer)
+}
+
 func (u *MenuService) GetPermissionsByMenuIds(ids []uint) ([]model.Permission, error) {
-	subQuery := u.db.Model(&model.MenuPermission{}).
-		Select("permission_id").
-		Where("menu_id IN (?)", ids)
+	return menuPermissions(u.db, ids)
+}
+
+// 上级菜单需存在，且不能是自身或子菜单
+func (u *MenuService) checkParent(id uint, parentId uint) error {
+	if parentId == 0 {
+		return nil
+	}
+	var menus []model.Menu
+	if err := u.db.Select("id", "parent_id").Find(&menus).Error; err != nil {
+		return fmt.Errorf("菜单查询失败: %w", err)
+	}
+	parents := lo.SliceToMap(menus, func(item model.Menu) (uint, uint) {
+		return item.ID, item.ParentID
+	})
+	if _, ok := parents[parentId]; !ok {
+		return fmt.Errorf("上级菜单不存在")
+	}
+	if id == 0 {
+		return nil
+	}
+	parents[id] = parentId
+	if hasParentCycle(parents, id) {
+		return fmt.Errorf("不能移动到自身或子菜单下")
+	}
+	return nil
+}
+
+func (u *MenuService) checkPermissions(permissionIds []uint) error {
+	permissionIds = lo.Uniq(permissionIds)
+	if len(permissionIds) == 0 {
+		return nil
+	}
+	var count int64
+	if err := u.db.Model(&model.Permission{}).Where("id IN ?", permissionIds).Count(&count).Error; err != nil {
+		return fmt.Errorf("权限查询失败: %w", err)
+	}
+	if count != int64(len(permissionIds)) {
+		return fmt.Errorf("权限参数校验失败，请检查")
+	}
+	return nil
+}
+
+// 拥有这些菜单的角色按当前可用菜单重新授权
+func syncRoles(tx *gorm.DB, changes *policyChanges, menuIds []uint) error {
+	var roleIds []uint
+	if err := tx.Model(&model.RoleMenu{}).Where("menu_id IN ?", menuIds).Distinct().Pluck("role_id", &roleIds).Error; err != nil {
+		return fmt.Errorf("角色菜单查询失败: %w", err)
+	}
+	return syncRoleIds(tx, changes, roleIds)
+}
+
+// 超管角色始终拥有全部权限，不参与同步
+func syncRoleIds(tx *gorm.DB, changes *policyChanges, roleIds []uint) error {
+	roleIds = lo.Without(lo.Uniq(roleIds), superRoleId)
+	if len(roleIds) == 0 {
+		return nil
+	}
+	var roles []model.Role
+	if err := tx.Where("id IN ?", roleIds).Find(&roles).Error; err != nil {
+		return fmt.Errorf("角色查询失败: %w", err)
+	}
+	for i := range roles {
+		var menuIds []uint
+		if err := tx.Model(&model.RoleMenu{}).Where("role_id = ?", roles[i].ID).Pluck("menu_id", &menuIds).Error; err != nil {
+			return fmt.Errorf("角色菜单查询失败: %w", err)
+		}
+		if err := grantRolePermissions(tx, changes, &roles[i], menuIds); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 从 id 沿上级查找，回到自身即存在循环，菜单与角色共用
+func hasParentCycle(parents map[uint]uint, id uint) bool {
+	current := parents[id]
+	for i := 0; i < len(parents) && current != 0; i++ {
+		if current == id {
+			return true
+		}
+		current = parents[current]
+	}
+	return current != 0
+}
+
+// 重置菜单关联的权限
+func setMenuPermissions(tx *gorm.DB, menuId uint, permissionIds []uint) error {
+	if err := tx.Where("menu_id = ?", menuId).Delete(&model.MenuPermission{}).Error; err != nil {
+		return fmt.Errorf("菜单权限处理失败: %w", err)
+	}
+	permissionIds = lo.Uniq(permissionIds)
+	if len(permissionIds) == 0 {
+		return nil
+	}
+	menuPermissions := lo.Map(permissionIds, func(item uint, index int) model.MenuPermission {
+		return model.MenuPermission{MenuID: menuId, PermissionID: item}
+	})
+	if err := tx.Create(&menuPermissions).Error; err != nil {
+		return fmt.Errorf("菜单权限创建失败: %w", err)
+	}
+	return nil
+}
+
+// 菜单关联的权限，已停用的菜单不再授予权限
+func menuPermissions(db *gorm.DB, menuIds []uint) ([]model.Permission, error) {
 	var permissions []model.Permission
-	u.db.Where("id IN (?)", subQuery).Find(&permissions)
+	if len(menuIds) == 0 {
+		return permissions, nil
+	}
+	enabledMenus := db.Model(&model.Menu{}).Select("id").Where("id IN ?", menuIds).Where("is_disabled = ?", false)
+	err := db.Where("id IN (?)", db.Model(&model.MenuPermission{}).Select("permission_id").Where("menu_id IN (?)", enabledMenus)).
+		Find(&permissions).Error
+	if err != nil {
+		return nil, fmt.Errorf("权限查询失败: %w", err)
+	}
 	return permissions, nil
 }
+
+// 按菜单重置角色的菜单、权限，casbin 授权记录到 changes，事务提交后写入
+func grantRoleMenus(tx *gorm.DB, changes *policyChanges, role *model.Role, menuIds []uint) error {
+	if err := tx.Where("role_id = ?", role.ID).Delete(&model.RoleMenu{}).Error; err != nil {
+		return fmt.Errorf("角色菜单处理失败: %w", err)
+	}
+	if len(menuIds) > 0 {
+		roleMenus := lo.Map(menuIds, func(item uint, index int) model.RoleMenu {
+			return model.RoleMenu{RoleID: role.ID, MenuID: item}
+		})
+		if err := tx.Create(&roleMenus).Error; err != nil {
+			return fmt.Errorf("角色菜单创建失败: %w", err)
+		}
+	}
+	return grantRolePermissions(tx, changes, role, menuIds)
+}
+
+// 按菜单重置角色的权限，角色菜单不变，casbin 授权记录到 changes，事务提交后写入
+func grantRolePermissions(tx *gorm.DB, changes *policyChanges, role *model.Role, menuIds []uint) error {
+	if err := tx.Where("role_id = ?", role.ID).Delete(&model.RolePermission{}).Error; err != nil {
+		return fmt.Errorf("角色权限处理失败: %w", err)
+	}
+	permissions, err := menuPermissions(tx, menuIds)
+	if err != nil {
+		return err
+	}
+	if len(permissions) > 0 {
+		rolePermissions := lo.Map(permissions, func(item model.Permission, index int) model.RolePermission {
+			return model.RolePermission{RoleID: role.ID, PermissionID: item.ID}
+		})
+		if err := tx.Create(&rolePermissions).Error; err != nil {
+			return fmt.Errorf("角色权限创建失败: %w", err)
+		}
+	}
+
+	domain := tenantDomain(role.TenantID)
+	changes.resetPermissions(role.ID, domain, lo.Map(permissions, func(item model.Permission, index int) []string {
+		return []string{domain, item.PATH, item.Method}
+	}))
+	return nil
+}
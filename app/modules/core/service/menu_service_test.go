@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"gorm.io/gorm"
+)
+
+// 菜单 1 及其子菜单 2 各关联一个权限，租户 2 的角色 2 拥有这两个菜单
+func seedMenuData(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := newTestDB(t, &model.Role{}, &model.Menu{}, &model.Permission{}, &model.MenuPermission{}, &model.RoleMenu{}, &model.RolePermission{})
+	role := model.Role{Name: "staff"}
+	role.ID, role.TenantID = 2, 2
+	menus := []model.Menu{{Name: "parent", Number: "parent"}, {Name: "child", Number: "child", ParentID: 1}}
+	menus[0].ID, menus[1].ID = 1, 2
+	permissions := []model.Permission{{PATH: "/admin/parent", Method: "GET"}, {PATH: "/admin/child", Method: "GET"}}
+	permissions[0].ID, permissions[1].ID = 1, 2
+	records := []interface{}{
+		&role,
+		&menus,
+		&permissions,
+		&[]model.MenuPermission{{MenuID: 1, PermissionID: 1}, {MenuID: 2, PermissionID: 2}},
+		&[]model.RoleMenu{{RoleID: 2, MenuID: 1}, {RoleID: 2, MenuID: 2}},
+		&[]model.RolePermission{{RoleID: 2, PermissionID: 1}, {RoleID: 2, PermissionID: 2}},
+	}
+	for _, record := range records {
+		if err := db.Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestMenuSetDisabledSyncsCasbin(t *testing.T) {
+	db := seedMenuData(t)
+	enforcer := newTestEnforcer(t)
+	enforcer.AddPolicies([][]string{
+		{"role_2", "2", "/admin/parent", "GET"},
+		{"role_2", "2", "/admin/child", "GET"},
+	})
+	service := NewMenuService(db, enforcer)
+
+	// 停用上级菜单，子菜单一并停用，角色不再拥有其权限
+	if err := service.SetDisabled(1, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/admin/parent", "/admin/child"} {
+		if ok, _ := enforcer.Enforce("role_2", "2", path, "GET"); ok {
+			t.Errorf("%s: 菜单停用后应收回 casbin 授权", path)
+		}
+	}
+	var count int64
+	db.Model(&model.RolePermission{}).Where("role_id = ?", 2).Count(&count)
+	if count != 0 {
+		t.Errorf("菜单停用后应删除角色权限, got %d", count)
+	}
+	db.Model(&model.RoleMenu{}).Where("role_id = ?", 2).Count(&count)
+	if count != 2 {
+		t.Errorf("停用菜单不应删除角色菜单, got %d", count)
+	}
+
+	// 重新启用后恢复授权
+	if err := service.SetDisabled(1, false); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/admin/parent", "/admin/child"} {
+		if ok, _ := enforcer.Enforce("role_2", "2", path, "GET"); !ok {
+			t.Errorf("%s: 菜单启用后应恢复 casbin 授权", path)
+		}
+	}
+}
+
+func TestMenuSetDisabledRollbackKeepsCasbin(t *testing.T) {
+	db := seedMenuData(t)
+	other := model.Role{Name: "other"}
+	other.ID, other.TenantID = 3, 2
+	if err := db.Create(&other).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&model.RoleMenu{RoleID: 3, MenuID: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	enforcer := newTestEnforcer(t)
+	enforcer.AddPolicies([][]string{
+		{"role_2", "2", "/admin/parent", "GET"},
+		{"role_3", "2", "/admin/parent", "GET"},
+	})
+	service := NewMenuService(db, enforcer)
+
+	// 角色 2 同步完成后，角色 3 的权限写入失败，事务整体回滚
+	calls := 0
+	err := db.Callback().Delete().Before("gorm:delete").Register("test:fail_role_permissions", func(tx *gorm.DB) {
+		if tx.Statement.Table == "role_permissions" {
+			if calls++; calls == 2 {
+				tx.AddError(errors.New("写入失败"))
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := service.SetDisabled(1, true); err == nil {
+		t.Fatal("同步角色权限失败时应报错")
+	}
+	if menu, _ := service.GetById(1); menu == nil || menu.IsDisabled {
+		t.Fatal("失败时数据库应回滚")
+	}
+	for _, subject := range []string{"role_2", "role_3"} {
+		if ok, _ := enforcer.Enforce(subject, "2", "/admin/parent", "GET"); !ok {
+			t.Errorf("%s: 失败时不应修改 casbin 规则", subject)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/config"
+	"github.com/maxlcoder/homework-backend/kafka"
+	base_model "github.com/maxlcoder/homework-backend/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrPasswordMismatch   = errors.New("原密码错误")
+	ErrPasswordUnchanged  = errors.New("新密码不能与原密码相同")
+	ErrInvalidResetToken  = errors.New("重置令牌无效或已过期")
+	ErrUnknownAccountType = errors.New("未知的账号类型")
+)
+
+// bcrypt 只处理前 72 字节
+const passwordMaxLength = 72
+
+// PasswordResetMessage 重置令牌投递消息，由通知服务发送给用户
+type PasswordResetMessage struct {
+	UserType  string    `json:"user_type"`
+	UserId    uint      `json:"user_id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type PasswordServiceInterface interface {
+	// WithContext 绑定请求 context，重置令牌投递时携带请求 ID
+	WithContext(ctx context.Context) PasswordServiceInterface
+	// Change 校验原密码后修改密码
+	Change(userType string, userId uint, oldPassword string, newPassword string) error
+	// Forgot 生成重置令牌并投递，账号不存在时静默返回，避免账号枚举
+	Forgot(userType string, account string) error
+	// Reset 使用重置令牌设置新密码
+	Reset(userType string, token string, newPassword string) error
+}
+
+type PasswordService struct {
+	db *gorm.DB
+}
+
+func NewPasswordService(db *gorm.DB) PasswordServiceInterface {
+	return &PasswordService{
+		db: db,
+	}
+}
+
+func (u *PasswordService) WithContext(ctx context.Context) PasswordServiceInterface {
+	return &PasswordService{
+		db: u.db.WithContext(ctx),
+	}
+}
+
+// 配置缺省值
+func passwordConfig() config.PasswordConfig {
+	passwordConfig := config.PasswordConfig{}
+	if conf := config.GetConfig(); conf != nil {
+		passwordConfig = conf.Password
+	}
+	if passwordConfig.MinLength <= 0 {
+		passwordConfig.MinLength = 8
+	}
+	if passwordConfig.ResetTimeout <= 0 {
+		passwordConfig.ResetTimeout = 30 * time.Minute
+	}
+	return passwordConfig
+}
+
+// ValidatePassword 按密码策略校验强度
+func ValidatePassword(password string) error {
+	policy := passwordConfig()
+	if len(password) < policy.MinLength {
+		return fmt.Errorf("密码长度不能少于 %d 位", policy.MinLength)
+	}
+	if len(password) > passwordMaxLength {
+		return fmt.Errorf("密码长度不能超过 %d 位", passwordMaxLength)
+	}
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			hasSymbol = true
+		}
+	}
+	var missing []string
+	if policy.RequireUpper && !hasUpper {
+		missing = append(missing, "大写字母")
+	}
+	if policy.RequireLower && !hasLower {
+		missing = append(missing, "小写字母")
+	}
+	if policy.RequireDigit && !hasDigit {
+		missing = append(missing, "数字")
+	}
+	if policy.RequireSymbol && !hasSymbol {
+		missing = append(missing, "特殊字符")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("密码必须包含%s", strings.Join(missing, "、"))
+	}
+	return nil
+}
+
+// PasswordChangeRequired 是否需要修改密码：被要求修改（如初始密码）或超过有效期
+func PasswordChangeRequired(mustChange bool, changedAt *time.Time) bool {
+	if mustChange {
+		return true
+	}
+	maxAge := passwordConfig().MaxAge
+	return maxAge > 0 && changedAt != nil && changedAt.Add(maxAge).Before(time.Now())
+}
+
+// 密码所属账号
+type passwordAccount struct {
+	ID       uint
+	Name     string
+	Email    string
+	Password string
+}
+
+func accountModel(userType string) (interface{}, error) {
+	switch userType {
+	case "Admin":
+		return &model.Admin{}, nil
+	case "User":
+		return &model.User{}, nil
+	}
+	return nil, ErrUnknownAccountType
+}
+
+func (u *PasswordService) findAccount(tx *gorm.DB, userType string, query string, args ...interface{}) (*passwordAccount, error) {
+	accountModel, err := accountModel(userType)
+	if err != nil {
+		return nil, err
+	}
+	var account passwordAccount
+	err = tx.Model(accountModel).Where(query, args...).Take(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// 设置新密码：校验策略与历史密码，记录历史，吊销已有刷新令牌
+func (u *PasswordService) setPassword(tx *gorm.DB, userType string, account *passwordAccount, newPassword string) error {
+	if err := ValidatePassword(newPassword); err != nil {
+		return err
+	}
+	if base_model.CheckPasswordHash(newPassword, account.Password) {
+		return ErrPasswordUnchanged
+	}
+	history := passwordConfig().History
+	if history > 0 {
+		var hashes []string
+		err := tx.Model(&model.PasswordHistory{}).
+			Where("user_type = ?", userType).
+			Where("user_id = ?", account.ID).
+			Order("id DESC").
+			Limit(history).
+			Pluck("password_hash", &hashes).Error
+		if err != nil {
+			return err
+		}
+		for _, hash := range hashes {
+			if base_model.CheckPasswordHash(newPassword, hash) {
+				return fmt.Errorf("不能使用最近 %d 次使用过的密码", history)
+			}
+		}
+	}
+
+	hash, err := base_model.HashPassword(newPassword)
+	if err != nil {
+		return fmt.Errorf("密码处理失败: %w", err)
+	}
+	accountModel, _ := accountModel(userType)
+	now := time.Now()
+	err = tx.Model(accountModel).Where("id = ?", account.ID).Updates(map[string]interface{}{
+		"password":             hash,
+		"password_changed_at":  now,
+		"must_change_password": false,
+	}).Error
+	if err != nil {
+		return err
+	}
+
+	if history > 0 {
+		// 记录旧密码，只保留最近 N 条
+		if account.Password != "" {
+			err = tx.Create(&model.PasswordHistory{
+				UserType:     userType,
+				UserId:       account.ID,
+				PasswordHash: account.Password,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		var keepIds []uint
+		tx.Model(&model.PasswordHistory{}).
+			Where("user_type = ?", userType).
+			Where("user_id = ?", account.ID).
+			Order("id DESC").
+			Limit(history).
+			Pluck("id", &keepIds)
+		if len(keepIds) > 0 {
+			tx.Where("user_type = ?", userType).
+				Where("user_id = ?", account.ID).
+				Where("id NOT IN ?", keepIds).
+				Delete(&model.PasswordHistory{})
+		}
+	}
+
+	// 修改密码后其他会话需重新登录
+	return tx.Model(&model.RefreshToken{}).
+		Where("user_type = ?", userType).
+		Where("user_id = ?", account.ID).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", now).Error
+}
+
+func (u *PasswordService) Change(userType string, userId uint, oldPassword string, newPassword string) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		account, err := u.findAccount(tx, userType, "id = ?", userId)
+		if err != nil {
+			return fmt.Errorf("账号查询失败: %w", err)
+		}
+		if !base_model.CheckPasswordHash(oldPassword, account.Password) {
+			return ErrPasswordMismatch
+		}
+		return u.setPassword(tx, userType, account, newPassword)
+	})
+}
+
+func (u *PasswordService) Forgot(userType string, account string) error {
+	found, err := u.findAccount(u.db, userType, "name = ? OR email = ?", account, account)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	expiresAt := time.Now().Add(passwordConfig().ResetTimeout)
+
+	err = u.db.Transaction(func(tx *gorm.DB) error {
+		// 同一账号只保留最新的重置令牌
+		err := tx.Where("user_type = ?", userType).
+			Where("user_id = ?", found.ID).
+			Where("used_at IS NULL").
+			Delete(&model.PasswordResetToken{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&model.PasswordResetToken{
+			UserType:  userType,
+			UserId:    found.ID,
+			TokenHash: hashResetToken(token),
+			ExpiresAt: expiresAt,
+		}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("重置令牌生成失败: %w", err)
+	}
+
+	message, err := json.Marshal(PasswordResetMessage{
+		UserType:  userType,
+		UserId:    found.ID,
+		Name:      found.Name,
+		Email:     found.Email,
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	topic := passwordConfig().ResetTopic
+	if topic == "" {
+		return errors.New("未配置重置令牌投递 topic")
+	}
+	if err := kafka.SendSync(u.db.Statement.Context, topic, message); err != nil {
+		return fmt.Errorf("重置令牌投递失败: %w", err)
+	}
+	return nil
+}
+
+func (u *PasswordService) Reset(userType string, token string, newPassword string) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		var resetToken model.PasswordResetToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", hashResetToken(token)).
+			Where("user_type = ?", userType).
+			First(&resetToken).Error
+		if err != nil {
+			return ErrInvalidResetToken
+		}
+		if resetToken.UsedAt != nil || resetToken.ExpiresAt.Before(time.Now()) {
+			return ErrInvalidResetToken
+		}
+		account, err := u.findAccount(tx, userType, "id = ?", resetToken.UserId)
+		if err != nil {
+			return ErrInvalidResetToken
+		}
+		if err := u.setPassword(tx, userType, account, newPassword); err != nil {
+			return err
+		}
+		return tx.Model(&resetToken).Update("used_at", time.Now()).Error
+	})
+}
+
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,11 +1,14 @@
 package service
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/maxlcoder/homework-backend/app/modules/core/model"
 	base_model "github.com/maxlcoder/homework-backend/model"
+	"github.com/maxlcoder/homework-backend/pkg/datascope"
+	"github.com/maxlcoder/homework-backend/pkg/tenant"
 	"gorm.io/gorm/clause"
 
 	"github.com/maxlcoder/homework-backend/repository"
@@ -14,21 +17,32 @@ import (
 )
 
 type RoleServiceInterface interface {
+	// WithContext 绑定请求 context，角色按 context 中的租户隔离
+	WithContext(ctx context.Context) RoleServiceInterface
 	Create(role *model.Role) (*model.Role, error)
 	CreateWithMenus(role *model.Role, menus []model.Menu) (*model.Role, error)
 	UpdateWithMenus(role *model.Role, menus []model.Menu) (*model.Role, error)
 	GetById(id uint) (*model.Role, error)
 	GetPageByFilter(modelFilter model.RoleFilter, pagination base_model.Pagination) (int64, []model.Role, error)
 	Delete(role *model.Role) error
+	// AttachChildren 设置下级角色，下级角色继承当前角色的全部权限
+	AttachChildren(id uint, childIds []uint) error
+	// DetachChild 解除下级角色与当前角色的继承关系
+	DetachChild(id uint, childId uint) error
+	// Tree 角色继承树，按 context 中的租户隔离
+	Tree() ([]*model.Role, error)
 }
 
+// 角色继承最多层数，casbin 默认最多解析 10 层 g 规则（含管理员与角色的关联）
+const maxRoleDepth = 5
+
 type RoleService struct {
 	db          *gorm.DB
-	enforcer    *casbin.Enforcer
+	enforcer    *casbin.SyncedEnforcer
 	menuService MenuServiceInterface
 }
 
-func NewRoleService(db *gorm.DB, enforcer *casbin.Enforcer, menuService MenuServiceInterface) RoleServiceInterface {
+func NewRoleService(db *gorm.DB, enforcer *casbin.SyncedEnforcer, menuService MenuServiceInterface) RoleServiceInterface {
 	return &RoleService{
 		db:          db,
 		enforcer:    enforcer,
@@ -36,6 +50,14 @@ func NewRoleService(db *gorm.DB, enforcer *casbin.Enforcer, menuService MenuServ
 	}
 }
 
+func (u *RoleService) WithContext(ctx context.Context) RoleServiceInterface {
+	return &RoleService{
+		db:          u.db.WithContext(ctx),
+		enforcer:    u.enforcer,
+		menuService: u.menuService,
+	}
+}
+
 func (u *RoleService) Create(role *model.Role) (*model.Role, error) {
 	// 判断是否存在已经适用的名称
 	filter := model.RoleFilter{
@@ -90,13 +112,25 @@ func (u *RoleService) CreateWithMenus(role *model.Role, menus []model.Menu) (*mo
 	if menuCount != int64(len(menus)) {
 		return nil, fmt.Errorf("菜单参数校验失败，请检查")
 	}
+	// 租户内只能使用租户已授权的菜单
+	if err := u.checkMenusEntitled(menus); err != nil {
+		return nil, err
+	}
 
-	// 启动事务
+	// 上级角色在角色创建、确定租户后设置
+	parentId := role.ParentID
+	role.ParentID = 0
+
+	// 启动事务，casbin 授权在事务提交后写入
+	changes := &policyChanges{}
 	err = u.db.Transaction(func(tx *gorm.DB) error {
 		err := repository.NewBaseRepository[model.Role](u.db).Create(role, tx)
 		if err != nil {
 			return fmt.Errorf("角色创建失败: %w", err)
 		}
+		if err := setRoleParent(tx, changes, role, parentId); err != nil {
+			return err
+		}
 		// 菜单处理
 		var roleMenus []model.RoleMenu
 		roleMenus = lo.Map(menus, func(item model.Menu, index int) model.RoleMenu {
@@ -106,16 +140,21 @@ func (u *RoleService) CreateWithMenus(role *model.Role, menus []model.Menu) (*mo
 			}
 		})
 		if len(roleMenus) > 0 {
-			u.db.Clauses(clause.OnConflict{
+			if err := tx.Clauses(clause.OnConflict{
 				DoNothing: true,
-			}).Create(&roleMenus)
+			}).Create(&roleMenus).Error; err != nil {
+				return fmt.Errorf("角色菜单创建失败: %w", err)
+			}
 		}
 
 		// 角色授权
 		// 菜单关联的权限
-		permissions, _ := u.GetPermissionsByMenuIds(lo.Map(menus, func(item model.Menu, index int) uint {
+		permissions, err := menuPermissions(tx, lo.Map(menus, func(item model.Menu, index int) uint {
 			return item.ID
 		}))
+		if err != nil {
+			return err
+		}
 		// 1. role_permission 表增加记录
 		rolePermissions := lo.Map(permissions, func(item model.Permission, index int) model.RolePermission {
 			return model.RolePermission{
@@ -123,35 +162,42 @@ func (u *RoleService) CreateWithMenus(role *model.Role, menus []model.Menu) (*mo
 				PermissionID: item.ID,
 			}
 		})
-		u.db.Clauses(clause.OnConflict{
-			DoNothing: true,
-		}).Create(rolePermissions)
-		// 2. casbin 授权
-		casbinPermission := lo.Map(permissions, func(item model.Permission, index int) []string {
-			return []string{"1", item.PATH, item.Method}
-		})
-		if len(casbinPermission) > 0 {
-			// 补充 casbin 中 p 规则，给角色赋权
-			_, err := u.enforcer.AddPermissionsForUser("role_"+role.Name, casbinPermission...)
-			if err != nil {
-				return fmt.Errorf("角色Casbin授权失败: %w", err)
+		if len(rolePermissions) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				DoNothing: true,
+			}).Create(&rolePermissions).Error; err != nil {
+				return fmt.Errorf("角色权限创建失败: %w", err)
 			}
 		}
+		// 2. casbin 授权，域为角色所属租户
+		domain := tenantDomain(role.TenantID)
+		changes.resetPermissions(role.ID, domain, lo.Map(permissions, func(item model.Permission, index int) []string {
+			return []string{domain, item.PATH, item.Method}
+		}))
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
+	if err := changes.apply(u.enforcer); err != nil {
+		return nil, err
+	}
 	return role, nil
 }
 
+// 当前租户取自请求 context，未绑定租户或平台租户不受限制
+func (u *RoleService) checkMenusEntitled(menus []model.Menu) error {
+	tenantId, ok := tenant.FromContext(u.db.Statement.Context)
+	if !ok {
+		return nil
+	}
+	return CheckMenusEntitled(u.db, tenantId, lo.Map(menus, func(item model.Menu, index int) uint {
+		return item.ID
+	}))
+}
+
 func (u *RoleService) GetPermissionsByMenuIds(ids []uint) ([]model.Permission, error) {
-	subQuery := u.db.Model(&model.MenuPermission{}).
-		Select("permission_id").
-		Where("menu_id IN (?)", ids)
-	var permissions []model.Permission
-	u.db.Where("id IN (?)", subQuery).Find(&permissions)
-	return permissions, nil
+	return menuPermissions(u.db, ids)
 }
 
 func (u *RoleService) UpdateWithMenus(role *model.Role, menus []model.Menu) (*model.Role, error) {
@@ -184,8 +230,16 @@ func (u *RoleService) UpdateWithMenus(role *model.Role, menus []model.Menu) (*mo
 	if menuCount != int64(len(menus)) {
 		return nil, fmt.Errorf("菜单参数校验失败，请检查")
 	}
+	// 租户内只能使用租户已授权的菜单
+	if err := u.checkMenusEntitled(menus); err != nil {
+		return nil, err
+	}
 
-	// 启动事务
+	// 上级角色通过下级角色接口调整
+	role.ParentID = find.ParentID
+
+	// 启动事务，casbin 授权在事务提交后写入
+	changes := &policyChanges{}
 	err = u.db.Transaction(func(tx *gorm.DB) error {
 		err := repository.NewBaseRepository[model.Role](u.db).Update(role, tx)
 		if err != nil {
@@ -213,19 +267,26 @@ func (u *RoleService) UpdateWithMenus(role *model.Role, menus []model.Menu) (*mo
 			}
 		})
 		if len(roleMenus) > 0 {
-			u.db.Clauses(clause.OnConflict{
+			if err := tx.Clauses(clause.OnConflict{
 				DoNothing: true,
-			}).Create(&roleMenus)
+			}).Create(&roleMenus).Error; err != nil {
+				return fmt.Errorf("角色菜单创建失败: %w", err)
+			}
 		}
 
 		// 角色授权
 		// 菜单关联的权限
-		permissions, _ := u.menuService.GetPermissionsByMenuIds(lo.Map(menus, func(item model.Menu, index int) uint {
+		permissions, err := menuPermissions(tx, lo.Map(menus, func(item model.Menu, index int) uint {
 			return item.ID
 		}))
+		if err != nil {
+			return err
+		}
 		// 1. 先删除role_permission 表记录，再增加记录
 		// 删除记录
-		u.db.Where("role_id = ?", role.ID).Delete(&model.RolePermission{})
+		if err := tx.Where("role_id = ?", role.ID).Delete(&model.RolePermission{}).Error; err != nil {
+			return fmt.Errorf("角色权限处理失败: %w", err)
+		}
 
 		rolePermissions := lo.Map(permissions, func(item model.Permission, index int) model.RolePermission {
 			return model.RolePermission{
@@ -233,26 +294,26 @@ func (u *RoleService) UpdateWithMenus(role *model.Role, menus []model.Menu) (*mo
 				PermissionID: item.ID,
 			}
 		})
-		u.db.Clauses(clause.OnConflict{
-			DoNothing: true,
-		}).Create(&rolePermissions)
-		// 2. 先删除 casbin 授权，再添加
-		u.enforcer.RemoveFilteredPolicy(0, "role_"+role.Name, "1")
-		casbinPermission := lo.Map(permissions, func(item model.Permission, index int) []string {
-			return []string{"1", item.PATH, item.Method}
-		})
-		if len(casbinPermission) > 0 {
-			// 补充 casbin 中 p 规则，给角色赋权
-			_, err := u.enforcer.AddPermissionsForUser("role_"+role.Name, casbinPermission...)
-			if err != nil {
-				return fmt.Errorf("角色Casbin授权失败: %w", err)
+		if len(rolePermissions) > 0 {
+			if err := tx.Clauses(clause.OnConflict{
+				DoNothing: true,
+			}).Create(&rolePermissions).Error; err != nil {
+				return fmt.Errorf("角色权限创建失败: %w", err)
 			}
 		}
+		// 2. 先删除 casbin 授权，再添加，域为角色所属租户
+		domain := tenantDomain(find.TenantID)
+		changes.resetPermissions(role.ID, domain, lo.Map(permissions, func(item model.Permission, index int) []string {
+			return []string{domain, item.PATH, item.Method}
+		}))
 		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
+	if err := changes.apply(u.enforcer); err != nil {
+		return nil, err
+	}
 
 	return role, nil
 }
@@ -264,8 +325,16 @@ func (u *RoleService) Delete(role *model.Role) error {
 	if err != nil {
 		return err
 	}
+	var childCount int64
+	if err := u.db.Model(&model.Role{}).Where("parent_id = ?", role.ID).Count(&childCount).Error; err != nil {
+		return fmt.Errorf("角色查询失败: %w", err)
+	}
+	if childCount > 0 {
+		return fmt.Errorf("存在下级角色，无法删除")
+	}
 
-	// 启动事务
+	// 启动事务，casbin 规则在事务提交后删除
+	changes := &policyChanges{}
 	err = u.db.Transaction(func(tx *gorm.DB) error {
 		// 1. 删除 role 表记录
 		err := repository.NewBaseRepository[model.Role](u.db).DeleteById(role.ID, tx)
@@ -285,12 +354,174 @@ func (u *RoleService) Delete(role *model.Role) error {
 			return fmt.Errorf("角色菜单删除失败: %w", err)
 		}
 		// 3. 删除 role_permission 表记录
-		u.db.Where("role_id = ?", role.ID).Delete(&model.RolePermission{})
+		if err := tx.Where("role_id = ?", role.ID).Delete(&model.RolePermission{}).Error; err != nil {
+			return fmt.Errorf("角色权限删除失败: %w", err)
+		}
 		// 4. 删除 casbin 记录
-		u.enforcer.RemoveFilteredPolicy(0, "role_"+role.Name, "1")
+		changes.removeFilteredPolicy(0, roleSubject(role.ID), tenantDomain(role.TenantID))
+		changes.removeFilteredGroupingPolicy(1, roleSubject(role.ID), tenantDomain(role.TenantID))
+		changes.removeFilteredGroupingPolicy(0, roleSubject(role.ID), tenantDomain(role.TenantID))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return changes.apply(u.enforcer)
+}
+
+func (u *RoleService) AttachChildren(id uint, childIds []uint) error {
+	parent, err := u.GetById(id)
+	if err != nil {
+		return fmt.Errorf("当前角色不存在，请检查")
+	}
+	childIds = lo.Uniq(childIds)
+	var children []model.Role
+	if err := u.db.Where("id IN ?", childIds).Find(&children).Error; err != nil {
+		return fmt.Errorf("角色查询失败: %w", err)
+	}
+	if len(children) != len(childIds) {
+		return fmt.Errorf("下级角色不存在，请检查")
+	}
+
+	changes := &policyChanges{}
+	err = u.db.Transaction(func(tx *gorm.DB) error {
+		for i := range children {
+			if err := setRoleParent(tx, changes, &children[i], parent.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return changes.apply(u.enforcer)
+}
+
+func (u *RoleService) DetachChild(id uint, childId uint) error {
+	child, err := u.GetById(childId)
+	if err != nil {
+		return fmt.Errorf("下级角色不存在，请检查")
+	}
+	if child.ParentID != id {
+		return fmt.Errorf("角色 %d 不是当前角色的下级角色", childId)
+	}
+	changes := &policyChanges{}
+	err = u.db.Transaction(func(tx *gorm.DB) error {
+		return setRoleParent(tx, changes, child, 0)
+	})
+	if err != nil {
+		return err
+	}
+	return changes.apply(u.enforcer)
+}
+
+func (u *RoleService) Tree() ([]*model.Role, error) {
+	var roles []model.Role
+	if err := u.db.Order("tenant_id").Order("id").Find(&roles).Error; err != nil {
+		return nil, fmt.Errorf("角色查询失败: %w", err)
+	}
+	ptrs := lo.ToSlicePtr(roles)
+	ids := lo.SliceToMap(ptrs, func(item *model.Role) (uint, *model.Role) {
+		return item.ID, item
+	})
+	tree := make([]*model.Role, 0)
+	for _, role := range ptrs {
+		// 上级角色不在当前范围内时作为根节点
+		if parent, ok := ids[role.ParentID]; ok && role.ParentID != 0 {
+			parent.Children = append(parent.Children, role)
+			continue
+		}
+		tree = append(tree, role)
+	}
+	return tree, nil
+}
+
+// 设置角色的上级角色并记录 casbin g 规则变更：g = role_<下级角色 ID>, role_<上级角色 ID>, <租户 ID>，parentId 为 0 时解除继承
+func setRoleParent(tx *gorm.DB, changes *policyChanges, role *model.Role, parentId uint) error {
+	if role.ParentID == parentId {
 		return nil
+	}
+	if parentId != 0 {
+		if err := checkRoleParent(tx, role, parentId); err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(&model.Role{}).Where("id = ?", role.ID).Update("parent_id", parentId).Error; err != nil {
+		return fmt.Errorf("上级角色设置失败: %w", err)
+	}
+
+	domain := tenantDomain(role.TenantID)
+	if role.ParentID != 0 {
+		changes.removeGroupingPolicy(roleSubject(role.ID), roleSubject(role.ParentID), domain)
+	}
+	if parentId != 0 {
+		changes.addGroupingPolicy(roleSubject(role.ID), roleSubject(parentId), domain)
+	}
+	role.ParentID = parentId
+	return nil
+}
+
+// 上级角色需属于同一租户，不能形成循环，继承层数不超过 maxRoleDepth
+func checkRoleParent(tx *gorm.DB, role *model.Role, parentId uint) error {
+	if role.ID == superRoleId || parentId == superRoleId {
+		return fmt.Errorf("超管角色不参与继承")
+	}
+	if role.ID == parentId {
+		return fmt.Errorf("角色不能继承自身")
+	}
+	var roles []model.Role
+	if err := tx.Select("id", "parent_id").Where("tenant_id = ?", role.TenantID).Find(&roles).Error; err != nil {
+		return fmt.Errorf("角色查询失败: %w", err)
+	}
+	parents := lo.SliceToMap(roles, func(item model.Role) (uint, uint) {
+		return item.ID, item.ParentID
 	})
-	return err
+	if _, ok := parents[parentId]; !ok {
+		return fmt.Errorf("上级角色不存在或不属于同一租户")
+	}
+	parents[role.ID] = parentId
+	if hasParentCycle(parents, role.ID) {
+		return fmt.Errorf("不能继承自身的下级角色")
+	}
+
+	// 层数：上级链长度 + 当前角色子树高度
+	depth := 0
+	for current := role.ID; current != 0; current = parents[current] {
+		depth++
+	}
+	var height func(id uint) int
+	height = func(id uint) int {
+		highest := 0
+		for child, parent := range parents {
+			if parent == id {
+				highest = lo.Max([]int{highest, height(child)})
+			}
+		}
+		return highest + 1
+	}
+	if depth+height(role.ID)-1 > maxRoleDepth {
+		return fmt.Errorf("角色继承不能超过 %d 层", maxRoleDepth)
+	}
+	return nil
+}
+
+// 角色及其全部上级角色 ID，由近及远
+func roleChainIds(db *gorm.DB, roleId uint) ([]uint, error) {
+	chain := []uint{roleId}
+	current := roleId
+	for len(chain) < maxRoleDepth {
+		var role model.Role
+		if err := db.Select("id", "parent_id").Where("id = ?", current).First(&role).Error; err != nil {
+			return nil, fmt.Errorf("角色查询失败: %w", err)
+		}
+		if role.ParentID == 0 || lo.Contains(chain, role.ParentID) {
+			break
+		}
+		chain = append(chain, role.ParentID)
+		current = role.ParentID
+	}
+	return chain, nil
 }
 
 func (u *RoleService) List() {
@@ -335,3 +566,38 @@ func (u *RoleService) GetPageByFilter(modelFilter model.RoleFilter, pagination b
 	}
 	return total, users, nil
 }
+
+// RoleDataScope 角色数据权限，超管为全部，租户角色最大为本租户，未设置或无效时按本人处理
+func RoleDataScope(db *gorm.DB, roleId uint, tenantId uint) (datascope.Scope, error) {
+	if roleId == superRoleId {
+		return datascope.ScopeAll, nil
+	}
+	var role model.Role
+	if err := db.Select("id", "data_scope").Where("id = ?", roleId).First(&role).Error; err != nil {
+		return "", fmt.Errorf("角色查询失败: %w", err)
+	}
+	scope := datascope.Scope(role.DataScope)
+	if !scope.IsValid() {
+		return datascope.ScopeSelf, nil
+	}
+	if scope == datascope.ScopeAll && tenantId != tenant.PlatformTenantId {
+		return datascope.ScopeTenant, nil
+	}
+	return scope, nil
+}
+
+// RoleFields 角色通过菜单获得的字段权限，含上级角色的字段权限
+func RoleFields(db *gorm.DB, roleId uint) ([]string, error) {
+	roleIds, err := roleChainIds(db, roleId)
+	if err != nil {
+		return nil, err
+	}
+	var fields []string
+	err = db.Model(&model.MenuField{}).
+		Where("menu_id IN (?)", db.Model(&model.RoleMenu{}).Select("menu_id").Where("role_id IN ?", roleIds)).
+		Distinct().Pluck("field", &fields).Error
+	if err != nil {
+		return nil, fmt.Errorf("字段权限查询失败: %w", err)
+	}
+	return fields, nil
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"gorm.io/gorm"
+)
+
+// 租户 2：角色 2 ← 3 ← 4 ← 5 ← 6 共 5 层，7 无上级，9 ← 10 两层；租户 3：角色 8
+func seedRoleHierarchy(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := newTestDB(t, &model.Role{})
+	parents := map[uint]uint{2: 0, 3: 2, 4: 3, 5: 4, 6: 5, 7: 0, 9: 0, 10: 9}
+	for id, parentId := range parents {
+		role := model.Role{Name: "role", ParentID: parentId}
+		role.ID, role.TenantID = id, 2
+		if err := db.Create(&role).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	super := model.Role{Name: "super_admin"}
+	super.ID = superRoleId
+	other := model.Role{Name: "other"}
+	other.ID, other.TenantID = 8, 3
+	if err := db.Create(&[]model.Role{super, other}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func findRole(t *testing.T, db *gorm.DB, id uint) *model.Role {
+	t.Helper()
+	var role model.Role
+	if err := db.First(&role, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return &role
+}
+
+func TestCheckRoleParent(t *testing.T) {
+	db := seedRoleHierarchy(t)
+	cases := []struct {
+		name     string
+		roleId   uint
+		parentId uint
+		wantErr  string
+	}{
+		{"有效的上级角色", 7, 3, ""},
+		{"继承链恰好 5 层", 7, 5, ""},
+		{"超管角色", superRoleId, 2, "超管角色不参与继承"},
+		{"继承超管角色", 7, superRoleId, "超管角色不参与继承"},
+		{"继承自身", 7, 7, "角色不能继承自身"},
+		{"不同租户", 7, 8, "不属于同一租户"},
+		{"上级角色不存在", 7, 100, "上级角色不存在"},
+		{"继承下级角色形成循环", 2, 6, "不能继承自身的下级角色"},
+		{"间接循环", 3, 5, "不能继承自身的下级角色"},
+		{"上级链超过层数", 7, 6, "不能超过 5 层"},
+		{"上级链与子树合计超过层数", 9, 5, "不能超过 5 层"},
+		{"上级链与子树合计恰好 5 层", 9, 4, ""},
+	}
+	for _, c := range cases {
+		err := checkRoleParent(db, findRole(t, db, c.roleId), c.parentId)
+		if c.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: 不应报错, got %v", c.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), c.wantErr) {
+			t.Errorf("%s: 应报错 %q, got %v", c.name, c.wantErr, err)
+		}
+	}
+}
+
+func TestRoleChainIds(t *testing.T) {
+	db := seedRoleHierarchy(t)
+	chain, err := roleChainIds(db, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint{6, 5, 4, 3, 2}
+	if len(chain) != len(want) {
+		t.Fatalf("got %v", chain)
+	}
+	for i := range want {
+		if chain[i] != want[i] {
+			t.Fatalf("继承链应由近及远, got %v", chain)
+		}
+	}
+}
+
+func TestAttachChildrenRollbackKeepsCasbin(t *testing.T) {
+	db := seedRoleHierarchy(t)
+	enforcer := newTestEnforcer(t)
+	service := NewRoleService(db, enforcer, nil)
+
+	// 角色 7 可设为 3 的下级，角色 8 属于其他租户，设置失败后整体回滚
+	if err := service.AttachChildren(3, []uint{7, 8}); err == nil {
+		t.Fatal("下级角色不属于同一租户时应报错")
+	}
+	if findRole(t, db, 7).ParentID != 0 {
+		t.Fatal("失败时数据库应回滚")
+	}
+	if ok, _ := enforcer.HasGroupingPolicy("role_7", "role_3", "2"); ok {
+		t.Fatal("失败时不应写入 casbin 规则")
+	}
+
+	if err := service.AttachChildren(3, []uint{7}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := enforcer.HasGroupingPolicy("role_7", "role_3", "2"); !ok {
+		t.Fatal("事务提交后应写入 casbin 规则")
+	}
+	if err := service.DetachChild(3, 7); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := enforcer.HasGroupingPolicy("role_7", "role_3", "2"); ok {
+		t.Fatal("解除继承后应删除 casbin 规则")
+	}
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/maxlcoder/homework-backend/app/contract"
+	"github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/samber/lo"
+	"gorm.io/gorm"
+)
+
+// 基础模块，所有租户默认可用
+const baseModuleName = "CoreModule"
+
+// 平台专属菜单（含子菜单），不授予租户；管理员账号为平台全局数据，只能由平台管理
+var platformMenuNumbers = []string{"admin-management", "tenant-management", "menu-management", "system-maintenance"}
+
+// TenantModule 租户模块授权情况
+type TenantModule struct {
+	Name    string `json:"name"`
+	Base    bool   `json:"base"`
+	Granted bool   `json:"granted"`
+}
+
+// 模块菜单编号，跳过平台专属菜单
+func moduleMenuNumbers(name string) ([]string, error) {
+	provider := contract.GetMenuProviderByName(name)
+	if provider == nil {
+		return nil, fmt.Errorf("模块 %s 不存在", name)
+	}
+	var numbers []string
+	var walk func(menus []*model.Menu)
+	walk = func(menus []*model.Menu) {
+		for _, menu := range menus {
+			if lo.Contains(platformMenuNumbers, menu.Number) {
+				continue
+			}
+			numbers = append(numbers, menu.Number)
+			walk(menu.Children)
+		}
+	}
+	walk(lo.ToSlicePtr(provider.GetMenus()))
+	return numbers, nil
+}
+
+func moduleMenuIds(db *gorm.DB, names ...string) ([]uint, error) {
+	var numbers []string
+	for _, name := range names {
+		moduleNumbers, err := moduleMenuNumbers(name)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, moduleNumbers...)
+	}
+	if len(numbers) == 0 {
+		return nil, nil
+	}
+	var menuIds []uint
+	if err := db.Model(&model.Menu{}).Where("number IN ?", numbers).Pluck("id", &menuIds).Error; err != nil {
+		return nil, fmt.Errorf("菜单查询失败: %w", err)
+	}
+	return menuIds, nil
+}
+
+// EntitledMenuIds 租户可用菜单：基础模块菜单与已授权模块菜单，平台租户（0）不受限制，不应调用
+func EntitledMenuIds(db *gorm.DB, tenantId uint) ([]uint, error) {
+	baseMenuIds, err := moduleMenuIds(db, baseModuleName)
+	if err != nil {
+		return nil, err
+	}
+	var grantedMenuIds []uint
+	if err := db.Model(&model.TenantMenu{}).Where("tenant_id = ?", tenantId).Pluck("menu_id", &grantedMenuIds).Error; err != nil {
+		return nil, fmt.Errorf("租户菜单查询失败: %w", err)
+	}
+	return lo.Union(baseMenuIds, grantedMenuIds), nil
+}
+
+// CheckMenusEntitled 菜单是否均在租户可用范围内
+func CheckMenusEntitled(db *gorm.DB, tenantId uint, menuIds []uint) error {
+	if tenantId == 0 {
+		return nil
+	}
+	entitled, err := EntitledMenuIds(db, tenantId)
+	if err != nil {
+		return err
+	}
+	if outside, _ := lo.Difference(menuIds, entitled); len(outside) > 0 {
+		return fmt.Errorf("菜单超出租户可用范围: %v", outside)
+	}
+	return nil
+}
+
+// RevokeUnentitledMenus 收回租户角色超出租户可用范围的菜单及对应权限，平台专属菜单调整后由初始化数据执行，casbin 规则随后按授权数据重建
+func RevokeUnentitledMenus(db *gorm.DB) error {
+	var roles []model.Role
+	if err := db.Where("tenant_id <> ?", 0).Find(&roles).Error; err != nil {
+		return fmt.Errorf("租户角色查询失败: %w", err)
+	}
+	entitledByTenant := make(map[uint][]uint)
+	for _, role := range roles {
+		entitled, ok := entitledByTenant[role.TenantID]
+		if !ok {
+			var err error
+			if entitled, err = EntitledMenuIds(db, role.TenantID); err != nil {
+				return err
+			}
+			entitledByTenant[role.TenantID] = entitled
+		}
+		var current []uint
+		if err := db.Model(&model.RoleMenu{}).Where("role_id = ?", role.ID).Pluck("menu_id", &current).Error; err != nil {
+			return fmt.Errorf("角色菜单查询失败: %w", err)
+		}
+		outside, _ := lo.Difference(current, entitled)
+		if len(outside) == 0 {
+			continue
+		}
+		if err := db.Where("role_id = ? AND menu_id IN ?", role.ID, outside).Delete(&model.RoleMenu{}).Error; err != nil {
+			return fmt.Errorf("角色菜单处理失败: %w", err)
+		}
+		permissions, err := menuPermissions(db, lo.Intersect(current, entitled))
+		if err != nil {
+			return err
+		}
+		permissionIds := lo.Map(permissions, func(item model.Permission, index int) uint {
+			return item.ID
+		})
+		query := db.Where("role_id = ?", role.ID)
+		if len(permissionIds) > 0 {
+			query = query.Where("permission_id NOT IN ?", permissionIds)
+		}
+		if err := query.Delete(&model.RolePermission{}).Error; err != nil {
+			return fmt.Errorf("角色权限处理失败: %w", err)
+		}
+		slog.Info("租户角色超出可用范围的菜单已收回", "role_id", role.ID, "tenant_id", role.TenantID, "menus", outside)
+	}
+	return nil
+}
+
+func (u *TenantService) GetModules(id uint) ([]TenantModule, error) {
+	if _, err := u.FindById(id); err != nil {
+		return nil, err
+	}
+	var grantedMenuIds []uint
+	if err := u.db.Model(&model.TenantMenu{}).Where("tenant_id = ?", id).Pluck("menu_id", &grantedMenuIds).Error; err != nil {
+		return nil, fmt.Errorf("租户菜单查询失败: %w", err)
+	}
+	modules := make([]TenantModule, 0)
+	for _, name := range contract.GetMenuProviderNames() {
+		module := TenantModule{Name: name, Base: name == baseModuleName}
+		if module.Base {
+			module.Granted = true
+		} else {
+			menuIds, err := moduleMenuIds(u.db, name)
+			if err != nil {
+				return nil, err
+			}
+			module.Granted = len(menuIds) > 0 && len(lo.Intersect(menuIds, grantedMenuIds)) > 0
+		}
+		modules = append(modules, module)
+	}
+	return modules, nil
+}
+
+func (u *TenantService) SetModules(id uint, modules []string) error {
+	if _, err := u.FindById(id); err != nil {
+		return err
+	}
+	modules = lo.Without(lo.Uniq(modules), baseModuleName)
+	menuIds, err := moduleMenuIds(u.db, modules...)
+	if err != nil {
+		return err
+	}
+
+	changes := &policyChanges{}
+	err = u.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tenant_id = ?", id).Delete(&model.TenantMenu{}).Error; err != nil {
+			return fmt.Errorf("租户菜单处理失败: %w", err)
+		}
+		if len(menuIds) > 0 {
+			tenantMenus := lo.Map(menuIds, func(item uint, index int) model.TenantMenu {
+				return model.TenantMenu{TenantId: id, MenuId: item}
+			})
+			if err := tx.Create(&tenantMenus).Error; err != nil {
+				return fmt.Errorf("租户菜单创建失败: %w", err)
+			}
+		}
+
+		// 租户角色同步：租户管理员获得全部可用菜单，其他角色去掉不再可用的菜单
+		entitled, err := EntitledMenuIds(tx, id)
+		if err != nil {
+			return err
+		}
+		var roles []model.Role
+		if err := tx.Where("tenant_id = ?", id).Find(&roles).Error; err != nil {
+			return fmt.Errorf("租户角色查询失败: %w", err)
+		}
+		for i := range roles {
+			roleMenuIds := entitled
+			if roles[i].Name != tenantAdminRoleName {
+				var current []uint
+				if err := tx.Model(&model.RoleMenu{}).Where("role_id = ?", roles[i].ID).Pluck("menu_id", &current).Error; err != nil {
+					return fmt.Errorf("角色菜单查询失败: %w", err)
+				}
+				roleMenuIds = lo.Intersect(current, entitled)
+			}
+			if err := grantRoleMenus(tx, changes, &roles[i], roleMenuIds); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return changes.apply(u.enforcer)
+}
@@ -1,31 +1,85 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 
+	"github.com/casbin/casbin/v2"
+	"github.com/maxlcoder/homework-backend/app/contract"
 	"github.com/maxlcoder/homework-backend/app/modules/core/admin/request"
 	"github.com/maxlcoder/homework-backend/app/modules/core/model"
 	base_model "github.com/maxlcoder/homework-backend/model"
+	"github.com/maxlcoder/homework-backend/pkg/datascope"
+	"github.com/maxlcoder/homework-backend/pkg/tenant"
 	"github.com/maxlcoder/homework-backend/repository"
+	"github.com/samber/lo"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrTenantSuspended = errors.New("所属租户已停用")
+	ErrTenantArchived  = errors.New("所属租户已归档")
+)
+
+// 租户管理员默认角色名称
+const tenantAdminRoleName = "tenant_admin"
+
+// 允许的状态变更，归档后不可恢复
+var tenantStatusTransitions = map[string][]string{
+	model.TenantStatusActive:    {model.TenantStatusSuspended, model.TenantStatusArchived},
+	model.TenantStatusSuspended: {model.TenantStatusActive, model.TenantStatusArchived},
+}
+
 type TenantServiceInterface interface {
 	Page(pageRequest request.TenantPageRequest) ([]model.Tenant, int64, error)
-	Create(model *model.Tenant) (*model.Tenant, error)
+	// Create 开通租户：创建租户、租户管理员角色及授权、初始管理员账号
+	Create(model *model.Tenant, admin *model.Admin) (*model.Tenant, error)
 	Update(model *model.Tenant) (*model.Tenant, error)
+	// Delete 删除已归档的租户，仍有租户用户时禁止删除，其余关联数据一并删除
 	Delete(id uint) error
 	FindById(id uint) (*model.Tenant, error)
+	SetMfaRequired(id uint, required bool) error
+	// SetStatus 变更租户状态
+	SetStatus(id uint, status string) error
+	// GetModules 租户模块授权情况
+	GetModules(id uint) ([]TenantModule, error)
+	// SetModules 设置租户可用模块（基础模块始终可用），同步调整租户角色的菜单与授权
+	SetModules(id uint, modules []string) error
 }
 
 type TenantService struct {
-	db *gorm.DB
+	db       *gorm.DB
+	enforcer *casbin.SyncedEnforcer
 }
 
-func NewTenantService(db *gorm.DB) TenantServiceInterface {
+func NewTenantService(db *gorm.DB, enforcer *casbin.SyncedEnforcer) TenantServiceInterface {
+	// 租户管理为平台功能，需跨租户读写角色、部门等租户数据，按系统任务处理
 	return &TenantService{
-		db: db,
+		db:       db.WithContext(tenant.System(db.Statement.Context)),
+		enforcer: enforcer,
+	}
+}
+
+// CheckTenantActive 租户是否可用，平台租户（0）始终可用
+func CheckTenantActive(db *gorm.DB, tenantId uint) error {
+	if tenantId == 0 {
+		return nil
 	}
+	var tenant model.Tenant
+	err := db.Select("id", "status").Where("id = ?", tenantId).First(&tenant).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTenantArchived
+	}
+	if err != nil {
+		return fmt.Errorf("租户查询失败: %w", err)
+	}
+	switch tenant.Status {
+	case model.TenantStatusSuspended:
+		return ErrTenantSuspended
+	case model.TenantStatusArchived:
+		return ErrTenantArchived
+	}
+	return nil
 }
 
 func (u *TenantService) Page(pageRequest request.TenantPageRequest) ([]model.Tenant, int64, error) {
@@ -52,7 +106,7 @@ func (u *TenantService) Page(pageRequest request.TenantPageRequest) ([]model.Ten
 	return tenants, count, nil
 }
 
-func (u *TenantService) Create(tenant *model.Tenant) (*model.Tenant, error) {
+func (u *TenantService) Create(tenant *model.Tenant, admin *model.Admin) (*model.Tenant, error) {
 	// 判断是否存在已经适用的名称
 	filer := model.Tenant{
 		Name: tenant.Name,
@@ -64,13 +118,62 @@ func (u *TenantService) Create(tenant *model.Tenant) (*model.Tenant, error) {
 	if find != nil {
 		return nil, fmt.Errorf("当前租户名称不可用，请检查")
 	}
-	err := repository.NewBaseRepository[model.Tenant](u.db).Create(tenant, nil)
+	findAdmin, _ := repository.NewBaseRepository[model.Admin](u.db).FindByName(admin.Name)
+	if findAdmin != nil {
+		return nil, fmt.Errorf("当前账号名称已存在，请检查")
+	}
+
+	tenant.Status = model.TenantStatusActive
+	changes := &policyChanges{}
+	err := u.db.Transaction(func(tx *gorm.DB) error {
+		if err := repository.NewBaseRepository[model.Tenant](u.db).Create(tenant, tx); err != nil {
+			return fmt.Errorf("租户创建失败: %w", err)
+		}
+		role := model.Role{Name: tenantAdminRoleName, DataScope: string(datascope.ScopeTenant)}
+		role.TenantID = tenant.ID
+		if err := tx.Create(&role).Error; err != nil {
+			return fmt.Errorf("租户管理员角色创建失败: %w", err)
+		}
+		// 初始管理员，首次登录需修改密码
+		admin.RoleId = role.ID
+		admin.MustChangePassword = true
+		if err := tx.Omit("Roles").Create(admin).Error; err != nil {
+			return fmt.Errorf("租户管理员创建失败: %w", err)
+		}
+		if err := tx.Create(&model.AdminRole{AdminId: admin.ID, RoleId: role.ID}).Error; err != nil {
+			return fmt.Errorf("管理员角色分配失败: %w", err)
+		}
+		if err := tx.Create(&model.TenantAdmin{TenantId: tenant.ID, AdminId: admin.ID}).Error; err != nil {
+			return fmt.Errorf("租户管理员关联失败: %w", err)
+		}
+
+		// 新租户只有基础模块可用，其他模块通过模块授权开通，casbin 授权在事务提交后写入
+		menuIds, err := EntitledMenuIds(tx, tenant.ID)
+		if err == nil {
+			err = grantRoleMenus(tx, changes, &role, menuIds)
+		}
+		if err != nil {
+			return fmt.Errorf("租户授权失败: %w", err)
+		}
+		changes.addGroupingPolicy(adminSubject(admin.ID), roleSubject(role.ID), tenantDomain(tenant.ID))
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("租户创建失败: %w", err)
+		return nil, err
+	}
+	if err := changes.apply(u.enforcer); err != nil {
+		return nil, err
 	}
 	return tenant, nil
 }
 
+// 清理租户域下全部 casbin 规则
+func (u *TenantService) removeDomainPolicies(tenantId uint) {
+	domain := tenantDomain(tenantId)
+	u.enforcer.RemoveFilteredPolicy(1, domain)
+	u.enforcer.RemoveFilteredGroupingPolicy(2, domain)
+}
+
 func (u *TenantService) Update(tenant *model.Tenant) (*model.Tenant, error) {
 	// 判断是否存在已经适用的名称（排除自身）
 	filer := model.Tenant{
@@ -92,10 +195,92 @@ func (u *TenantService) Update(tenant *model.Tenant) (*model.Tenant, error) {
 }
 
 func (u *TenantService) Delete(id uint) error {
-	err := repository.NewBaseRepository[model.Tenant](u.db).DeleteById(id, nil)
+	tenant, err := u.FindById(id)
+	if err != nil {
+		return err
+	}
+	if tenant.Status != model.TenantStatusArchived {
+		return fmt.Errorf("租户需先归档才能删除")
+	}
+	// 租户下仍有用户时禁止删除，需先迁移或删除用户
+	var userCount int64
+	if err := u.db.Model(&model.TenantUser{}).Where("tenant_id = ?", id).Count(&userCount).Error; err != nil {
+		return fmt.Errorf("租户用户查询失败: %w", err)
+	}
+	if userCount > 0 {
+		return fmt.Errorf("租户下仍有 %d 个用户，无法删除", userCount)
+	}
+	// 各模块中仍有该租户的业务数据时禁止删除，需先清理数据
+	if err := u.checkTenantData(id); err != nil {
+		return err
+	}
+
+	err = u.db.Transaction(func(tx *gorm.DB) error {
+		var roleIds []uint
+		if err := tx.Model(&model.Role{}).Where("tenant_id = ?", id).Pluck("id", &roleIds).Error; err != nil {
+			return err
+		}
+		var adminIds []uint
+		if err := tx.Model(&model.TenantAdmin{}).Where("tenant_id = ?", id).Pluck("admin_id", &adminIds).Error; err != nil {
+			return err
+		}
+		if len(roleIds) > 0 {
+			if err := tx.Where("role_id IN ?", roleIds).Delete(&model.RoleMenu{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("role_id IN ?", roleIds).Delete(&model.RolePermission{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("role_id IN ?", roleIds).Delete(&model.AdminRole{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Where("id IN ?", roleIds).Delete(&model.Role{}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("tenant_id = ?", id).Delete(&model.TenantAdmin{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tenant_id = ?", id).Delete(&model.TenantMenu{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tenant_id = ?", id).Delete(&model.Department{}).Error; err != nil {
+			return err
+		}
+		// 只属于该租户的管理员账号一并删除，仍有其他角色或租户的保留
+		if len(adminIds) > 0 {
+			err := tx.Where("id IN ?", adminIds).
+				Where("id NOT IN (?)", tx.Model(&model.AdminRole{}).Select("admin_id")).
+				Where("id NOT IN (?)", tx.Model(&model.TenantAdmin{}).Select("admin_id")).
+				Delete(&model.Admin{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return repository.NewBaseRepository[model.Tenant](u.db).DeleteById(id, tx)
+	})
 	if err != nil {
 		return fmt.Errorf("租户删除失败: %w", err)
 	}
+	u.removeDomainPolicies(id)
+	return nil
+}
+
+// 检查各模块按租户隔离的数据表中是否仍有该租户的数据；角色、部门等租户配置随租户一并删除，审计日志保留
+func (u *TenantService) checkTenantData(id uint) error {
+	for _, tenantModel := range contract.GetAllTenantModels() {
+		stmt := &gorm.Statement{DB: u.db}
+		if err := stmt.Parse(tenantModel); err != nil {
+			return fmt.Errorf("租户数据检查失败: %w", err)
+		}
+		var count int64
+		if err := u.db.Model(tenantModel).Where("tenant_id = ?", id).Count(&count).Error; err != nil {
+			return fmt.Errorf("租户数据查询失败: %w", err)
+		}
+		if count > 0 {
+			return fmt.Errorf("租户下仍有 %d 条 %s 数据，无法删除", count, stmt.Schema.Table)
+		}
+	}
 	return nil
 }
 
@@ -109,3 +294,30 @@ func (u *TenantService) FindById(id uint) (*model.Tenant, error) {
 	}
 	return tenant, nil
 }
+
+// SetMfaRequired 设置租户是否强制管理员启用两步验证
+func (u *TenantService) SetMfaRequired(id uint, required bool) error {
+	err := u.db.Model(&model.Tenant{}).Where("id = ?", id).Update("mfa_required", required).Error
+	if err != nil {
+		return fmt.Errorf("租户两步验证设置失败: %w", err)
+	}
+	return nil
+}
+
+func (u *TenantService) SetStatus(id uint, status string) error {
+	tenant, err := u.FindById(id)
+	if err != nil {
+		return err
+	}
+	if tenant.Status == status {
+		return nil
+	}
+	if !lo.Contains(tenantStatusTransitions[tenant.Status], status) {
+		return fmt.Errorf("租户状态不能从 %s 变更为 %s", tenant.Status, status)
+	}
+	err = u.db.Model(&model.Tenant{}).Where("id = ?", id).Update("status", status).Error
+	if err != nil {
+		return fmt.Errorf("租户状态变更失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package response
+
+import (
+	"github.com/maxlcoder/homework-backend/app/response"
+)
+
+// ParentResponse 家长响应结构，手机号需 parent.mobile 字段权限，未授权时打码
+type ParentResponse struct {
+	response.BaseResponse
+	Name   string `json:"name"`
+	Mobile string `json:"mobile" sensitive:"parent.mobile"`
+}
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS `student_answers`;
+DROP TABLE IF EXISTS `student_assignments`;
+DROP TABLE IF EXISTS `assignment_items`;
+DROP TABLE IF EXISTS `assignments`;
+DROP TABLE IF EXISTS `questions`;
+DROP TABLE IF EXISTS `student_parents`;
+DROP TABLE IF EXISTS `parents`;
+DROP TABLE IF EXISTS `students`;
+DROP TABLE IF EXISTS `school_teachers`;
+DROP TABLE IF EXISTS `teachers`;
+DROP TABLE IF EXISTS `courses`;
+DROP TABLE IF EXISTS `classes`;
+DROP TABLE IF EXISTS `schools`;
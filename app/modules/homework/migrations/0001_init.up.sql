@@ -0,0 +1,156 @@
+-- homework 模块初始表结构，已由 AutoMigrate 创建的数据库执行时跳过已存在的表
+
+CREATE TABLE IF NOT EXISTS `schools` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '租户 ID',
+  `name` varchar(60) NOT NULL DEFAULT '' COMMENT '学校名称',
+  `address` varchar(200) NOT NULL DEFAULT '' COMMENT '学校地址',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `classes` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '租户 ID',
+  `name` varchar(60) NOT NULL DEFAULT '' COMMENT '班级名称',
+  `number` varchar(60) NOT NULL DEFAULT '' COMMENT '班级编号',
+  `school_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '学校 ID',
+  `head_teacher_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '班主任老师 ID',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `courses` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '租户 ID',
+  `name` varchar(60) NOT NULL DEFAULT '' COMMENT '课程名称',
+  `number` varchar(60) NOT NULL DEFAULT '' COMMENT '课程编号',
+  `school_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '学校 ID',
+  `class_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '班级 ID',
+  `teacher_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '讲课老师 ID',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `teachers` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '租户 ID',
+  `name` varchar(60) NOT NULL DEFAULT '' COMMENT '姓名',
+  `age` tinyint COMMENT '年龄',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `school_teachers` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `school_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '学校 ID',
+  `teacher_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '教师 ID',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `students` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '租户 ID',
+  `name` varchar(60) NOT NULL DEFAULT '' COMMENT '学校名称',
+  `school_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '学校 ID',
+  `class_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '学校 ID',
+  `age` tinyint COMMENT '年龄',
+  `birthday` longtext COMMENT '生日',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `parents` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `name` varchar(60) NOT NULL DEFAULT '' COMMENT '姓名',
+  `mobile` varchar(30) NOT NULL DEFAULT '' COMMENT '手机号',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `student_parents` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `student_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '学生 ID',
+  `parent_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '家长 ID',
+  `parent_type` tinyint NOT NULL DEFAULT 0 COMMENT '家长类型 1:爸爸 2:妈妈 3:爷爷 4:奶奶 5...',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `questions` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `title` varchar(200) NOT NULL DEFAULT '' COMMENT '标题',
+  `content` text COMMENT '内容',
+  `remark` varchar(2000) NOT NULL DEFAULT '' COMMENT '备注',
+  `score` smallint COMMENT '分数',
+  `answer` text COMMENT '答案',
+  `type` tinyint COMMENT '类型 1:填空题 2:选择题(单选) 3:选择题(多选) 4:对错题 5:解答题 6:画图题(附加)',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `assignments` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '租户 ID',
+  `title` varchar(60) NOT NULL DEFAULT '' COMMENT '作业标题',
+  `number` varchar(60) NOT NULL DEFAULT '' COMMENT '作业编号',
+  `school_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '学校 ID',
+  `class_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '班级 ID',
+  `teacher_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '老师 ID',
+  `course_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '课程 ID',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `assignment_items` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `question_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '来源问题 ID',
+  `title` varchar(200) NOT NULL DEFAULT '' COMMENT '标题',
+  `content` text COMMENT '内容',
+  `remark` varchar(2000) NOT NULL DEFAULT '' COMMENT '备注',
+  `score` smallint COMMENT '分数',
+  `answer` text COMMENT '答案',
+  `type` tinyint COMMENT '类型 1:填空题 2:选择题(单选) 3:选择题(多选) 4:对错题 5:解答题 6:画图题(附加)',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `student_assignments` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `tenant_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '租户 ID',
+  `student_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '学生 ID',
+  `assignment_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '作业 ID',
+  `score` smallint COMMENT '总分',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `student_answers` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `student_assigment_item_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '作业领取 ID',
+  `assigment_item_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '作业项 ID',
+  `student_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '学生 ID',
+  `answer` longtext COMMENT '答案',
+  `answer_file` longtext COMMENT '答案文件',
+  `score` smallint COMMENT '得分',
+  `revision` longtext COMMENT '修订',
+  `teacher_id` longtext COMMENT '评分老师 ID',
+  `teacher_comment` longtext COMMENT '老师评语',
+  `final_score` smallint COMMENT '最终得分',
+  PRIMARY KEY (`id`)
+);
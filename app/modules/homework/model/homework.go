@@ -2,7 +2,6 @@ package model
 
 import (
 	base_model "github.com/maxlcoder/homework-backend/model"
-	"gorm.io/gorm"
 )
 
 // School 学校
@@ -125,9 +124,9 @@ type Question struct {
 	Type    int8   `gorm:"comment:类型 1:填空题 2:选择题(单选) 3:选择题(多选) 4:对错题 5:解答题 6:画图题(附加)"`
 }
 
+// Models homework 模块全部数据表模型，表结构变更需同步新增 migrations 中的迁移文件
 func Models() []interface{} {
 	return []interface{}{
-		// 初始化 wms 数据库
 		&School{},
 		&Class{},
 		&Course{},
@@ -143,7 +142,3 @@ func Models() []interface{} {
 		&StudentAnswer{},
 	}
 }
-
-func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(Models()...)
-}
@@ -0,0 +1,21 @@
+package response
+
+import (
+	"github.com/maxlcoder/homework-backend/app/response"
+	"github.com/shopspring/decimal"
+)
+
+// OrderResponse 订单响应结构，金额需 order.amount 字段权限，未授权时不输出
+type OrderResponse struct {
+	response.BaseResponse
+	StoreOrderId   uint             `json:"store_order_id"`
+	Address        string           `json:"address"`
+	ProvinceCode   string           `json:"province_code"`
+	CityCode       string           `json:"city_code"`
+	CountyCode     string           `json:"county_code"`
+	TotalAmount    *decimal.Decimal `json:"total_amount,omitempty" sensitive:"order.amount"`
+	Currency       string           `json:"currency"`
+	TotalAmountCny *decimal.Decimal `json:"total_amount_cny,omitempty" sensitive:"order.amount"`
+	TotalAmountUsd *decimal.Decimal `json:"total_amount_usd,omitempty" sensitive:"order.amount"`
+	State          int              `json:"state"`
+}
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS `webhook_logs`;
+DROP TABLE IF EXISTS `store_order_items`;
+DROP TABLE IF EXISTS `store_orders`;
+DROP TABLE IF EXISTS `order_operate_logs`;
+DROP TABLE IF EXISTS `order_items`;
+DROP TABLE IF EXISTS `orders`;
+DROP TABLE IF EXISTS `platforms`;
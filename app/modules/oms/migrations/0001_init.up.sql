@@ -0,0 +1,87 @@
+-- oms 模块初始表结构
+
+CREATE TABLE IF NOT EXISTS `platforms` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `name` varchar(60) NOT NULL DEFAULT '' COMMENT '名称',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `orders` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `store_order_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '店铺订单 ID',
+  `address` varchar(200) NOT NULL DEFAULT '' COMMENT '详细地址',
+  `province_code` varchar(30) NOT NULL DEFAULT '' COMMENT '省编号',
+  `city_code` varchar(30) NOT NULL DEFAULT '' COMMENT '市编号',
+  `county_code` varchar(30) NOT NULL DEFAULT '' COMMENT '区编号',
+  `total_amount` decimal(14,4) COMMENT '金额',
+  `currency` varchar(10) NOT NULL DEFAULT '' COMMENT '币种',
+  `total_amount_cny` decimal(14,4) COMMENT '人民币金额',
+  `total_amount_usd` decimal(14,4) COMMENT '美元金额',
+  `state` tinyint COMMENT '状态',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `order_items` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `product_name` varchar(60) NOT NULL DEFAULT '' COMMENT '商品名称',
+  `quantity` smallint NOT NULL DEFAULT 0 COMMENT '商品数量',
+  `price` decimal(14,4) COMMENT '单价',
+  `currency` varchar(10) NOT NULL DEFAULT '' COMMENT '币种',
+  `price_cny` decimal(14,4) COMMENT '人民币价格',
+  `price_usd` decimal(14,4) COMMENT '美元价格',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `order_operate_logs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `admin_id` bigint unsigned NOT NULL DEFAULT 0,
+  `content` longtext COMMENT '内容',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `store_orders` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `address` varchar(200) NOT NULL DEFAULT '' COMMENT '详细地址',
+  `province_code` varchar(30) NOT NULL DEFAULT '' COMMENT '省编号',
+  `city_code` varchar(30) NOT NULL DEFAULT '' COMMENT '市编号',
+  `county_code` varchar(30) NOT NULL DEFAULT '' COMMENT '区编号',
+  `total_amount` decimal(14,4) COMMENT '金额',
+  `currency` varchar(10) NOT NULL DEFAULT '' COMMENT '币种',
+  `total_amount_cny` decimal(14,4) COMMENT '人民币金额',
+  `total_amount_usd` decimal(14,4) COMMENT '美元金额',
+  `state` tinyint COMMENT '状态',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `store_order_items` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `product_name` varchar(60) NOT NULL DEFAULT '' COMMENT '商品名称',
+  `quantity` smallint NOT NULL DEFAULT 0 COMMENT '商品数量',
+  `price` decimal(14,4) COMMENT '单价',
+  `currency` varchar(10) NOT NULL DEFAULT '' COMMENT '币种',
+  `price_cny` decimal(14,4) COMMENT '人民币价格',
+  `price_usd` decimal(14,4) COMMENT '美元价格',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `webhook_logs` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `unique_num` varchar(20) NOT NULL DEFAULT '' COMMENT '唯一编号',
+  `platform_type` varchar(20) NOT NULL DEFAULT '' COMMENT '平台类型',
+  `content` longtext COMMENT '内容',
+  PRIMARY KEY (`id`)
+);
@@ -76,3 +76,16 @@ type WebhookLog struct {
 	PlatformType string `gorm:"size:20;not null;default:'';comment:平台类型"`
 	Content      string `gorm:"comment:内容"`
 }
+
+// Models oms 模块全部数据表模型，表结构变更需同步新增 migrations 中的迁移文件
+func Models() []interface{} {
+	return []interface{}{
+		&Platform{},
+		&Order{},
+		&OrderItem{},
+		&OrderOperateLog{},
+		&StoreOrder{},
+		&StoreOrderItem{},
+		&WebhookLog{},
+	}
+}
@@ -0,0 +1,68 @@
+package route
+
+import (
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxlcoder/homework-backend/app/contract"
+	core_model "github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/app/modules/oms/model"
+	base_model "github.com/maxlcoder/homework-backend/model"
+	"gorm.io/gorm"
+)
+
+// OmsModule OMS模块结构，实现RouteModule和ModuleInitializer接口
+type OmsModule struct {
+	DB          *gorm.DB
+	initialized bool
+}
+
+// Name 返回模块名称，实现RouteModule接口
+func (m *OmsModule) Name() string {
+	return "OmsModule"
+}
+
+// GetMenus 返回OMS模块的菜单定义，实现MenuProvider接口
+func (m *OmsModule) GetMenus() []core_model.Menu {
+	return []core_model.Menu{
+		{
+			Number: "oms-management",
+			Name:   "OMS管理",
+			Sort:   3,
+			Children: []*core_model.Menu{
+				{
+					Number: "order-management",
+					Name:   "订单管理",
+					Children: []*core_model.Menu{
+						{
+							Number: "order-amount",
+							Name:   "查看金额",
+							Fields: []string{"order.amount"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// TenantModels 返回模块中按租户隔离的数据表，删除租户前检查，实现TenantDataProvider接口
+func (m *OmsModule) TenantModels() []interface{} {
+	return base_model.TenantModels(model.Models())
+}
+
+// Init 初始化模块，实现ModuleInitializer接口
+func (m *OmsModule) Init() contract.Module {
+	if !m.initialized {
+		m.initialized = true
+	}
+	return m
+}
+
+// RegisterRoutes 注册模块路由，实现RouteModule接口；后台订单接口尚未开放，当前只提供菜单与字段权限
+func (m *OmsModule) RegisterRoutes(apiGroup *gin.RouterGroup, apiAuthGroup *gin.RouterGroup, adminGroup *gin.RouterGroup, adminAuthGroup *gin.RouterGroup, module interface{}) {
+	slog.Info("registering module routes", "module", "oms")
+
+	// 确保模块已初始化
+	m.Init()
+}
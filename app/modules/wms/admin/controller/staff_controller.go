@@ -36,7 +36,7 @@ func (controller *StaffController) Page(c *gin.Context) {
 	}
 
 	// 获取员工列表
-	staffs, total, err := controller.staffService.Page(pageRequest)
+	staffs, total, err := controller.staffService.WithContext(c.Request.Context()).Page(pageRequest)
 	if err != nil {
 		controller.Error(c, http.StatusInternalServerError, "获取员工列表失败")
 		return
@@ -57,7 +57,7 @@ func (controller *StaffController) Show(c *gin.Context) {
 	}
 
 	// 获取员工列表
-	staffs, total, err := controller.staffService.Page(pageRequest)
+	staffs, total, err := controller.staffService.WithContext(c.Request.Context()).Page(pageRequest)
 	if err != nil {
 		controller.Error(c, http.StatusInternalServerError, "获取员工列表失败")
 		return
@@ -86,7 +86,7 @@ func (controller *StaffController) Store(c *gin.Context) {
 	}
 
 	// 创建员工
-	_, err = controller.staffService.Create(&staff)
+	_, err = controller.staffService.WithContext(c.Request.Context()).Create(&staff)
 	if err != nil {
 		controller.Error(c, http.StatusInternalServerError, "创建员工失败")
 		return
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS `wms_picking_basket`;
+DROP TABLE IF EXISTS `wms_picking_car`;
+DROP TABLE IF EXISTS `wms_bin`;
+DROP TABLE IF EXISTS `wms_warehouse`;
@@ -0,0 +1,50 @@
+-- wms 模块基线表结构，与引入版本化迁移前 AutoMigrate 创建的表一致，已存在的表执行时跳过
+
+CREATE TABLE IF NOT EXISTS `wms_warehouse` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) NULL DEFAULT null COMMENT '删除时间',
+  `name` varchar(100) NOT NULL DEFAULT '' COMMENT '名称',
+  `address` varchar(200) NOT NULL DEFAULT '' COMMENT '详细地址',
+  `province_code` varchar(30) NOT NULL DEFAULT '' COMMENT '省编号',
+  `city_code` varchar(30) NOT NULL DEFAULT '' COMMENT '市编号',
+  `county_code` varchar(30) NOT NULL DEFAULT '' COMMENT '区编号',
+  `area` varchar(30) NOT NULL DEFAULT '' COMMENT '面积',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `wms_bin` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) NULL DEFAULT null COMMENT '删除时间',
+  `warehouse_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '仓库 ID',
+  `code` varchar(60) NOT NULL DEFAULT '' COMMENT '库位编号',
+  `sku_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '当前存放 SKU ID',
+  `num` smallint NOT NULL DEFAULT 0 COMMENT 'SKU 商品数量',
+  `expiration_date` varchar(191) DEFAULT NULL COMMENT '过期时间',
+  PRIMARY KEY (`id`),
+  INDEX `idx_warehouse_id` (`warehouse_id`),
+  CONSTRAINT `uni_wms_bin_code` UNIQUE (`code`)
+);
+
+CREATE TABLE IF NOT EXISTS `wms_picking_car` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) NULL DEFAULT null COMMENT '删除时间',
+  `code` varchar(60) NOT NULL DEFAULT '' COMMENT '编号',
+  `max_basket_count` tinyint NOT NULL DEFAULT 0 COMMENT '最大拣货框数',
+  PRIMARY KEY (`id`),
+  CONSTRAINT `uni_wms_picking_car_code` UNIQUE (`code`)
+);
+
+CREATE TABLE IF NOT EXISTS `wms_picking_basket` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) NULL DEFAULT null COMMENT '删除时间',
+  `code` varchar(60) NOT NULL DEFAULT '' COMMENT '编号',
+  PRIMARY KEY (`id`)
+);
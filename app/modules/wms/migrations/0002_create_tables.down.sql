@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS `wms_picking_task_basket_product`;
+DROP TABLE IF EXISTS `wms_picking_task_basket`;
+DROP TABLE IF EXISTS `wms_picking_task`;
+DROP TABLE IF EXISTS `wms_picking_car_basket`;
+DROP TABLE IF EXISTS `wms_stock_task_product`;
+DROP TABLE IF EXISTS `wms_stock_task`;
+DROP TABLE IF EXISTS `wms_stock_order_product`;
+DROP TABLE IF EXISTS `wms_stock_order`;
+DROP TABLE IF EXISTS `wms_staff`;
@@ -0,0 +1,106 @@
+-- 新增人员、入库、拣货任务等表
+
+CREATE TABLE `wms_staff` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) NULL DEFAULT null COMMENT '删除时间',
+  `created_by` bigint unsigned NOT NULL DEFAULT 0 COMMENT '创建人',
+  `department_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '归属部门 ID',
+  `code` varchar(60) NOT NULL DEFAULT '' COMMENT '编号',
+  `name` varchar(60) NOT NULL DEFAULT '' COMMENT '姓名',
+  `state` tinyint NOT NULL DEFAULT 1 COMMENT '状态',
+  PRIMARY KEY (`id`),
+  INDEX `idx_wms_staff_created_by` (`created_by`),
+  INDEX `idx_wms_staff_department_id` (`department_id`)
+);
+
+CREATE TABLE `wms_stock_order` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) NULL DEFAULT null COMMENT '删除时间',
+  `code` varchar(60) NOT NULL DEFAULT '' COMMENT '编号',
+  `in_date` varchar(30) NOT NULL DEFAULT '',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE `wms_stock_order_product` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) NULL DEFAULT null COMMENT '删除时间',
+  `stock_order_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '入库单 ID',
+  `product_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '商品 ID',
+  `num` smallint NOT NULL DEFAULT 0 COMMENT '数量',
+  `bin_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '库位 ID',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE `wms_stock_task` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) NULL DEFAULT null COMMENT '删除时间',
+  `code` varchar(60) NOT NULL DEFAULT '' COMMENT '编号',
+  `stock_order_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '入库单 ID',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE `wms_stock_task_product` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) NULL DEFAULT null COMMENT '删除时间',
+  `stock_task_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '入库任务 ID',
+  `product_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '商品 ID',
+  `num` smallint NOT NULL DEFAULT 0 COMMENT '数量',
+  `bin_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '库位 ID',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE `wms_picking_car_basket` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) NULL DEFAULT null COMMENT '删除时间',
+  `picking_car_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '拣货车 ID',
+  `picking_basket_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '拣货篮 ID',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE `wms_picking_task` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) NULL DEFAULT null COMMENT '删除时间',
+  `picking_car_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '拣货车 ID',
+  `staff_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '拣货员工 ID',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE `wms_picking_task_basket` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) NULL DEFAULT null COMMENT '删除时间',
+  `picking_task_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '拣货任务 ID',
+  `picking_car_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '拣货车 ID',
+  `order_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '订单 ID',
+  PRIMARY KEY (`id`)
+);
+
+CREATE TABLE `wms_picking_task_basket_product` (
+  `id` bigint unsigned AUTO_INCREMENT,
+  `created_at` datetime(3) NOT NULL COMMENT '创建时间',
+  `updated_at` datetime(3) NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime(3) NULL DEFAULT null COMMENT '删除时间',
+  `picking_task_basket_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '拣货框 ID',
+  `picking_task_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '拣货任务 ID',
+  `picking_car_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '拣货车 ID',
+  `order_id` bigint unsigned NOT NULL DEFAULT 0 COMMENT '订单 ID',
+  `sku_code` varchar(191) NOT NULL DEFAULT '' COMMENT 'SKU CODE',
+  `num` smallint NOT NULL DEFAULT 0 COMMENT '应拣数量',
+  `actual_num` smallint NOT NULL DEFAULT 0 COMMENT '实拣数量',
+  PRIMARY KEY (`id`)
+);
@@ -4,7 +4,6 @@ import (
 	"time"
 
 	base_model "github.com/maxlcoder/homework-backend/model"
-	"gorm.io/gorm"
 )
 
 const TablePrefix = "wms_"
@@ -73,6 +72,7 @@ func (s StaffState) IsValid() bool {
 // 仓库人员
 type Staff struct {
 	base_model.BaseSoftDeletedModel
+	base_model.DataOwner
 	Code  string     `gorm:"size:60;not null;default:'';comment:编号"`
 	Name  string     `gorm:"size:60;not null;default:'';comment:姓名"`
 	State StaffState `gorm:"not null;default:1;comment:状态"` // 默认启用状态
@@ -218,16 +218,21 @@ func (PickingTaskBasketProduct) TableName() string {
 	return TablePrefix + "picking_task_basket_product"
 }
 
+// Models wms 模块全部数据表模型，表结构变更需同步新增 migrations 中的迁移文件
 func Models() []interface{} {
 	return []interface{}{
-		// 初始化 wms 数据库
 		&Warehouse{},
 		&Bin{},
+		&Staff{},
+		&StockOrder{},
+		&StockOrderProduct{},
+		&StockTask{},
+		&StockTaskProduct{},
 		&PickingCar{},
 		&PickingBasket{},
+		&PickingCarBasket{},
+		&PickingTask{},
+		&PickingTaskBasket{},
+		&PickingTaskBasketProduct{},
 	}
 }
-
-func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(Models()...)
-}
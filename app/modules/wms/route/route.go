@@ -1,18 +1,17 @@
 package route
 
 import (
-	"fmt"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
 	"github.com/maxlcoder/homework-backend/app/contract"
+	"github.com/maxlcoder/homework-backend/app/middleware"
 	core_model "github.com/maxlcoder/homework-backend/app/modules/core/model"
 	wms_admin_controller "github.com/maxlcoder/homework-backend/app/modules/wms/admin/controller"
-	admin_middleware "github.com/maxlcoder/homework-backend/app/modules/wms/admin/middleware"
 	wms_api_controller "github.com/maxlcoder/homework-backend/app/modules/wms/api/controller"
-	api_middleware "github.com/maxlcoder/homework-backend/app/modules/wms/api/middleware"
-	module_middleware "github.com/maxlcoder/homework-backend/app/modules/wms/middleware"
 	"github.com/maxlcoder/homework-backend/app/modules/wms/model"
 	"github.com/maxlcoder/homework-backend/app/modules/wms/service"
+	base_model "github.com/maxlcoder/homework-backend/model"
 
 	"gorm.io/gorm"
 )
@@ -300,11 +299,14 @@ func (m *WmsModule) GetMenus() []core_model.Menu {
 	}
 }
 
+// TenantModels 返回模块中按租户隔离的数据表，删除租户前检查，实现TenantDataProvider接口
+func (m *WmsModule) TenantModels() []interface{} {
+	return base_model.TenantModels(model.Models())
+}
+
 // Init 初始化模块，实现ModuleInitializer接口
 func (m *WmsModule) Init() contract.Module {
 	if !m.initialized {
-		// 初始化表
-		model.AutoMigrate(m.DB)
 		// 初始化服务
 		pickingCarService := service.NewPickingCarService(m.DB)
 		pickingBasketService := service.NewPickingBasketService(m.DB)
@@ -335,7 +337,7 @@ func (m *WmsModule) Init() contract.Module {
 
 // RegisterRoutes 注册模块路由，实现RouteModule接口
 func (m *WmsModule) RegisterRoutes(apiGroup *gin.RouterGroup, apiAuthGroup *gin.RouterGroup, adminGroup *gin.RouterGroup, adminAuthGroup *gin.RouterGroup, module interface{}) {
-	fmt.Println("Registering WMS Module routes")
+	slog.Info("registering module routes", "module", "wms")
 
 	// 确保模块已初始化
 	m.Init()
@@ -343,9 +345,6 @@ func (m *WmsModule) RegisterRoutes(apiGroup *gin.RouterGroup, apiAuthGroup *gin.
 	// 注册模块接口
 	apiGroup = apiGroup.Group("/wms")
 	apiAuthGroup = apiAuthGroup.Group("/wms")
-	// 添加WMS API模块级中间件
-	apiGroup.Use(module_middleware.Logger())
-	apiAuthGroup.Use(module_middleware.Logger())
 	if m.ApiController != nil {
 		m.ApiController.RegisterRoutes(apiGroup, apiAuthGroup)
 	}
@@ -353,9 +352,6 @@ func (m *WmsModule) RegisterRoutes(apiGroup *gin.RouterGroup, apiAuthGroup *gin.
 	// 注册Admin路由 - 后台接口
 	adminGroup = adminGroup.Group("/wms")
 	adminAuthGroup = adminAuthGroup.Group("/wms")
-	// 应用Admin子模块的中间件
-	adminGroup.Use(module_middleware.Logger())
-	adminAuthGroup.Use(module_middleware.Logger())
 	if m.AdminController != nil {
 
 		// 注册需要认证的路由
@@ -370,18 +366,12 @@ func NewWmsModule(db *gorm.DB) *WmsModule {
 
 // RegisterRoutes 为 ApiController 添加路由注册方法
 func (ctrl *ApiController) RegisterRoutes(group *gin.RouterGroup, authGroup *gin.RouterGroup) {
-	// 注册中间件
-	authGroup.Use(api_middleware.Logger())
-
 	// 普通接口 - 继承父路由组的中间件
 	authGroup.GET("bins", ctrl.BinController.Page) // 分页列表、
 }
 
 // RegisterRoutes 为 AdminController 添加路由注册方法
 func (ctrl *AdminController) RegisterRoutes(group *gin.RouterGroup, authGroup *gin.RouterGroup) {
-	// 注册中间件
-	authGroup.Use(admin_middleware.Logger())
-
 	// ------------ 拣货车管理 ------------
 	authGroup.GET("picking-cars", ctrl.PickingCarController.Page)           // 分页列表
 	authGroup.GET("picking-cars/:id", ctrl.PickingCarController.Show)       // 详情
@@ -409,4 +399,10 @@ func (ctrl *AdminController) RegisterRoutes(group *gin.RouterGroup, authGroup *g
 	authGroup.POST("staffs", ctrl.StaffController.Store)         // 新增
 	authGroup.PUT("staffs/:id", ctrl.StaffController.Update)     // 更新
 	authGroup.DELETE("staffs/:id", ctrl.StaffController.Destroy) // 删除
+
+	// 审计资源
+	middleware.RegisterAuditResource("/admin/wms/picking-cars", &model.PickingCar{})
+	middleware.RegisterAuditResource("/admin/wms/picking-baskets", &model.PickingBasket{})
+	middleware.RegisterAuditResource("/admin/wms/bins", &model.Bin{})
+	middleware.RegisterAuditResource("/admin/wms/staffs", &model.Staff{})
 }
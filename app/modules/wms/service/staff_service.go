@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 
 	"github.com/maxlcoder/homework-backend/app/modules/wms/model"
@@ -14,6 +15,8 @@ import (
 
 // StaffServiceInterface 仓库人员服务接口
 type StaffServiceInterface interface {
+	// WithContext 绑定请求 context，列表按当前操作人的数据权限过滤
+	WithContext(ctx context.Context) StaffServiceInterface
 	Page(pageRequest base_request.PageRequest) ([]model.Staff, int64, error)
 	Create(model *model.Staff) (*model.Staff, error)
 	Update(model *model.Staff) (*model.Staff, error)
@@ -36,6 +39,12 @@ func NewStaffService(db *gorm.DB) StaffServiceInterface {
 	}
 }
 
+func (u *StaffService) WithContext(ctx context.Context) StaffServiceInterface {
+	return &StaffService{
+		db: u.db.WithContext(ctx),
+	}
+}
+
 // Page 分页获取仓库人员列表
 func (u *StaffService) Page(pageRequest base_request.PageRequest) ([]model.Staff, int64, error) {
 	// 创建分页参数
@@ -60,6 +69,7 @@ func (u *StaffService) Create(staff *model.Staff) (*model.Staff, error) {
 		MapCond: map[string]interface{}{
 			"name": staff.Name,
 		},
+		SkipDataScope: true,
 	}
 	find, err := repository.NewBaseRepository[model.Staff](u.db).FindBy(cond)
 	if err == nil && find != nil {
@@ -32,18 +32,20 @@ func (br *BaseResponse) FromBaseModel(bm base_model.BaseModel) {
 	br.UpdatedAt = JSONTime(bm.UpdatedAt)
 }
 
-// ConvertModelToResponse 通用的模型到响应转换函数
-func ConvertModelToResponse[M any, R any](model M) R {
+// ConvertModelToResponse 通用的模型到响应转换函数，未在 grants 中授权的敏感字段会被打码或置空
+func ConvertModelToResponse[M any, R any](model M, grants ...FieldGrants) R {
 	var response R
 	copier.Copy(&response, &model)
+	MaskSensitive(&response, mergeFieldGrants(grants))
 	return response
 }
 
 // ConvertSlice 通用的切片转换函数
-func ConvertSlice[M any, R any](models []M) []R {
+func ConvertSlice[M any, R any](models []M, grants ...FieldGrants) []R {
+	fieldGrants := mergeFieldGrants(grants)
 	responses := make([]R, len(models))
 	for i, m := range models {
-		responses[i] = ConvertModelToResponse[M, R](m)
+		responses[i] = ConvertModelToResponse[M, R](m, fieldGrants)
 	}
 	return responses
 }
@@ -61,21 +63,23 @@ type DataId struct {
 	ID uint `json:"id"`
 }
 
-// BuildPageResponse 组装分页结果（支持泛型转换）
-func BuildPageResponse[M any, R any](models []M, total int64, page, perPage int) PageResponse[R] {
+// BuildPageResponse 组装分页结果（支持泛型转换），grants 为当前角色的字段权限
+func BuildPageResponse[M any, R any](models []M, total int64, page, perPage int, grants ...FieldGrants) PageResponse[R] {
 	return PageResponse[R]{
 		Page:    page,
 		PerPage: perPage,
 		Total:   total,
-		Data:    ConvertSlice[M, R](models),
+		Data:    ConvertSlice[M, R](models, grants...),
 	}
 }
 
 // BuildPageResponseWithMapper 使用自定义转换函数组装分页结果
-func BuildPageResponseWithMapper[M any, R any](models []M, total int64, page, perPage int, mapper func(M) R) PageResponse[R] {
+func BuildPageResponseWithMapper[M any, R any](models []M, total int64, page, perPage int, mapper func(M) R, grants ...FieldGrants) PageResponse[R] {
+	fieldGrants := mergeFieldGrants(grants)
 	responses := make([]R, len(models))
 	for i, m := range models {
 		responses[i] = mapper(m)
+		MaskSensitive(&responses[i], fieldGrants)
 	}
 	return PageResponse[R]{
 		Page:    page,
@@ -0,0 +1,99 @@
+package response
+
+import (
+	"reflect"
+	"strings"
+)
+
+// 敏感字段标签，如 `sensitive:"admin.email"`，值为字段权限编号；追加 ",drop" 时未授权直接置空而不是打码
+const sensitiveTag = "sensitive"
+
+// FieldGrants 当前角色已授权的字段权限
+type FieldGrants map[string]struct{}
+
+func NewFieldGrants(fields ...string) FieldGrants {
+	grants := make(FieldGrants, len(fields))
+	for _, field := range fields {
+		grants[field] = struct{}{}
+	}
+	return grants
+}
+
+func (g FieldGrants) Has(field string) bool {
+	_, ok := g[field]
+	return ok
+}
+
+// 合并多个授权，未传入时视为无任何字段权限
+func mergeFieldGrants(grants []FieldGrants) FieldGrants {
+	if len(grants) == 1 {
+		return grants[0]
+	}
+	merged := FieldGrants{}
+	for _, grant := range grants {
+		for field := range grant {
+			merged[field] = struct{}{}
+		}
+	}
+	return merged
+}
+
+// MaskSensitive 处理响应中未授权的敏感字段：字符串打码，drop 或非字符串字段置为零值（配合 omitempty 不输出），v 需为指针
+func MaskSensitive(v interface{}, grants FieldGrants) {
+	maskValue(reflect.ValueOf(v), grants)
+}
+
+func maskValue(v reflect.Value, grants FieldGrants) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			maskValue(v.Elem(), grants)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			maskValue(v.Index(i), grants)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := v.Field(i)
+			if !field.CanSet() {
+				continue
+			}
+			tag, ok := t.Field(i).Tag.Lookup(sensitiveTag)
+			if !ok {
+				maskValue(field, grants)
+				continue
+			}
+			name, option, _ := strings.Cut(tag, ",")
+			if grants.Has(name) {
+				continue
+			}
+			if field.Kind() == reflect.String && option != "drop" {
+				field.SetString(MaskString(field.String()))
+			} else {
+				field.Set(reflect.Zero(field.Type()))
+			}
+		}
+	}
+}
+
+// MaskString 打码：邮箱保留首字符与域名，较长内容（如手机号）保留前三后四位，其余保留首尾字符
+func MaskString(s string) string {
+	if s == "" {
+		return s
+	}
+	if local, domain, ok := strings.Cut(s, "@"); ok && local != "" {
+		return string([]rune(local)[:1]) + "***@" + domain
+	}
+	runes := []rune(s)
+	n := len(runes)
+	switch {
+	case n <= 2:
+		return strings.Repeat("*", n)
+	case n < 7:
+		return string(runes[:1]) + "***" + string(runes[n-1:])
+	default:
+		return string(runes[:3]) + "****" + string(runes[n-4:])
+	}
+}
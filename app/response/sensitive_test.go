@@ -0,0 +1,87 @@
+package response
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+type maskedContact struct {
+	Mobile string `sensitive:"parent.mobile"`
+}
+
+type maskedAdmin struct {
+	Name     string
+	Email    string          `sensitive:"admin.email"`
+	IdCard   string          `sensitive:"admin.id_card,drop"`
+	Amount   decimal.Decimal `sensitive:"order.amount"`
+	Contact  maskedContact
+	Contacts []*maskedContact
+}
+
+func newMaskedAdmin() *maskedAdmin {
+	return &maskedAdmin{
+		Name:     "admin",
+		Email:    "admin@homework.com",
+		IdCard:   "110101199001011234",
+		Amount:   decimal.NewFromInt(100),
+		Contact:  maskedContact{Mobile: "13800138000"},
+		Contacts: []*maskedContact{{Mobile: "13900139000"}, nil},
+	}
+}
+
+func TestMaskSensitiveWithoutGrants(t *testing.T) {
+	admin := newMaskedAdmin()
+	MaskSensitive(admin, NewFieldGrants())
+	if admin.Name != "admin" {
+		t.Errorf("未标记的字段不应处理, got %q", admin.Name)
+	}
+	if admin.Email != "a***@homework.com" {
+		t.Errorf("邮箱应打码, got %q", admin.Email)
+	}
+	if admin.IdCard != "" {
+		t.Errorf("drop 字段应置空, got %q", admin.IdCard)
+	}
+	if !admin.Amount.IsZero() {
+		t.Errorf("非字符串字段应置为零值, got %s", admin.Amount)
+	}
+	if admin.Contact.Mobile != "138****8000" {
+		t.Errorf("嵌套结构体应处理, got %q", admin.Contact.Mobile)
+	}
+	if admin.Contacts[0].Mobile != "139****9000" {
+		t.Errorf("切片中的结构体应处理, got %q", admin.Contacts[0].Mobile)
+	}
+}
+
+func TestMaskSensitiveWithGrants(t *testing.T) {
+	admin := newMaskedAdmin()
+	MaskSensitive(admin, NewFieldGrants("admin.email", "order.amount", "parent.mobile"))
+	if admin.Email != "admin@homework.com" || admin.Contact.Mobile != "13800138000" || !admin.Amount.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("已授权字段不应处理, got %+v", admin)
+	}
+	if admin.IdCard != "" {
+		t.Errorf("未授权字段应置空, got %q", admin.IdCard)
+	}
+
+	admins := []maskedAdmin{*newMaskedAdmin()}
+	MaskSensitive(&admins, NewFieldGrants())
+	if admins[0].Email != "a***@homework.com" {
+		t.Errorf("切片应逐个处理, got %q", admins[0].Email)
+	}
+}
+
+func TestMaskString(t *testing.T) {
+	cases := map[string]string{
+		"":              "",
+		"ab":            "**",
+		"alice":         "a***e",
+		"13800138000":   "138****8000",
+		"bob@gmail.com": "b***@gmail.com",
+		"张三丰":           "张***丰",
+	}
+	for input, want := range cases {
+		if got := MaskString(input); got != want {
+			t.Errorf("MaskString(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
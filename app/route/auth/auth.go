@@ -2,20 +2,30 @@ package auth
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"log"
+	"log/slog"
+	"math"
 	"net/http"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	gojwt "github.com/golang-jwt/jwt/v4"
 	core_model "github.com/maxlcoder/homework-backend/app/modules/core/model"
+	core_service "github.com/maxlcoder/homework-backend/app/modules/core/service"
+	base_response "github.com/maxlcoder/homework-backend/app/response"
+	"github.com/maxlcoder/homework-backend/config"
 	"github.com/maxlcoder/homework-backend/database"
 	"github.com/maxlcoder/homework-backend/model"
+	"github.com/maxlcoder/homework-backend/pkg/datascope"
 	"github.com/maxlcoder/homework-backend/pkg/response"
+	"github.com/maxlcoder/homework-backend/pkg/tenant"
 	"github.com/maxlcoder/homework-backend/repository"
-	"github.com/spf13/viper"
 	"gorm.io/gorm"
 )
 
@@ -24,10 +34,51 @@ type lgoin struct {
 	Password string `form:"password" json:"password" binding:"required"`
 }
 
+type refreshRequest struct {
+	RefreshToken string `form:"refresh_token" json:"refresh_token" binding:"required"`
+}
+
 var (
 	identityKey = "id"
 )
 
+// 需修改密码时仍可访问的路由
+var passwordChangeAllowedPaths = map[string]bool{
+	"/admin/me":          true,
+	"/admin/me/password": true,
+	"/admin/logout":      true,
+}
+
+// 默认有效期
+const (
+	defaultTimeout        = time.Hour
+	defaultRefreshTimeout = 30 * 24 * time.Hour
+)
+
+// 登录会话，sid 作为刷新令牌家族 ID 写入访问令牌，便于退出时整体吊销
+// 管理员的当前角色与租户同样写入令牌，权限校验时无需再查库
+type loginSession struct {
+	Identity model.Authenticatable
+	Sid      string
+	RoleId   uint
+	TenantId uint
+}
+
+func newLoginSession(identity model.Authenticatable, sid string) (*loginSession, error) {
+	session := &loginSession{Identity: identity, Sid: sid}
+	if admin, ok := identity.(*core_model.Admin); ok {
+		role, err := core_service.NewAdminService(database.DB).ActiveRole(admin)
+		if err != nil {
+			return nil, err
+		}
+		if role != nil {
+			session.RoleId = role.ID
+			session.TenantId = role.TenantID
+		}
+	}
+	return session, nil
+}
+
 func InitMiddleware(authMiddleware *jwt.GinJWTMiddleware) {
 	errInit := authMiddleware.MiddlewareInit()
 	if errInit != nil {
@@ -36,12 +87,16 @@ func InitMiddleware(authMiddleware *jwt.GinJWTMiddleware) {
 }
 
 func InitJwtParams() *jwt.GinJWTMiddleware {
-
-	return &jwt.GinJWTMiddleware{
+	jwtConfig := config.GetConfig().Jwt
+	keyring, err := NewKeyring(jwtConfig.Keys, jwtConfig.Key)
+	if err != nil {
+		log.Fatal("JWT keyring Error:" + err.Error())
+	}
+	return registerKeyring(&jwt.GinJWTMiddleware{
 		Realm:       "Homework",
-		Key:         []byte("secret key"),
-		Timeout:     time.Hour,
-		MaxRefresh:  time.Hour,
+		KeyFunc:     keyring.KeyFunc,
+		Timeout:     durationOrDefault(jwtConfig.Timeout, defaultTimeout),
+		MaxRefresh:  durationOrDefault(jwtConfig.MaxRefresh, defaultTimeout),
 		IdentityKey: identityKey,
 		PayloadFunc: payloadFunc[core_model.User, *core_model.User](),
 
@@ -54,20 +109,20 @@ func InitJwtParams() *jwt.GinJWTMiddleware {
 		TimeFunc:        time.Now,
 
 		LoginResponse: loginResponse(),
-	}
+	}, keyring)
 }
 
 func InitAdminJwtParams() *jwt.GinJWTMiddleware {
-	// 获取 jwt 过期时间配置
-	timeout := viper.GetDuration("jwt.timeout")
-	if timeout == 0 {
-		timeout = time.Hour
+	jwtConfig := config.GetConfig().Jwt
+	keyring, err := NewKeyring(jwtConfig.AdminKeys, jwtConfig.AdminKey)
+	if err != nil {
+		log.Fatal("JWT keyring Error:" + err.Error())
 	}
-	return &jwt.GinJWTMiddleware{
+	return registerKeyring(&jwt.GinJWTMiddleware{
 		Realm:       "Homework",
-		Key:         []byte("secret key"),
-		Timeout:     timeout,
-		MaxRefresh:  time.Hour,
+		KeyFunc:     keyring.KeyFunc,
+		Timeout:     durationOrDefault(jwtConfig.Timeout, defaultTimeout),
+		MaxRefresh:  durationOrDefault(jwtConfig.MaxRefresh, defaultTimeout),
 		IdentityKey: identityKey,
 		PayloadFunc: payloadFunc[core_model.Admin, *core_model.Admin](),
 
@@ -79,7 +134,111 @@ func InitAdminJwtParams() *jwt.GinJWTMiddleware {
 		TimeFunc:        time.Now,
 
 		LoginResponse: loginResponse(),
+	}, keyring)
+}
+
+// 中间件与密钥环的对应关系，gin-jwt 自身签发的令牌不带 kid，签发统一走密钥环
+var (
+	keyrings   = make(map[*jwt.GinJWTMiddleware]*Keyring)
+	keyringsMu sync.RWMutex
+)
+
+func registerKeyring(mw *jwt.GinJWTMiddleware, keyring *Keyring) *jwt.GinJWTMiddleware {
+	keyringsMu.Lock()
+	defer keyringsMu.Unlock()
+	keyrings[mw] = keyring
+	return mw
+}
+
+func keyringOf(mw *jwt.GinJWTMiddleware) *Keyring {
+	keyringsMu.RLock()
+	defer keyringsMu.RUnlock()
+	return keyrings[mw]
+}
+
+// 按 gin-jwt 的负载规则生成访问令牌，使用密钥环签名
+func generateToken(mw *jwt.GinJWTMiddleware, data interface{}) (string, time.Time, error) {
+	claims := gojwt.MapClaims{}
+	if mw.PayloadFunc != nil {
+		for key, value := range mw.PayloadFunc(data) {
+			claims[key] = value
+		}
+	}
+	now := mw.TimeFunc()
+	expire := now.Add(mw.TimeoutFunc(claims))
+	claims[mw.ExpField] = expire.Unix()
+	claims["orig_iat"] = now.Unix()
+	token, err := keyringOf(mw).Sign(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expire, nil
+}
+
+// LoginHandler 登录，替代 gin-jwt 的 LoginHandler 以便令牌携带 kid
+func LoginHandler(mw *jwt.GinJWTMiddleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		data, err := mw.Authenticator(c)
+		if err != nil {
+			respondLoginError(c, mw, err)
+			return
+		}
+		// 启用两步验证的管理员先返回待验证令牌
+		if mfaChallenge(c, mw, data) {
+			return
+		}
+		token, expire, err := generateToken(mw, data)
+		if err != nil {
+			mw.Unauthorized(c, http.StatusUnauthorized, mw.HTTPStatusMessageFunc(jwt.ErrFailedTokenCreation, c))
+			return
+		}
+		mw.LoginResponse(c, http.StatusOK, token, expire)
+	}
+}
+
+// 登录失败响应：退避返回 429，锁定返回 403
+func respondLoginError(c *gin.Context, mw *jwt.GinJWTMiddleware, err error) {
+	var throttled *core_service.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+		response.Error(c, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, core_service.ErrAccountLocked),
+		errors.Is(err, core_service.ErrTenantSuspended),
+		errors.Is(err, core_service.ErrTenantArchived):
+		response.Forbidden(c, err.Error())
+	default:
+		mw.Unauthorized(c, http.StatusUnauthorized, mw.HTTPStatusMessageFunc(err, c))
+	}
+}
+
+// 登录会话创建失败：租户不可用返回 403，其余为服务端错误
+func respondSessionError(c *gin.Context, err error) {
+	if errors.Is(err, core_service.ErrTenantSuspended) || errors.Is(err, core_service.ErrTenantArchived) {
+		response.Forbidden(c, err.Error())
+		return
 	}
+	response.InternalServerError(c, err.Error())
+}
+
+// JWKSHandler 公开非对称签名公钥，供其他服务验签
+func JWKSHandler(mw *jwt.GinJWTMiddleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"keys": keyringOf(mw).JWKS(),
+		})
+	}
+}
+
+func durationOrDefault(d time.Duration, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
+
+func refreshTimeout() time.Duration {
+	return durationOrDefault(config.GetConfig().Jwt.RefreshTimeout, defaultRefreshTimeout)
 }
 
 // 负载函数
@@ -88,12 +247,23 @@ func payloadFunc[T any, PT interface {
 	model.Authenticatable
 }]() func(data interface{}) jwt.MapClaims {
 	return func(data interface{}) jwt.MapClaims {
-		if v, ok := data.(PT); ok {
+		session, ok := data.(*loginSession)
+		if !ok {
+			return jwt.MapClaims{}
+		}
+		if v, ok := session.Identity.(PT); ok {
 			userType := reflect.TypeOf(new(T)).Elem().Name()
-			return jwt.MapClaims{
+			claims := jwt.MapClaims{
 				identityKey: v.GetId(), // 取用户表主键作为唯一标志
 				"user_type": userType,
+				"jti":       newRandomToken(16), // 访问令牌 ID，用于吊销
+				"sid":       session.Sid,
+			}
+			if userType == "Admin" {
+				claims["role_id"] = session.RoleId
+				claims["tenant_id"] = session.TenantId
 			}
+			return claims
 		}
 		return jwt.MapClaims{}
 	}
@@ -110,16 +280,49 @@ func authenticator[T any, PT interface {
 		}
 		userID := loginVals.Name
 		password := loginVals.Password
+
+		// 防爆破：账号与 IP 退避检查
+		guard := core_service.NewLoginGuardService(database.DB)
+		attempt := core_service.LoginAttemptInfo{
+			UserType: reflect.TypeOf(new(T)).Elem().Name(),
+			Name:     userID,
+			Ip:       c.ClientIP(),
+		}
+		if err := guard.Check(attempt); err != nil {
+			return nil, err
+		}
+
 		query := database.DB.Model(new(T)).Where("name = ?", userID)
 		user, err := repository.First[T, PT](query)
 		if err != nil {
+			guard.Fail(attempt)
 			return nil, jwt.ErrFailedAuthentication
 		}
+		attempt.UserId = user.GetId()
+		if guard.IsLocked(attempt.UserType, attempt.UserId) {
+			return nil, core_service.ErrAccountLocked
+		}
 
 		if model.CheckPasswordHash(password, user.GetPassword()) {
-			return user, nil
+			guard.Succeed(attempt)
+			// 旧算法或低强度参数的哈希自动升级
+			if model.PasswordNeedsRehash(user.GetPassword()) {
+				if hash, err := model.HashPassword(password); err == nil {
+					database.DB.Model(user).Update("password", hash)
+				}
+			}
+			session, err := newLoginSession(user, newRandomToken(16))
+			if err != nil {
+				return nil, err
+			}
+			// 登录响应中签发刷新令牌
+			c.Set("login_session", session)
+			return session, nil
 		}
 
+		if locked, _ := guard.Fail(attempt); locked {
+			return nil, core_service.ErrAccountLocked
+		}
 		return nil, jwt.ErrFailedAuthentication
 	}
 }
@@ -127,6 +330,9 @@ func authenticator[T any, PT interface {
 func authorizator[T core_model.User | core_model.Admin]() func(data interface{}, c *gin.Context) bool {
 	// 用户类型传入
 	return func(data interface{}, c *gin.Context) bool {
+		if data == nil {
+			return false
+		}
 		tType := reflect.TypeOf(new(T)).Elem().Name()
 		dataType := reflect.TypeOf(data).Elem().Name()
 		if tType != dataType {
@@ -142,19 +348,168 @@ func authorizator[T core_model.User | core_model.Admin]() func(data interface{},
 
 func unauthorized() func(c *gin.Context, code int, message string) {
 	return func(c *gin.Context, code int, message string) {
+		// identityHandler 中已经输出过响应
+		if c.Writer.Written() {
+			return
+		}
 		response.Error(c, code, "未授权："+message)
 	}
 }
 
 func loginResponse() func(c *gin.Context, code int, message string, time time.Time) {
-	return func(c *gin.Context, code int, message string, time time.Time) {
-		response.Success(c, gin.H{
-			"expired": time.Format("2006-01-02 15:04:05"),
+	return func(c *gin.Context, code int, message string, expire time.Time) {
+		data := gin.H{
+			"expired": expire.Format("2006-01-02 15:04:05"),
 			"token":   message,
+		}
+		if value, ok := c.Get("login_session"); ok {
+			session := value.(*loginSession)
+			userType := reflect.TypeOf(session.Identity).Elem().Name()
+			refreshToken, refreshExpire, err := IssueRefreshToken(nil, userType, session.Identity.GetId(), session.Sid, refreshTimeout())
+			if err != nil {
+				response.Error(c, http.StatusInternalServerError, "刷新令牌签发失败")
+				return
+			}
+			data["refresh_token"] = refreshToken
+			data["refresh_expired"] = refreshExpire.Format("2006-01-02 15:04:05")
+
+			// 需修改密码（初始密码或已过期）时提示前端跳转修改密码
+			switch identity := session.Identity.(type) {
+			case *core_model.Admin:
+				data["password_change_required"] = core_service.PasswordChangeRequired(identity.MustChangePassword, identity.PasswordChangedAt)
+			case *core_model.User:
+				data["password_change_required"] = core_service.PasswordChangeRequired(identity.MustChangePassword, identity.PasswordChangedAt)
+			}
+		}
+		// 首次绑定两步验证时返回恢复码，仅展示一次
+		if codes, ok := c.Get("recovery_codes"); ok {
+			data["recovery_codes"] = codes
+		}
+		response.Success(c, data)
+	}
+}
+
+// RefreshHandler 刷新令牌换取新的访问令牌与刷新令牌（轮换）
+func RefreshHandler[T any, PT interface {
+	*T
+	model.Authenticatable
+}](mw *jwt.GinJWTMiddleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req refreshRequest
+		if err := c.ShouldBind(&req); err != nil {
+			response.BadRequest(c, "缺少刷新令牌")
+			return
+		}
+		record, refreshToken, refreshExpire, err := RotateRefreshToken(req.RefreshToken, refreshTimeout())
+		if err != nil {
+			response.Unauthorized(c, err.Error())
+			return
+		}
+		userType := reflect.TypeOf(new(T)).Elem().Name()
+		if record.UserType != userType {
+			response.Unauthorized(c, ErrInvalidRefreshToken.Error())
+			return
+		}
+		user, err := repository.First[T, PT](database.DB.Model(new(T)).Where("id = ?", record.UserId))
+		if err != nil {
+			_ = RevokeRefreshTokenFamily(record.FamilyId)
+			response.Unauthorized(c, "当前用户信息异常")
+			return
+		}
+		session, err := newLoginSession(user, record.FamilyId)
+		if err != nil {
+			respondSessionError(c, err)
+			return
+		}
+		token, expire, err := generateToken(mw, session)
+		if err != nil {
+			response.InternalServerError(c, "令牌签发失败")
+			return
+		}
+		response.Success(c, gin.H{
+			"expired":         expire.Format("2006-01-02 15:04:05"),
+			"token":           token,
+			"refresh_token":   refreshToken,
+			"refresh_expired": refreshExpire.Format("2006-01-02 15:04:05"),
+		})
+	}
+}
+
+// SwitchRoleHandler 切换当前角色，签发携带新角色的访问令牌并吊销当前令牌
+func SwitchRoleHandler(mw *jwt.GinJWTMiddleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roleId, ok := switchRoleId(c.Param("id"))
+		if !ok {
+			response.BadRequest(c, "无效的角色ID")
+			return
+		}
+		adminId := c.GetUint("login_admin_id")
+		role, err := core_service.NewAdminService(database.DB).SwitchRole(adminId, roleId)
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		admin, err := repository.First[core_model.Admin](database.DB.Model(&core_model.Admin{}).Where("id = ?", adminId))
+		if err != nil {
+			response.Unauthorized(c, "当前用户信息异常")
+			return
+		}
+
+		claims := jwt.ExtractClaims(c)
+		sid, _ := claims["sid"].(string)
+		token, expire, err := generateToken(mw, &loginSession{Identity: admin, Sid: sid, RoleId: role.ID, TenantId: role.TenantID})
+		if err != nil {
+			response.InternalServerError(c, "令牌签发失败")
+			return
+		}
+		jti, _ := claims["jti"].(string)
+		if exp, ok := claims["exp"].(float64); ok {
+			_ = RevokeAccessToken(jti, time.Unix(int64(exp), 0))
+		}
+		response.Success(c, gin.H{
+			"expired":   expire.Format("2006-01-02 15:04:05"),
+			"token":     token,
+			"role_id":   role.ID,
+			"tenant_id": role.TenantID,
 		})
 	}
 }
 
+// 切换角色路由为 me/roles/:id:switch，gin 的路径参数不支持同一段内带后缀，注册为 me/roles/:id 后在此解析
+func switchRoleId(param string) (uint, bool) {
+	id, ok := strings.CutSuffix(param, ":switch")
+	if !ok {
+		return 0, false
+	}
+	roleId, err := strconv.ParseUint(id, 10, 32)
+	if err != nil || roleId == 0 {
+		return 0, false
+	}
+	return uint(roleId), true
+}
+
+// LogoutHandler 退出登录：吊销当前访问令牌以及同一会话（sid）的全部刷新令牌，会话下已签发的其他访问令牌随之失效
+func LogoutHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := jwt.ExtractClaims(c)
+		jti, _ := claims["jti"].(string)
+		sid, _ := claims["sid"].(string)
+		expire := time.Now().Add(defaultTimeout)
+		if exp, ok := claims["exp"].(float64); ok {
+			expire = time.Unix(int64(exp), 0)
+		}
+		if err := RevokeAccessToken(jti, expire); err != nil {
+			response.InternalServerError(c, "退出登录失败")
+			return
+		}
+		if err := RevokeRefreshTokenFamily(sid); err != nil {
+			response.InternalServerError(c, "退出登录失败")
+			return
+		}
+		response.Success(c, nil)
+	}
+}
+
 func identityHandler() func(c *gin.Context) interface{} {
 	return func(c *gin.Context) interface{} {
 		claims := jwt.ExtractClaims(c)
@@ -164,8 +519,24 @@ func identityHandler() func(c *gin.Context) interface{} {
 			response.Error(c, http.StatusUnauthorized, "无效的用户 ID")
 			return nil
 		}
+		// 吊销检查：访问令牌与所属登录会话，查询失败时拒绝
+		jti, _ := claims["jti"].(string)
+		sid, _ := claims["sid"].(string)
+		if jti == "" || sid == "" {
+			response.Error(c, http.StatusUnauthorized, "登录已失效，请重新登录")
+			return nil
+		}
+		revoked, err := IsAccessTokenRevoked(jti, sid)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "access token revocation check failed", "error", err)
+			response.Error(c, http.StatusUnauthorized, "登录状态校验失败，请稍后重试")
+			return nil
+		}
+		if revoked {
+			response.Error(c, http.StatusUnauthorized, "登录已失效，请重新登录")
+			return nil
+		}
 		userType := claims["user_type"]
-		fmt.Println(userType)
 		userId := uint(userIdFloat)
 		switch userType {
 		case "User":
@@ -184,20 +555,49 @@ func identityHandler() func(c *gin.Context) interface{} {
 				response.Error(c, http.StatusUnauthorized, "当前用户信息异常")
 				return nil
 			}
-			c.Set("login_admin_role_id", admin.RoleId)
-			// 获取管理员角色
-			if admin.RoleId > 0 {
-				role, error := gorm.G[core_model.Role](database.DB).Where("id = ?", admin.RoleId).First(ctx)
-				if error != nil {
-					response.Error(c, http.StatusUnauthorized, "当前用户角色信息异常")
-					return nil
-				}
-				c.Set("login_admin_role", role)
+			// 需修改密码时只允许修改密码与退出登录
+			if core_service.PasswordChangeRequired(admin.MustChangePassword, admin.PasswordChangedAt) && !passwordChangeAllowedPaths[c.FullPath()] {
+				response.Forbidden(c, "请先修改密码")
+				return nil
+			}
+			// 当前角色与租户取自令牌，切换角色时重新签发
+			roleId, ok := claims["role_id"].(float64)
+			if !ok {
+				response.Error(c, http.StatusUnauthorized, "登录已失效，请重新登录")
+				return nil
+			}
+			tenantId, _ := claims["tenant_id"].(float64)
+			// 租户停用或归档后已签发的令牌立即失效
+			if err := core_service.CheckTenantActive(database.DB, uint(tenantId)); err != nil {
+				response.Forbidden(c, err.Error())
+				return nil
+			}
+			// 角色及其上级角色属于令牌中的租户
+			roleDB := database.DB.WithContext(tenant.WithTenant(c.Request.Context(), uint(tenantId)))
+			scope, err := core_service.RoleDataScope(roleDB, uint(roleId), uint(tenantId))
+			if err != nil {
+				response.Error(c, http.StatusUnauthorized, "当前角色信息异常")
+				return nil
 			}
-			role, exists := c.Get("login_admin_role")
-			if exists {
-				fmt.Println("login_admin_role:", role)
+			fields, err := core_service.RoleFields(roleDB, uint(roleId))
+			if err != nil {
+				response.InternalServerError(c, err.Error())
+				return nil
 			}
+			c.Set("login_admin_role_id", uint(roleId))
+			c.Set("login_admin_tenant_id", uint(tenantId))
+			// 字段权限，响应中未授权的敏感字段打码或置空
+			c.Set("login_admin_fields", base_response.NewFieldGrants(fields...))
+			// 租户写入请求 context，使用该 context 的租户模型读写自动隔离
+			ctx = tenant.WithTenant(c.Request.Context(), uint(tenantId))
+			// 操作人与角色数据权限写入请求 context，开启数据权限的查询按其范围过滤
+			ctx = datascope.WithOperator(ctx, datascope.Operator{
+				AdminId:      userId,
+				DepartmentId: admin.DepartmentId,
+				TenantId:     uint(tenantId),
+				Scope:        scope,
+			})
+			c.Request = c.Request.WithContext(ctx)
 			admin.ID = userId
 			return &admin
 		}
@@ -0,0 +1,261 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v4"
+	"github.com/maxlcoder/homework-backend/config"
+)
+
+// 兼容单密钥配置时使用的 kid，未携带 kid 的令牌也按此查找
+const legacyKeyId = "default"
+
+var (
+	ErrNoSigningKey  = errors.New("没有可用的签名密钥")
+	ErrUnknownKeyId  = errors.New("未知的密钥 ID")
+	ErrKeyRetired    = errors.New("签名密钥已退役")
+	ErrAlgorithmDiff = errors.New("令牌签名算法与密钥不一致")
+)
+
+type signingKey struct {
+	ID        string
+	Method    gojwt.SigningMethod
+	CreatedAt time.Time
+	RetireAt  time.Time
+	signKey   interface{} // HS 为 []byte，RS/ES 为私钥，仅验签时为空
+	verifyKey interface{} // HS 为 []byte，RS/ES 为公钥
+}
+
+func (k *signingKey) retired(now time.Time) bool {
+	return !k.RetireAt.IsZero() && !now.Before(k.RetireAt)
+}
+
+// Keyring 密钥环，按启用时间排序，最新启用的密钥签名
+type Keyring struct {
+	mu   sync.RWMutex
+	keys []*signingKey
+}
+
+// NewKeyring 根据配置创建密钥环，legacySecret 为兼容的单个 HS256 密钥
+func NewKeyring(keys []config.JwtKey, legacySecret string) (*Keyring, error) {
+	ring := &Keyring{}
+	if legacySecret != "" {
+		ring.keys = append(ring.keys, &signingKey{
+			ID:        legacyKeyId,
+			Method:    gojwt.SigningMethodHS256,
+			signKey:   []byte(legacySecret),
+			verifyKey: []byte(legacySecret),
+		})
+	}
+	ids := map[string]bool{legacyKeyId: legacySecret != ""}
+	for _, keyConfig := range keys {
+		key, err := parseSigningKey(keyConfig)
+		if err != nil {
+			return nil, fmt.Errorf("密钥 %s 配置错误: %w", keyConfig.ID, err)
+		}
+		if ids[key.ID] {
+			return nil, fmt.Errorf("密钥 ID %s 重复", key.ID)
+		}
+		ids[key.ID] = true
+		ring.keys = append(ring.keys, key)
+	}
+	if len(ring.keys) == 0 {
+		return nil, ErrNoSigningKey
+	}
+	sort.SliceStable(ring.keys, func(i, j int) bool {
+		return ring.keys[i].CreatedAt.Before(ring.keys[j].CreatedAt)
+	})
+	if _, err := ring.signingKey(); err != nil {
+		return nil, err
+	}
+	return ring, nil
+}
+
+func parseSigningKey(keyConfig config.JwtKey) (*signingKey, error) {
+	if keyConfig.ID == "" {
+		return nil, errors.New("缺少 id")
+	}
+	key := &signingKey{ID: keyConfig.ID}
+	var err error
+	if keyConfig.CreatedAt != "" {
+		if key.CreatedAt, err = time.Parse(time.RFC3339, keyConfig.CreatedAt); err != nil {
+			return nil, fmt.Errorf("created_at 格式错误: %w", err)
+		}
+	}
+	if keyConfig.RetireAt != "" {
+		if key.RetireAt, err = time.Parse(time.RFC3339, keyConfig.RetireAt); err != nil {
+			return nil, fmt.Errorf("retire_at 格式错误: %w", err)
+		}
+	}
+
+	privatePem, err := readKeyMaterial(keyConfig.PrivateKey, keyConfig.PrivateKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	publicPem, err := readKeyMaterial(keyConfig.PublicKey, keyConfig.PublicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	switch keyConfig.Algorithm {
+	case "", "HS256":
+		if keyConfig.Secret == "" {
+			return nil, errors.New("HS256 缺少 secret")
+		}
+		key.Method = gojwt.SigningMethodHS256
+		key.signKey = []byte(keyConfig.Secret)
+		key.verifyKey = []byte(keyConfig.Secret)
+	case "RS256":
+		key.Method = gojwt.SigningMethodRS256
+		if privatePem != nil {
+			privateKey, err := gojwt.ParseRSAPrivateKeyFromPEM(privatePem)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey = privateKey
+			key.verifyKey = &privateKey.PublicKey
+		} else if publicPem != nil {
+			if key.verifyKey, err = gojwt.ParseRSAPublicKeyFromPEM(publicPem); err != nil {
+				return nil, err
+			}
+		}
+	case "ES256":
+		key.Method = gojwt.SigningMethodES256
+		if privatePem != nil {
+			privateKey, err := gojwt.ParseECPrivateKeyFromPEM(privatePem)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey = privateKey
+			key.verifyKey = &privateKey.PublicKey
+		} else if publicPem != nil {
+			if key.verifyKey, err = gojwt.ParseECPublicKeyFromPEM(publicPem); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("不支持的签名算法 %s", keyConfig.Algorithm)
+	}
+	if key.verifyKey == nil {
+		return nil, errors.New("缺少密钥")
+	}
+	return key, nil
+}
+
+func readKeyMaterial(inline string, file string) ([]byte, error) {
+	if inline != "" {
+		return []byte(inline), nil
+	}
+	if file != "" {
+		return os.ReadFile(file)
+	}
+	return nil, nil
+}
+
+// 当前签名密钥：已启用、未退役且持有私钥的最新密钥
+func (k *Keyring) signingKey() (*signingKey, error) {
+	now := time.Now()
+	for i := len(k.keys) - 1; i >= 0; i-- {
+		key := k.keys[i]
+		if key.signKey == nil || key.retired(now) || key.CreatedAt.After(now) {
+			continue
+		}
+		return key, nil
+	}
+	return nil, ErrNoSigningKey
+}
+
+// Sign 使用当前签名密钥签发令牌，令牌头写入 kid
+func (k *Keyring) Sign(claims gojwt.MapClaims) (string, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, err := k.signingKey()
+	if err != nil {
+		return "", err
+	}
+	token := gojwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.signKey)
+}
+
+// KeyFunc 按令牌头 kid 查找验签密钥，供 gin-jwt 解析令牌使用
+func (k *Keyring) KeyFunc(token *gojwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = legacyKeyId
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, key := range k.keys {
+		if key.ID != kid {
+			continue
+		}
+		if key.retired(time.Now()) {
+			return nil, ErrKeyRetired
+		}
+		// 防止算法混淆攻击，令牌算法必须与密钥一致
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, ErrAlgorithmDiff
+		}
+		return key.verifyKey, nil
+	}
+	return nil, ErrUnknownKeyId
+}
+
+// JWK 公钥描述（RFC 7517）
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS 导出全部未退役的非对称公钥，HS 密钥不对外暴露
+func (k *Keyring) JWKS() []JWK {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	now := time.Now()
+	jwks := make([]JWK, 0, len(k.keys))
+	for _, key := range k.keys {
+		if key.retired(now) {
+			continue
+		}
+		switch publicKey := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case *ecdsa.PublicKey:
+			size := (publicKey.Curve.Params().BitSize + 7) / 8
+			jwks = append(jwks, JWK{
+				Kty: "EC",
+				Kid: key.ID,
+				Alg: key.Method.Alg(),
+				Use: "sig",
+				Crv: publicKey.Curve.Params().Name,
+				X:   base64.RawURLEncoding.EncodeToString(publicKey.X.FillBytes(make([]byte, size))),
+				Y:   base64.RawURLEncoding.EncodeToString(publicKey.Y.FillBytes(make([]byte, size))),
+			})
+		}
+	}
+	return jwks
+}
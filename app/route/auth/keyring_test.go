@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	gojwt "github.com/golang-jwt/jwt/v4"
+	"github.com/maxlcoder/homework-backend/config"
+)
+
+func ecPrivateKeyPem(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+}
+
+func parseWith(ring *Keyring, token string) error {
+	_, err := gojwt.Parse(token, ring.KeyFunc)
+	return err
+}
+
+func TestKeyringSignVerify(t *testing.T) {
+	ring, err := NewKeyring([]config.JwtKey{
+		{ID: "es-2025", Algorithm: "ES256", PrivateKey: ecPrivateKeyPem(t), CreatedAt: "2025-01-01T00:00:00Z"},
+		{ID: "es-2026", Algorithm: "ES256", PrivateKey: ecPrivateKeyPem(t), CreatedAt: "2026-01-01T00:00:00Z"},
+	}, "legacy-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := ring.Sign(gojwt.MapClaims{"id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := gojwt.Parse(token, ring.KeyFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Header["kid"] != "es-2026" {
+		t.Fatalf("应使用最新启用的密钥签名, got %v", parsed.Header["kid"])
+	}
+
+	// 轮换前签发的令牌仍可验签
+	old := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{"id": 1})
+	legacy, err := old.SignedString([]byte("legacy-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parseWith(ring, legacy); err != nil {
+		t.Fatalf("无 kid 的令牌按兼容密钥验签, got %v", err)
+	}
+}
+
+func TestKeyringRejectsUnknownKid(t *testing.T) {
+	ring, err := NewKeyring(nil, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{"id": 1})
+	token.Header["kid"] = "missing"
+	signed, err := token.SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parseWith(ring, signed); !errors.Is(err, ErrUnknownKeyId) {
+		t.Fatalf("未知 kid 应拒绝, got %v", err)
+	}
+}
+
+func TestKeyringRejectsAlgorithmMismatch(t *testing.T) {
+	ring, err := NewKeyring([]config.JwtKey{
+		{ID: "es", Algorithm: "ES256", PrivateKey: ecPrivateKeyPem(t)},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 以 HS256 冒充 ES256 密钥签名（算法混淆）
+	token := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{"id": 1})
+	token.Header["kid"] = "es"
+	signed, err := token.SignedString([]byte("public-key-as-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parseWith(ring, signed); !errors.Is(err, ErrAlgorithmDiff) {
+		t.Fatalf("算法与密钥不一致应拒绝, got %v", err)
+	}
+}
+
+func TestKeyringRejectsRetiredKey(t *testing.T) {
+	retireAt := time.Now().Add(time.Hour).Format(time.RFC3339)
+	ring, err := NewKeyring([]config.JwtKey{
+		{ID: "old", Secret: "old-secret", CreatedAt: "2025-01-01T00:00:00Z", RetireAt: retireAt},
+		{ID: "new", Secret: "new-secret", CreatedAt: "2026-01-01T00:00:00Z"},
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := gojwt.NewWithClaims(gojwt.SigningMethodHS256, gojwt.MapClaims{"id": 1})
+	token.Header["kid"] = "old"
+	signed, err := token.SignedString([]byte("old-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parseWith(ring, signed); err != nil {
+		t.Fatalf("未到退役时间的密钥应可验签, got %v", err)
+	}
+	ring.keys[0].RetireAt = time.Now().Add(-time.Minute)
+	if err := parseWith(ring, signed); !errors.Is(err, ErrKeyRetired) {
+		t.Fatalf("退役密钥签发的令牌应拒绝, got %v", err)
+	}
+}
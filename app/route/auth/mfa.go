@@ -0,0 +1,233 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	gojwt "github.com/golang-jwt/jwt/v4"
+	core_model "github.com/maxlcoder/homework-backend/app/modules/core/model"
+	core_service "github.com/maxlcoder/homework-backend/app/modules/core/service"
+	"github.com/maxlcoder/homework-backend/database"
+	"github.com/maxlcoder/homework-backend/pkg/response"
+	"github.com/maxlcoder/homework-backend/repository"
+)
+
+// 两步验证待验证令牌类型，不含用户 ID，无法作为访问令牌使用
+const mfaPendingType = "mfa_pending"
+
+var ErrInvalidMfaToken = errors.New("两步验证令牌无效或已过期，请重新登录")
+
+type mfaTokenRequest struct {
+	MfaToken string `form:"mfa_token" json:"mfa_token" binding:"required"`
+}
+
+type mfaCodeRequest struct {
+	MfaToken string `form:"mfa_token" json:"mfa_token" binding:"required"`
+	Code     string `form:"code" json:"code" binding:"required"`
+}
+
+// 需要两步验证的管理员，密码校验通过后只返回短期的待验证令牌
+func mfaChallenge(c *gin.Context, mw *jwt.GinJWTMiddleware, data interface{}) bool {
+	session, ok := data.(*loginSession)
+	if !ok {
+		return false
+	}
+	admin, ok := session.Identity.(*core_model.Admin)
+	if !ok {
+		return false
+	}
+	required, err := core_service.NewAdminMfaService(database.DB).IsRequired(admin)
+	if err != nil {
+		response.InternalServerError(c, err.Error())
+		return true
+	}
+	if !required {
+		return false
+	}
+
+	expire := mw.TimeFunc().Add(core_service.MfaPendingTimeout())
+	token, err := keyringOf(mw).Sign(gojwt.MapClaims{
+		"typ":          mfaPendingType,
+		"mfa_admin_id": admin.ID,
+		"sid":          session.Sid,
+		"jti":          newRandomToken(16),
+		"exp":          expire.Unix(),
+	})
+	if err != nil {
+		response.InternalServerError(c, "令牌签发失败")
+		return true
+	}
+	response.Success(c, gin.H{
+		"mfa_required": true,
+		"mfa_enrolled": admin.MfaEnabled, // 未绑定时需先调用绑定接口
+		"mfa_token":    token,
+		"mfa_expired":  expire.Format("2006-01-02 15:04:05"),
+	})
+	return true
+}
+
+// 解析待验证令牌，返回对应管理员与登录会话 ID
+func parseMfaToken(mw *jwt.GinJWTMiddleware, tokenString string) (*core_model.Admin, gojwt.MapClaims, error) {
+	token, err := gojwt.Parse(tokenString, keyringOf(mw).KeyFunc)
+	if err != nil || !token.Valid {
+		return nil, nil, ErrInvalidMfaToken
+	}
+	claims, ok := token.Claims.(gojwt.MapClaims)
+	if !ok || claims["typ"] != mfaPendingType {
+		return nil, nil, ErrInvalidMfaToken
+	}
+	jti, _ := claims["jti"].(string)
+	sid, _ := claims["sid"].(string)
+	if jti == "" {
+		return nil, nil, ErrInvalidMfaToken
+	}
+	if revoked, err := IsAccessTokenRevoked(jti, sid); err != nil || revoked {
+		return nil, nil, ErrInvalidMfaToken
+	}
+	adminId, ok := claims["mfa_admin_id"].(float64)
+	if !ok {
+		return nil, nil, ErrInvalidMfaToken
+	}
+	admin, err := repository.First[core_model.Admin](database.DB.Model(&core_model.Admin{}).Where("id = ?", uint(adminId)))
+	if err != nil {
+		return nil, nil, ErrInvalidMfaToken
+	}
+	return admin, claims, nil
+}
+
+// 待验证令牌一次性使用
+func consumeMfaToken(claims gojwt.MapClaims) {
+	jti, _ := claims["jti"].(string)
+	expire := time.Now().Add(core_service.MfaPendingTimeout())
+	if exp, ok := claims["exp"].(float64); ok {
+		expire = time.Unix(int64(exp), 0)
+	}
+	_ = RevokeAccessToken(jti, expire)
+}
+
+// 两步验证通过，签发正式令牌
+func completeMfaLogin(c *gin.Context, mw *jwt.GinJWTMiddleware, admin *core_model.Admin, claims gojwt.MapClaims) {
+	consumeMfaToken(claims)
+	sid, _ := claims["sid"].(string)
+	session, err := newLoginSession(admin, sid)
+	if err != nil {
+		respondSessionError(c, err)
+		return
+	}
+	c.Set("login_session", session)
+	token, expire, err := generateToken(mw, session)
+	if err != nil {
+		mw.Unauthorized(c, http.StatusUnauthorized, mw.HTTPStatusMessageFunc(jwt.ErrFailedTokenCreation, c))
+		return
+	}
+	mw.LoginResponse(c, http.StatusOK, token, expire)
+}
+
+// 验证码错误计入登录失败次数，防止暴力猜测
+func mfaFailed(c *gin.Context, guard core_service.LoginGuardServiceInterface, attempt core_service.LoginAttemptInfo, err error) {
+	if !errors.Is(err, core_service.ErrInvalidMfaCode) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+	if locked, _ := guard.Fail(attempt); locked {
+		response.Forbidden(c, core_service.ErrAccountLocked.Error())
+		return
+	}
+	response.Unauthorized(c, err.Error())
+}
+
+func mfaAttempt(c *gin.Context, admin *core_model.Admin) core_service.LoginAttemptInfo {
+	return core_service.LoginAttemptInfo{
+		UserType: "Admin",
+		UserId:   admin.ID,
+		Name:     admin.Name,
+		Ip:       c.ClientIP(),
+	}
+}
+
+// MfaLoginHandler 提交 TOTP 验证码或恢复码完成登录
+func MfaLoginHandler(mw *jwt.GinJWTMiddleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req mfaCodeRequest
+		if err := c.ShouldBind(&req); err != nil {
+			response.BadRequest(c, "缺少两步验证令牌或验证码")
+			return
+		}
+		admin, claims, err := parseMfaToken(mw, req.MfaToken)
+		if err != nil {
+			response.Unauthorized(c, err.Error())
+			return
+		}
+		guard := core_service.NewLoginGuardService(database.DB)
+		attempt := mfaAttempt(c, admin)
+		if err := guard.Check(attempt); err != nil {
+			respondLoginError(c, mw, err)
+			return
+		}
+		if guard.IsLocked(attempt.UserType, attempt.UserId) {
+			response.Forbidden(c, core_service.ErrAccountLocked.Error())
+			return
+		}
+		if err := core_service.NewAdminMfaService(database.DB).Verify(admin, req.Code); err != nil {
+			mfaFailed(c, guard, attempt, err)
+			return
+		}
+		guard.Succeed(attempt)
+		completeMfaLogin(c, mw, admin, claims)
+	}
+}
+
+// MfaEnrollHandler 登录过程中绑定两步验证（租户强制要求但尚未绑定时）
+func MfaEnrollHandler(mw *jwt.GinJWTMiddleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req mfaTokenRequest
+		if err := c.ShouldBind(&req); err != nil {
+			response.BadRequest(c, "缺少两步验证令牌")
+			return
+		}
+		admin, _, err := parseMfaToken(mw, req.MfaToken)
+		if err != nil {
+			response.Unauthorized(c, err.Error())
+			return
+		}
+		enrollment, err := core_service.NewAdminMfaService(database.DB).Enroll(admin)
+		if err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.Success(c, enrollment)
+	}
+}
+
+// MfaConfirmHandler 确认绑定并完成登录，响应中返回恢复码
+func MfaConfirmHandler(mw *jwt.GinJWTMiddleware) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req mfaCodeRequest
+		if err := c.ShouldBind(&req); err != nil {
+			response.BadRequest(c, "缺少两步验证令牌或验证码")
+			return
+		}
+		admin, claims, err := parseMfaToken(mw, req.MfaToken)
+		if err != nil {
+			response.Unauthorized(c, err.Error())
+			return
+		}
+		guard := core_service.NewLoginGuardService(database.DB)
+		attempt := mfaAttempt(c, admin)
+		if err := guard.Check(attempt); err != nil {
+			respondLoginError(c, mw, err)
+			return
+		}
+		codes, err := core_service.NewAdminMfaService(database.DB).Confirm(admin, req.Code)
+		if err != nil {
+			mfaFailed(c, guard, attempt, err)
+			return
+		}
+		guard.Succeed(attempt)
+		c.Set("recovery_codes", codes)
+		completeMfaLogin(c, mw, admin, claims)
+	}
+}
@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	core_model "github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/database"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("刷新令牌无效")
+	ErrExpiredRefreshToken = errors.New("刷新令牌已过期")
+	// 已轮换过的刷新令牌被再次使用，视为泄露，整个家族吊销
+	ErrReusedRefreshToken = errors.New("刷新令牌已被使用，请重新登录")
+)
+
+// 随机令牌，base64url 编码
+func newRandomToken(size int) string {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueRefreshToken 为登录会话签发刷新令牌
+func IssueRefreshToken(tx *gorm.DB, userType string, userId uint, familyId string, ttl time.Duration) (string, time.Time, error) {
+	if tx == nil {
+		tx = database.DB
+	}
+	token := newRandomToken(32)
+	expiresAt := time.Now().Add(ttl)
+	err := tx.Create(&core_model.RefreshToken{
+		UserType:  userType,
+		UserId:    userId,
+		FamilyId:  familyId,
+		TokenHash: hashToken(token),
+		ExpiresAt: expiresAt,
+	}).Error
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// RotateRefreshToken 使用刷新令牌换取新的刷新令牌，旧令牌作废
+// 返回旧令牌记录（携带用户信息与家族 ID）以及新令牌
+func RotateRefreshToken(token string, ttl time.Duration) (*core_model.RefreshToken, string, time.Time, error) {
+	var record core_model.RefreshToken
+	var newToken string
+	var expiresAt time.Time
+	reused := false
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", hashToken(token)).
+			First(&record).Error
+		if err != nil {
+			return ErrInvalidRefreshToken
+		}
+		now := time.Now()
+		if record.UsedAt != nil || record.RevokedAt != nil {
+			// 令牌重放，吊销整个家族
+			reused = true
+			return nil
+		}
+		if record.ExpiresAt.Before(now) {
+			return ErrExpiredRefreshToken
+		}
+		if err := tx.Model(&record).Update("used_at", now).Error; err != nil {
+			return err
+		}
+		newToken, expiresAt, err = IssueRefreshToken(tx, record.UserType, record.UserId, record.FamilyId, ttl)
+		return err
+	})
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	if reused {
+		_ = RevokeRefreshTokenFamily(record.FamilyId)
+		return nil, "", time.Time{}, ErrReusedRefreshToken
+	}
+	return &record, newToken, expiresAt, nil
+}
+
+// RevokeRefreshTokenFamily 吊销登录会话下的全部刷新令牌
+func RevokeRefreshTokenFamily(familyId string) error {
+	if familyId == "" {
+		return nil
+	}
+	return database.DB.Model(&core_model.RefreshToken{}).
+		Where("family_id = ?", familyId).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAccessToken 将访问令牌加入吊销列表，直到其自然过期
+func RevokeAccessToken(jti string, expiresAt time.Time) error {
+	if jti == "" {
+		return nil
+	}
+	// 顺带清理已过期的吊销记录
+	database.DB.Where("expires_at < ?", time.Now()).Delete(&core_model.RevokedToken{})
+	return database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&core_model.RevokedToken{
+		Jti:       jti,
+		ExpiresAt: expiresAt,
+	}).Error
+}
+
+// IsAccessTokenRevoked 访问令牌是否已吊销：令牌本身已吊销，或所属登录会话（sid）已吊销（退出登录、刷新令牌重放、修改密码）
+// 查询失败时返回错误，调用方应按已吊销处理
+func IsAccessTokenRevoked(jti string, sid string) (bool, error) {
+	var count int64
+	if err := database.DB.Model(&core_model.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 || sid == "" {
+		return count > 0, nil
+	}
+	err := database.DB.Model(&core_model.RefreshToken{}).
+		Where("family_id = ?", sid).
+		Where("revoked_at IS NOT NULL").
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
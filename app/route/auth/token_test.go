@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	core_model "github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/database"
+	"gorm.io/gorm"
+)
+
+func useTestDB(t *testing.T) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&core_model.RefreshToken{}, &core_model.RevokedToken{}); err != nil {
+		t.Fatal(err)
+	}
+	previous := database.DB
+	database.DB = db
+	t.Cleanup(func() {
+		database.DB = previous
+	})
+}
+
+func TestIsAccessTokenRevoked(t *testing.T) {
+	useTestDB(t)
+	if _, _, err := IssueRefreshToken(nil, "Admin", 1, "sid-1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if revoked, err := IsAccessTokenRevoked("jti-1", "sid-1"); err != nil || revoked {
+		t.Fatalf("未吊销的令牌, got %v %v", revoked, err)
+	}
+
+	// 吊销单个访问令牌
+	if err := RevokeAccessToken("jti-1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if revoked, _ := IsAccessTokenRevoked("jti-1", "sid-1"); !revoked {
+		t.Fatal("已吊销的访问令牌应失效")
+	}
+
+	// 吊销会话后同一会话的其他访问令牌失效
+	if revoked, _ := IsAccessTokenRevoked("jti-2", "sid-1"); revoked {
+		t.Fatal("会话未吊销时其他访问令牌应有效")
+	}
+	if err := RevokeRefreshTokenFamily("sid-1"); err != nil {
+		t.Fatal(err)
+	}
+	if revoked, _ := IsAccessTokenRevoked("jti-2", "sid-1"); !revoked {
+		t.Fatal("会话吊销后其他访问令牌应失效")
+	}
+}
+
+func TestIsAccessTokenRevokedFailsClosed(t *testing.T) {
+	useTestDB(t)
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Close()
+	if _, err := IsAccessTokenRevoked("jti-1", "sid-1"); err == nil {
+		t.Fatal("查询失败时应返回错误")
+	}
+}
+
+func TestSwitchRoleId(t *testing.T) {
+	cases := map[string]uint{"3:switch": 3, "3": 0, "3:other": 0, "abc:switch": 0, "0:switch": 0, ":switch": 0}
+	for param, want := range cases {
+		got, ok := switchRoleId(param)
+		if got != want || ok != (want != 0) {
+			t.Errorf("switchRoleId(%q) = %d, %v, want %d", param, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package route
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/maxlcoder/homework-backend/app/contract"
+	"github.com/maxlcoder/homework-backend/config"
+	"github.com/maxlcoder/homework-backend/database"
+	"github.com/maxlcoder/homework-backend/kafka"
+	"github.com/maxlcoder/homework-backend/pkg/lifecycle"
+	"github.com/maxlcoder/homework-backend/pkg/response"
+)
+
+// 单个就绪检查的超时时间
+const readyCheckTimeout = 3 * time.Second
+
+// HealthCheckResult 单个依赖的检查结果
+type HealthCheckResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"` // up / down
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ReadyReport 就绪检查结果，任一依赖不可用即为 down
+type ReadyReport struct {
+	Status         string              `json:"status"`
+	ConfigLoadedAt time.Time           `json:"config_loaded_at"`
+	Checks         []HealthCheckResult `json:"checks"`
+}
+
+// HealthRoutes 注册存活与就绪检查接口，并注册数据库、kafka、配置等系统依赖的检查项，服务关闭开始后就绪检查返回不可用
+func HealthRoutes(r *gin.Engine) {
+	contract.RegisterHealthCheck("database", database.Ping)
+	if conf := config.GetConfig(); conf != nil && len(conf.Kafka.Brokers) > 0 {
+		contract.RegisterHealthCheck("kafka", kafka.Ping)
+	}
+	contract.RegisterHealthCheck("lifecycle", func(ctx context.Context) error {
+		if lifecycle.ShuttingDown() {
+			return errors.New("服务正在关闭")
+		}
+		return nil
+	})
+	contract.RegisterHealthCheck("config", func(ctx context.Context) error {
+		if config.LoadedAt().IsZero() {
+			return errors.New("配置未加载")
+		}
+		return config.PingEtcd(ctx)
+	})
+
+	// 存活检查：进程可以处理请求即可，不检查依赖，避免依赖故障导致实例被反复重启
+	r.GET("/healthz", func(c *gin.Context) {
+		response.Success(c, gin.H{"status": "up"})
+	})
+	// 就绪检查：依赖均可用时返回 200，否则返回 503，负载均衡据此摘除实例
+	r.GET("/readyz", func(c *gin.Context) {
+		report := RunHealthChecks(c.Request.Context())
+		if report.Status != "up" {
+			c.JSON(http.StatusServiceUnavailable, response.Response{
+				Code: http.StatusServiceUnavailable,
+				Msg:  "not ready",
+				Data: report,
+			})
+			return
+		}
+		response.Success(c, report)
+	})
+}
+
+// RunHealthChecks 并发执行全部检查项
+func RunHealthChecks(ctx context.Context) ReadyReport {
+	checks := contract.GetAllHealthChecks()
+	results := make([]HealthCheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check contract.HealthCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, readyCheckTimeout)
+			defer cancel()
+			start := time.Now()
+			err := check.Check(checkCtx)
+			result := HealthCheckResult{
+				Name:       check.Name,
+				Status:     "up",
+				DurationMs: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				result.Status = "down"
+				result.Error = err.Error()
+			}
+			results[i] = result
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := ReadyReport{
+		Status:         "up",
+		ConfigLoadedAt: config.LoadedAt(),
+		Checks:         results,
+	}
+	for _, result := range results {
+		if result.Status != "up" {
+			report.Status = "down"
+			break
+		}
+	}
+	return report
+}
@@ -95,6 +95,18 @@ func RegisterModuleByName(name string, module Module) {
 	if menuProvider, ok := module.(contract.MenuProvider); ok {
 		contract.RegisterMenuProvider(name, menuProvider)
 	}
+	// 同时注册健康检查提供者（如果模块实现了HealthChecker接口）
+	if healthChecker, ok := module.(contract.HealthChecker); ok {
+		contract.RegisterHealthChecker(name, healthChecker)
+	}
+	// 同时注册生命周期钩子（如果模块实现了Lifecycle接口）
+	if lifecycle, ok := module.(contract.Lifecycle); ok {
+		contract.RegisterLifecycle(name, lifecycle)
+	}
+	// 同时注册租户数据提供者（如果模块实现了TenantDataProvider接口）
+	if tenantData, ok := module.(contract.TenantDataProvider); ok {
+		contract.RegisterTenantDataProvider(name, tenantData)
+	}
 }
 
 // AutoRegisterModule 自动注册模块路由
@@ -8,20 +8,16 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/maxlcoder/homework-backend/app/middleware"
 	core_route "github.com/maxlcoder/homework-backend/app/modules/core/route"
+	homework_route "github.com/maxlcoder/homework-backend/app/modules/homework/route"
+	oms_route "github.com/maxlcoder/homework-backend/app/modules/oms/route"
 	wms_route "github.com/maxlcoder/homework-backend/app/modules/wms/route"
 	"github.com/maxlcoder/homework-backend/app/route/auth"
 	"github.com/maxlcoder/homework-backend/database"
 )
 
 // ApiRoutes 注册所有API路由
-func ApiRoutes(r *gin.Engine, enforcer *casbin.Enforcer) {
-	// 全局公用中间件 - 应用于所有路由
-	// CORS中间件
-	r.Use(middleware.Cors())
-	// 错误处理中间件
-	r.Use(middleware.ErrorHandler())
-	// 请求日志中间件
-	r.Use(middleware.Logger())
+func ApiRoutes(r *gin.Engine, enforcer *casbin.SyncedEnforcer) {
+	// 全局中间件（请求 ID、访问日志、CORS、错误处理等）由 bootstrap.NewRouter 统一注册，此处只注册路由组中间件
 
 	// auth 中间件 - 可作为模块级别的公用中间件
 	authMiddleware, err := jwt.New(auth.InitJwtParams())
@@ -43,6 +39,9 @@ func ApiRoutes(r *gin.Engine, enforcer *casbin.Enforcer) {
 	RegisterModuleByName("CoreModule", &core_route.CoreModule{DB: database.DB, Enforcer: enforcer, ApiHandler: authMiddleware, AdminHandler: adminAuthMiddleware})
 	// 注册WMS模块 - 它可以在自己的Middleware方法中定义特定的中间件（会自动注册菜单提供者）
 	RegisterModuleByName("WmsModule", &wms_route.WmsModule{DB: database.DB})
+	// 注册OMS、作业模块（目前只提供菜单与字段权限）
+	RegisterModuleByName("OmsModule", &oms_route.OmsModule{DB: database.DB})
+	RegisterModuleByName("HomeworkModule", &homework_route.HomeworkModule{DB: database.DB})
 	// ---------- 此部分注入各个模块 END ----------
 
 	// 可以创建不同的路由组，应用不同的公用中间件
@@ -59,6 +58,7 @@ func ApiRoutes(r *gin.Engine, enforcer *casbin.Enforcer) {
 	// 管理后台路由组可以应用管理员特定的中间件
 	adminAuthGroup.Use(adminAuthMiddleware.MiddlewareFunc())
 	// 系统整体中间件 - 管理后台组
+	adminAuthGroup.Use(middleware.AuditMiddleware(database.DB))
 	adminAuthGroup.Use(middleware.CasbinMiddleware(enforcer))
 
 	// 自动注册所有模块
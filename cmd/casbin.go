@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/maxlcoder/homework-backend/app/bootstrap"
+	"github.com/maxlcoder/homework-backend/database"
+)
+
+// casbin 规则导出与导入：go run ./cmd casbin dump [-o file] / go run ./cmd casbin import -f file [-replace]
+// 文件格式与 casbin 文件适配器相同，每行一条规则，如 p, role_2, 0, /admin/admins, GET
+func runCasbin(args []string) error {
+	flags, configFile := newFlagSet("casbin")
+	output := flags.String("o", "", "dump：导出文件，默认输出到标准输出")
+	input := flags.String("f", "", "import：导入文件")
+	replace := flags.Bool("replace", false, "import：清空现有规则后导入，默认只追加不存在的规则")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "用法：go run ./cmd casbin [参数] dump|import")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("需指定 dump / import")
+	}
+
+	if err := bootstrap.Init(*configFile); err != nil {
+		return err
+	}
+	enforcer, err := bootstrap.InitDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+
+	switch action := flags.Arg(0); action {
+	case "dump":
+		w := io.Writer(os.Stdout)
+		if *output != "" {
+			file, err := os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			w = file
+		}
+		return dumpCasbin(enforcer, w)
+	case "import":
+		if *input == "" {
+			return errors.New("需通过 -f 指定导入文件")
+		}
+		file, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		return importCasbin(enforcer, file, *replace)
+	default:
+		return fmt.Errorf("未知 casbin 操作：%s", action)
+	}
+}
+
+func dumpCasbin(enforcer *casbin.SyncedEnforcer, w io.Writer) error {
+	policies, err := enforcer.GetPolicy()
+	if err != nil {
+		return err
+	}
+	groupings, err := enforcer.GetGroupingPolicy()
+	if err != nil {
+		return err
+	}
+	for _, policy := range policies {
+		if _, err := fmt.Fprintln(w, strings.Join(append([]string{"p"}, policy...), ", ")); err != nil {
+			return err
+		}
+	}
+	for _, grouping := range groupings {
+		if _, err := fmt.Fprintln(w, strings.Join(append([]string{"g"}, grouping...), ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func importCasbin(enforcer *casbin.SyncedEnforcer, r io.Reader, replace bool) error {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("规则文件解析失败：%w", err)
+	}
+	var policies, groupings [][]string
+	for i, record := range records {
+		if len(record) < 2 {
+			return fmt.Errorf("第 %d 行规则不完整", i+1)
+		}
+		switch strings.TrimSpace(record[0]) {
+		case "p":
+			policies = append(policies, record[1:])
+		case "g":
+			groupings = append(groupings, record[1:])
+		default:
+			return fmt.Errorf("第 %d 行规则类型不支持：%s", i+1, record[0])
+		}
+	}
+
+	if replace {
+		// 在内存中替换后整体保存，SavePolicy 会清空规则表后重新写入
+		enforcer.EnableAutoSave(false)
+		enforcer.ClearPolicy()
+		if len(policies) > 0 {
+			if _, err := enforcer.AddPolicies(policies); err != nil {
+				return err
+			}
+		}
+		if len(groupings) > 0 {
+			if _, err := enforcer.AddGroupingPolicies(groupings); err != nil {
+				return err
+			}
+		}
+		if err := enforcer.SavePolicy(); err != nil {
+			return err
+		}
+	} else {
+		if len(policies) > 0 {
+			if _, err := enforcer.AddPoliciesEx(policies); err != nil {
+				return err
+			}
+		}
+		if len(groupings) > 0 {
+			if _, err := enforcer.AddGroupingPoliciesEx(groupings); err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Printf("已导入 %d 条 p 规则、%d 条 g 规则\n", len(policies), len(groupings))
+	return nil
+}
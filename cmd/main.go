@@ -1,12 +1,66 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-
-	"github.com/maxlcoder/homework-backend/model"
+	"os"
 )
 
+// 命令行入口：go run ./cmd <命令> [参数]，各命令参数见 go run ./cmd <命令> -h
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{name: "serve", usage: "启动服务", run: runServe},
+	{name: "migrate", usage: "数据库迁移：up / down / status", run: runMigrate},
+	{name: "seed", usage: "同步初始化数据（超管、权限、菜单及 casbin 规则）", run: runSeed},
+	{name: "routes", usage: "列出全部路由及其权限对应关系", run: runRoutes},
+	{name: "casbin", usage: "casbin 规则导出与导入：dump / import", run: runCasbin},
+	{name: "hash-password", usage: "生成密码哈希", run: runHashPassword},
+}
+
 func main() {
-	password := "Admin@123"
-	fmt.Println(model.HashPassword(password))
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if err := cmd.run(os.Args[2:]); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				os.Exit(2)
+			}
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	if name != "-h" && name != "help" {
+		fmt.Fprintf(os.Stderr, "未知命令：%s\n", name)
+	}
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "用法：go run ./cmd <命令> [参数]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "命令：")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-14s %s\n", cmd.name, cmd.usage)
+	}
+}
+
+// 创建子命令参数集，所有需要加载配置的命令都支持 -config
+func newFlagSet(name string) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	configFile := flags.String("config", "", "配置文件路径，默认 ./config/config.yaml")
+	return flags, configFile
 }
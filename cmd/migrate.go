@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/maxlcoder/homework-backend/app/bootstrap"
+	"github.com/maxlcoder/homework-backend/database"
+	"github.com/maxlcoder/homework-backend/database/migrate"
+)
+
+// 数据库迁移：go run ./cmd migrate up|down|status|create
+func runMigrate(args []string) error {
+	flags, configFile := newFlagSet("migrate")
+	module := flags.String("module", "", "down：只回滚该模块；create：迁移所属模块")
+	steps := flags.Int("steps", 1, "down：回滚的迁移数量")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "用法：go run ./cmd migrate [参数] up|down|status|create <名称>")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return fmt.Errorf("需指定 up / down / status / create")
+	}
+
+	// 生成迁移文件不需要连接数据库
+	if flags.Arg(0) == "create" {
+		if *module == "" || flags.NArg() != 2 {
+			return fmt.Errorf("用法：go run ./cmd migrate -module core create add_xxx")
+		}
+		migrator, err := migrate.New(nil)
+		if err != nil {
+			return err
+		}
+		files, err := migrator.Create(*module, flags.Arg(1))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			fmt.Println(file)
+		}
+		return nil
+	}
+
+	if err := bootstrap.Init(*configFile); err != nil {
+		return err
+	}
+	if err := database.InitDB(); err != nil {
+		return fmt.Errorf("数据库连接初始化失败：%w", err)
+	}
+	defer database.Close()
+	migrator, err := migrate.New(database.DB)
+	if err != nil {
+		return err
+	}
+
+	switch action := flags.Arg(0); action {
+	case "up":
+		migrations, err := migrator.Up()
+		for _, migration := range migrations {
+			fmt.Printf("applied %s/%04d_%s\n", migration.Module, migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(migrations) == 0 {
+			fmt.Println("数据库结构已是最新")
+		}
+		return nil
+	case "down":
+		migrations, err := migrator.Down(*module, *steps)
+		for _, migration := range migrations {
+			fmt.Printf("rolled back %s/%04d_%s\n", migration.Module, migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(migrations) == 0 {
+			fmt.Println("没有可回滚的迁移")
+		}
+		return nil
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "MODULE\tVERSION\tNAME\tSTATE\tAPPLIED_AT")
+		for _, status := range statuses {
+			appliedAt := "-"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%s\t%04d\t%s\t%s\t%s\n", status.Module, status.Version, status.Name, status.State, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("未知迁移操作：%s", action)
+	}
+}
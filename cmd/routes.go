@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/maxlcoder/homework-backend/app/bootstrap"
+	"github.com/maxlcoder/homework-backend/app/contract"
+	"github.com/maxlcoder/homework-backend/app/middleware"
+	core_model "github.com/maxlcoder/homework-backend/app/modules/core/model"
+	"github.com/maxlcoder/homework-backend/database"
+)
+
+// routeInfo 路由及其权限对应关系
+type routeInfo struct {
+	Method       string   `json:"method"`
+	Path         string   `json:"path"`
+	Handler      string   `json:"handler"`
+	Access       string   `json:"access"` // casbin：按角色校验；personal：登录即可；-：不经过 casbin
+	PermissionId uint     `json:"permission_id,omitempty"`
+	Name         string   `json:"name,omitempty"`
+	Menus        []string `json:"menus"`
+}
+
+// 列出路由：go run ./cmd routes [-unmapped] [-json]
+func runRoutes(args []string) error {
+	flags, configFile := newFlagSet("routes")
+	unmapped := flags.Bool("unmapped", false, "只列出未关联菜单的后台接口（只有超管可访问）")
+	asJson := flags.Bool("json", false, "以 JSON 输出")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := bootstrap.Init(*configFile); err != nil {
+		return err
+	}
+	enforcer, err := bootstrap.InitDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	r := bootstrap.NewRouter(enforcer)
+
+	// 菜单定义中的权限，key 为 方法 路径
+	menus := make(map[string][]string)
+	names := make(map[string]string)
+	var walk func(menu core_model.Menu)
+	walk = func(menu core_model.Menu) {
+		for _, permission := range menu.Permissions {
+			key := permission.Method + " " + permission.PATH
+			menus[key] = append(menus[key], menu.Number)
+			if permission.Name != "" {
+				names[key] = permission.Name
+			}
+		}
+		for _, child := range menu.Children {
+			walk(*child)
+		}
+	}
+	for _, menu := range contract.GetAllMenus() {
+		walk(menu)
+	}
+
+	// 已同步到 permissions 表的权限
+	var permissions []core_model.Permission
+	if err := database.DB.Find(&permissions).Error; err != nil {
+		return fmt.Errorf("权限查询失败：%w", err)
+	}
+	permissionIds := make(map[string]uint, len(permissions))
+	for _, permission := range permissions {
+		permissionIds[permission.Method+" "+permission.PATH] = permission.ID
+	}
+
+	var routes []routeInfo
+	for _, route := range r.Routes() {
+		key := route.Method + " " + route.Path
+		info := routeInfo{
+			Method:       route.Method,
+			Path:         route.Path,
+			Handler:      route.Handler,
+			Access:       "-",
+			PermissionId: permissionIds[key],
+			Name:         names[key],
+			Menus:        menus[key],
+		}
+		if strings.HasPrefix(route.Path, "/admin/") {
+			info.Access = "casbin"
+			if middleware.IsPersonalPath(route.Path) {
+				info.Access = "personal"
+			}
+		}
+		if *unmapped && (info.Access != "casbin" || len(info.Menus) > 0) {
+			continue
+		}
+		routes = append(routes, info)
+	}
+
+	if *asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(routes)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "METHOD\tPATH\tACCESS\tPERMISSION\tNAME\tMENUS")
+	for _, route := range routes {
+		permissionId := "-"
+		if route.PermissionId > 0 {
+			permissionId = fmt.Sprint(route.PermissionId)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", route.Method, route.Path, route.Access, permissionId, orDash(route.Name), orDash(strings.Join(route.Menus, ",")))
+	}
+	return w.Flush()
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
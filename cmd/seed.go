@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/maxlcoder/homework-backend/app/bootstrap"
+	"github.com/maxlcoder/homework-backend/database"
+	"github.com/maxlcoder/homework-backend/database/seed"
+)
+
+// 同步初始化数据：go run ./cmd seed [-dry-run] [-json]
+func runSeed(args []string) error {
+	flags, configFile := newFlagSet("seed")
+	dryRun := flags.Bool("dry-run", false, "只输出将产生的变更，不写入")
+	asJson := flags.Bool("json", false, "以 JSON 输出变更")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := bootstrap.Init(*configFile); err != nil {
+		return err
+	}
+	enforcer, err := bootstrap.InitDB()
+	if err != nil {
+		return err
+	}
+	defer database.Close()
+	// 权限数据取自路由定义，需注册全部路由
+	r := bootstrap.NewRouter(enforcer)
+
+	report, err := seed.Run(database.DB, r, enforcer, *dryRun)
+	if err != nil {
+		return err
+	}
+	if *asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	return printSeedReport(report)
+}
+
+func printSeedReport(report *seed.Report) error {
+	if !report.Changed() {
+		fmt.Println("初始化数据已是最新，无变更")
+		return nil
+	}
+	names := make([]string, 0, len(report.Tables))
+	for name := range report.Tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tCREATED\tUPDATED\tDELETED")
+	for _, name := range names {
+		table := report.Tables[name]
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\n", name, table.Created, table.Updated, table.Deleted)
+	}
+	if diff := report.Casbin; diff != nil {
+		fmt.Fprintf(w, "casbin policies\t%d\t\t%d\n", len(diff.AddPolicies), len(diff.RemovePolicies))
+		fmt.Fprintf(w, "casbin groupings\t%d\t\t%d\n", len(diff.AddGroupings), len(diff.RemoveGroupings))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if report.DryRun {
+		fmt.Println("dry-run：以上变更未写入")
+	}
+	return nil
+}
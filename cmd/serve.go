@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/maxlcoder/homework-backend/app/bootstrap"
+)
+
+// 启动服务：go run ./cmd serve -port 8083 -config ./config/config.yaml
+func runServe(args []string) error {
+	flags, configFile := newFlagSet("serve")
+	port := flags.Int("port", 8083, "监听端口")
+	seed := flags.Bool("seed", true, "启动前同步初始化数据，多实例部署时可关闭并改为发布时执行 seed 命令")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if err := bootstrap.Init(*configFile); err != nil {
+		return err
+	}
+	return bootstrap.Serve(bootstrap.ServeOptions{Port: *port, Seed: *seed})
+}
@@ -23,7 +23,123 @@ type Config struct {
 			DNS string
 		}
 	}
-	Kafka KafkaConfig
+	Kafka      KafkaConfig
+	Jwt        JwtConfig
+	LoginGuard LoginGuardConfig `mapstructure:"login_guard"`
+	Mfa        MfaConfig
+	Password   PasswordConfig
+	Casbin     CasbinConfig
+	Audit      AuditConfig
+	Log        LogConfig
+	Tracing    TracingConfig
+	Shutdown   ShutdownConfig
+}
+
+// ShutdownConfig 优雅关闭，阶段依次为 http / consumer / module / producer / database / telemetry
+type ShutdownConfig struct {
+	Delay   time.Duration            // 收到信号后先将就绪检查置为不可用，等待该时长让负载均衡摘除实例
+	Timeout time.Duration            // 关闭总超时
+	Phases  map[string]time.Duration // 各阶段超时，未配置的阶段只受总超时限制
+}
+
+// TracingConfig OpenTelemetry 链路追踪
+type TracingConfig struct {
+	Exporter    string  // otlp / stdout（本地开发），留空不启用
+	Endpoint    string  // OTLP gRPC 地址，如 127.0.0.1:4317
+	Insecure    bool    // OTLP 不使用 TLS
+	ServiceName string  `mapstructure:"service_name"`
+	SampleRatio float64 `mapstructure:"sample_ratio"` // 采样比例 0~1，上游已采样的请求始终采样
+}
+
+// LogConfig 日志级别，输出为 JSON
+type LogConfig struct {
+	Level         string        // debug / info / warn / error
+	SqlLevel      string        `mapstructure:"sql_level"`      // SQL 日志：silent / error / warn / info
+	SlowThreshold time.Duration `mapstructure:"slow_threshold"` // 慢查询阈值，超过后按 warn 记录
+}
+
+// AuditConfig 操作审计日志
+type AuditConfig struct {
+	Retention     time.Duration // 保留时长，超出后定期清理，0 表示不清理
+	PurgeInterval time.Duration `mapstructure:"purge_interval"` // 清理间隔
+}
+
+// PasswordConfig 密码策略
+type PasswordConfig struct {
+	MinLength     int           `mapstructure:"min_length"` // 最小长度
+	RequireUpper  bool          `mapstructure:"require_upper"`
+	RequireLower  bool          `mapstructure:"require_lower"`
+	RequireDigit  bool          `mapstructure:"require_digit"`
+	RequireSymbol bool          `mapstructure:"require_symbol"`
+	History       int           // 不能与最近 N 次使用过的密码相同
+	MaxAge        time.Duration `mapstructure:"max_age"`       // 密码有效期，到期后需修改，0 表示不过期
+	ResetTimeout  time.Duration `mapstructure:"reset_timeout"` // 重置令牌有效期
+	ResetTopic    string        `mapstructure:"reset_topic"`   // 重置令牌投递的 kafka topic，由通知服务发送邮件
+	Hasher        HasherConfig
+}
+
+// HasherConfig 密码哈希算法，旧算法或参数生成的哈希在登录成功后自动升级
+type HasherConfig struct {
+	Algorithm  string       // bcrypt / argon2id
+	BcryptCost int          `mapstructure:"bcrypt_cost"`
+	Argon2     Argon2Config `mapstructure:"argon2"`
+}
+
+type Argon2Config struct {
+	Memory      uint32 // 内存，单位 KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32 `mapstructure:"salt_length"`
+	KeyLength   uint32 `mapstructure:"key_length"`
+}
+
+// MfaConfig 管理员两步验证（TOTP）配置
+type MfaConfig struct {
+	Issuer         string        // 验证器 App 中显示的签发方
+	PendingTimeout time.Duration `mapstructure:"pending_timeout"` // 登录待验证令牌有效期
+	RecoveryCodes  int           `mapstructure:"recovery_codes"`  // 恢复码数量
+}
+
+// LoginGuardConfig 登录防爆破配置
+type LoginGuardConfig struct {
+	Store         string        // 失败计数存储：memory（单实例）/ database（多实例共享）
+	MaxFailures   int           `mapstructure:"max_failures"`    // 账号连续失败多少次后锁定
+	MaxIpFailures int           `mapstructure:"max_ip_failures"` // 单 IP 窗口期内最多失败次数
+	Window        time.Duration // 失败计数窗口
+	BaseDelay     time.Duration `mapstructure:"base_delay"`    // 指数退避基础间隔
+	MaxDelay      time.Duration `mapstructure:"max_delay"`     // 指数退避最大间隔
+	LockDuration  time.Duration `mapstructure:"lock_duration"` // 锁定时长，到期自动解锁；0 表示永久锁定，需管理员手动解锁
+}
+
+// JwtConfig 令牌签名与有效期配置，签名密钥不入库，通过 etcd 或环境变量下发
+type JwtConfig struct {
+	Key            string        // 前台用户令牌签名密钥（兼容单密钥，kid 为 default）
+	AdminKey       string        `mapstructure:"admin_key"` // 管理后台令牌签名密钥（兼容单密钥，kid 为 default）
+	Keys           []JwtKey      // 前台用户密钥环
+	AdminKeys      []JwtKey      `mapstructure:"admin_keys"` // 管理后台密钥环
+	Timeout        time.Duration // 访问令牌有效期
+	MaxRefresh     time.Duration `mapstructure:"max_refresh"`
+	RefreshTimeout time.Duration `mapstructure:"refresh_timeout"` // 刷新令牌有效期
+}
+
+// JwtKey 密钥环中的一把密钥，最新启用的密钥负责签名，其余密钥在退役前仍可验签
+type JwtKey struct {
+	ID             string // 写入令牌头 kid
+	Algorithm      string // HS256 / RS256 / ES256
+	Secret         string // HS256 密钥
+	PrivateKey     string `mapstructure:"private_key"` // RS256 / ES256 私钥 PEM
+	PrivateKeyFile string `mapstructure:"private_key_file"`
+	PublicKey      string `mapstructure:"public_key"` // 仅验签的密钥可只配置公钥
+	PublicKeyFile  string `mapstructure:"public_key_file"`
+	CreatedAt      string `mapstructure:"created_at"` // 启用时间 RFC3339，决定签名密钥的先后
+	RetireAt       string `mapstructure:"retire_at"`  // 退役时间 RFC3339，之后不再接受该密钥签发的令牌
+}
+
+// CasbinConfig 多实例间的权限规则同步
+type CasbinConfig struct {
+	Watcher      string        // 规则变更通知方式：kafka / database，留空不同步（单实例）
+	Topic        string        // kafka 通知 topic
+	PollInterval time.Duration `mapstructure:"poll_interval"` // database 方式的轮询间隔
 }
 
 type KafkaConfig struct {
@@ -35,17 +151,36 @@ type KafkaConfig struct {
 var Conf *Config
 var confMu sync.RWMutex
 
+// 最近一次成功加载配置的时间
+var loadedAt time.Time
+
+// 本地配置文件路径，为空时读取 ./config/config.yaml
+var configFile string
+
+// SetFile 指定本地配置文件，需在 Init 前调用
+func SetFile(path string) {
+	configFile = path
+}
+
 // Init 远程配置，从环境变量获取
 func Init() {
 	v := viper.New()
 	// 1. 读取本地配置
-	v.SetConfigName("config")
-	v.AddConfigPath("./config")
+	if configFile != "" {
+		v.SetConfigFile(configFile)
+	} else {
+		v.SetConfigName("config")
+		v.AddConfigPath("./config")
+	}
 	v.SetConfigType("yaml")
 	if err := v.ReadInConfig(); err != nil {
 		log.Println("read local config failed:", err)
 	}
 
+	// 签名密钥支持环境变量注入，绑定后每次重载都会生效
+	_ = v.BindEnv("jwt.key", "JWT_KEY")
+	_ = v.BindEnv("jwt.admin_key", "JWT_ADMIN_KEY")
+
 	// 重载配置
 	load(v)
 
@@ -74,6 +209,7 @@ func load(v *viper.Viper) {
 		log.Println("unmarshal config failed:", err)
 		return
 	}
+	loadedAt = time.Now()
 	log.Println("config loaded successfully")
 }
 
@@ -190,3 +326,31 @@ func GetConfig() *Config {
 	defer confMu.RUnlock()
 	return Conf
 }
+
+// LoadedAt 最近一次成功加载配置的时间，未加载时为零值
+func LoadedAt() time.Time {
+	confMu.RLock()
+	defer confMu.RUnlock()
+	return loadedAt
+}
+
+// PingEtcd 就绪检查：配置了 etcd 时检查能否连接，未配置时直接返回
+func PingEtcd(ctx context.Context) error {
+	conf := GetConfig()
+	if conf == nil || len(conf.Etcd.Endpoints) == 0 {
+		return nil
+	}
+	cli, err := clientv3.New(clientv3.Config{
+		Endpoints:   conf.Etcd.Endpoints,
+		Username:    conf.Etcd.Username,
+		Password:    conf.Etcd.Password,
+		DialTimeout: 2 * time.Second,
+		Context:     ctx,
+	})
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	_, err = cli.Status(ctx, conf.Etcd.Endpoints[0])
+	return err
+}
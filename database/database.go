@@ -1,30 +1,44 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/maxlcoder/homework-backend/config"
+	"github.com/maxlcoder/homework-backend/pkg/logger"
+	"github.com/maxlcoder/homework-backend/pkg/metrics"
+	"github.com/maxlcoder/homework-backend/pkg/tenant"
+	"github.com/maxlcoder/homework-backend/pkg/tracing"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
 
 func InitDB() error {
-	mysqlConfig := config.GetConfig().Database
-	dsn := mysqlConfig.Mysql.DNS
+	conf := config.GetConfig()
+	dsn := conf.Database.Mysql.DNS
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: logger.NewGormLogger(conf.Log),
 	})
 	if err != nil {
 		return fmt.Errorf("数据库连接失败：%w", err)
 	}
 
+	// 租户隔离
+	if err := db.Use(tenant.NewPlugin()); err != nil {
+		return fmt.Errorf("租户插件注册失败：%w", err)
+	}
+	// 链路追踪
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		return fmt.Errorf("链路追踪插件注册失败：%w", err)
+	}
+
 	DB = db
 
-	sqlDB, _ := db.DB()
+	sqlDB, err := db.DB()
 	if err != nil {
 		return fmt.Errorf("获取数据库实例失败：%w", err)
 	}
@@ -34,5 +48,34 @@ func InitDB() error {
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
+	// 连接池指标
+	if err := metrics.RegisterDB("main", sqlDB); err != nil {
+		return fmt.Errorf("连接池指标注册失败：%w", err)
+	}
+
 	return nil
 }
+
+// Ping 就绪检查：数据库连接可用
+func Ping(ctx context.Context) error {
+	if DB == nil {
+		return errors.New("数据库未初始化")
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Close 关闭连接池，等待执行中的语句完成
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
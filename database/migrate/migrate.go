@@ -0,0 +1,411 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	core_migrations "github.com/maxlcoder/homework-backend/app/modules/core/migrations"
+	homework_migrations "github.com/maxlcoder/homework-backend/app/modules/homework/migrations"
+	oms_migrations "github.com/maxlcoder/homework-backend/app/modules/oms/migrations"
+	wms_migrations "github.com/maxlcoder/homework-backend/app/modules/wms/migrations"
+	"gorm.io/gorm"
+)
+
+// 迁移状态
+const (
+	StateApplied = "applied" // 已执行
+	StatePending = "pending" // 待执行
+	StateChanged = "changed" // 已执行的 up 脚本被修改，checksum 不一致
+	StateMissing = "missing" // 已执行但迁移文件已不存在
+)
+
+// 迁移文件名：<版本号>_<名称>.up.sql / <版本号>_<名称>.down.sql
+var fileNamePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// 迁移名称
+var namePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// Source 模块迁移脚本来源
+type Source struct {
+	Module string
+	FS     fs.FS
+	Dir    string // 迁移文件所在目录（相对项目根目录），用于 create 生成新迁移文件
+}
+
+// Sources 全部模块的迁移脚本，按执行顺序排列
+func Sources() []Source {
+	return []Source{
+		{Module: "core", FS: core_migrations.FS, Dir: "app/modules/core/migrations"},
+		{Module: "wms", FS: wms_migrations.FS, Dir: "app/modules/wms/migrations"},
+		{Module: "oms", FS: oms_migrations.FS, Dir: "app/modules/oms/migrations"},
+		{Module: "homework", FS: homework_migrations.FS, Dir: "app/modules/homework/migrations"},
+	}
+}
+
+// SchemaMigration 已执行的迁移记录
+type SchemaMigration struct {
+	ID        uint      `gorm:"primarykey"`
+	Module    string    `gorm:"size:30;not null;default:'';uniqueIndex:uq_module_version;comment:模块"`
+	Version   uint      `gorm:"not null;default:0;uniqueIndex:uq_module_version;comment:版本号"`
+	Name      string    `gorm:"size:100;not null;default:'';comment:名称"`
+	Checksum  string    `gorm:"size:64;not null;default:'';comment:up 脚本 sha256"`
+	AppliedAt time.Time `gorm:"not null;comment:执行时间"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migration 单个版本的迁移脚本
+type Migration struct {
+	Module   string
+	Version  uint
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrationStatus 迁移执行状态
+type MigrationStatus struct {
+	Module    string     `json:"module"`
+	Version   uint       `json:"version"`
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+// Migrator 按模块与版本号执行迁移，执行记录写入 schema_migrations
+type Migrator struct {
+	db         *gorm.DB
+	sources    []Source
+	migrations []Migration
+}
+
+// New 加载迁移脚本，未指定 sources 时使用全部模块
+func New(db *gorm.DB, sources ...Source) (*Migrator, error) {
+	if len(sources) == 0 {
+		sources = Sources()
+	}
+	var migrations []Migration
+	for _, source := range sources {
+		moduleMigrations, err := load(source)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, moduleMigrations...)
+	}
+	return &Migrator{
+		db:         db,
+		sources:    sources,
+		migrations: migrations,
+	}, nil
+}
+
+// 读取模块迁移脚本，按版本号排序
+func load(source Source) ([]Migration, error) {
+	entries, err := fs.ReadDir(source.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("%s 模块迁移脚本读取失败：%w", source.Module, err)
+	}
+	byVersion := make(map[uint]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		matches := fileNamePattern.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("%s 模块迁移文件名不合法：%s", source.Module, entry.Name())
+		}
+		version, err := strconv.ParseUint(matches[1], 10, 32)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("%s 模块迁移版本号不合法：%s", source.Module, entry.Name())
+		}
+		content, err := fs.ReadFile(source.FS, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		migration, ok := byVersion[uint(version)]
+		if !ok {
+			migration = &Migration{Module: source.Module, Version: uint(version), Name: matches[2]}
+			byVersion[uint(version)] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("%s 模块迁移版本号重复：%d", source.Module, version)
+		}
+		if matches[3] == "up" {
+			migration.Up = string(content)
+			sum := sha256.Sum256(content)
+			migration.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("%s 模块迁移 %d 缺少 up 脚本", source.Module, migration.Version)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// 已执行的迁移记录，迁移表不存在时为空
+func (m *Migrator) applied() ([]SchemaMigration, error) {
+	if !m.db.Migrator().HasTable(&SchemaMigration{}) {
+		return nil, nil
+	}
+	var records []SchemaMigration
+	if err := m.db.Order("id").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("迁移记录查询失败：%w", err)
+	}
+	return records, nil
+}
+
+// Status 各迁移的执行状态，按模块与版本号排列，已执行但文件不存在的迁移排在最后
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	records, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	appliedRecords := make(map[string]SchemaMigration, len(records))
+	for _, record := range records {
+		appliedRecords[migrationKey(record.Module, record.Version)] = record
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{
+			Module:  migration.Module,
+			Version: migration.Version,
+			Name:    migration.Name,
+			State:   StatePending,
+		}
+		key := migrationKey(migration.Module, migration.Version)
+		if record, ok := appliedRecords[key]; ok {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+			status.State = StateApplied
+			if record.Checksum != migration.Checksum {
+				status.State = StateChanged
+			}
+			delete(appliedRecords, key)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, record := range records {
+		if _, ok := appliedRecords[migrationKey(record.Module, record.Version)]; !ok {
+			continue
+		}
+		appliedAt := record.AppliedAt
+		statuses = append(statuses, MigrationStatus{
+			Module:    record.Module,
+			Version:   record.Version,
+			Name:      record.Name,
+			State:     StateMissing,
+			AppliedAt: &appliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// 已执行的迁移脚本被修改或删除时拒绝继续
+func verify(statuses []MigrationStatus) error {
+	for _, status := range statuses {
+		switch status.State {
+		case StateChanged:
+			return fmt.Errorf("迁移 %s/%04d_%s 执行后被修改，checksum 不一致，请新增迁移而不是修改已执行的迁移", status.Module, status.Version, status.Name)
+		case StateMissing:
+			return fmt.Errorf("迁移 %s/%04d_%s 已执行但迁移文件不存在", status.Module, status.Version, status.Name)
+		}
+	}
+	return nil
+}
+
+// Check 启动前检查数据库结构是否为最新，存在待执行或被修改的迁移时返回错误
+func (m *Migrator) Check() error {
+	statuses, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if err := verify(statuses); err != nil {
+		return err
+	}
+	var pending []string
+	for _, status := range statuses {
+		if status.State == StatePending {
+			pending = append(pending, fmt.Sprintf("%s/%04d_%s", status.Module, status.Version, status.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("数据库结构未迁移到最新版本，待执行迁移：%s，请先执行 go run ./cmd migrate up", strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// Up 按模块顺序执行全部待执行迁移，返回本次执行的迁移
+func (m *Migrator) Up() ([]Migration, error) {
+	if err := m.db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("迁移表创建失败：%w", err)
+	}
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+	if err := verify(statuses); err != nil {
+		return nil, err
+	}
+	pending := make(map[string]bool)
+	for _, status := range statuses {
+		if status.State == StatePending {
+			pending[migrationKey(status.Module, status.Version)] = true
+		}
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if !pending[migrationKey(migration.Module, migration.Version)] {
+			continue
+		}
+		// MySQL 的 DDL 会隐式提交，迁移中途失败时已执行的语句不会回滚，需修复后手动处理
+		if err := m.exec(migration.Up); err != nil {
+			return done, fmt.Errorf("迁移 %s/%04d_%s 执行失败：%w", migration.Module, migration.Version, migration.Name, err)
+		}
+		record := SchemaMigration{
+			Module:    migration.Module,
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Checksum:  migration.Checksum,
+			AppliedAt: time.Now(),
+		}
+		if err := m.db.Create(&record).Error; err != nil {
+			return done, fmt.Errorf("迁移记录写入失败：%w", err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down 按执行顺序倒序回滚最近 steps 个迁移，module 不为空时只回滚该模块
+func (m *Migrator) Down(module string, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		return nil, errors.New("回滚数量需大于 0")
+	}
+	if !m.db.Migrator().HasTable(&SchemaMigration{}) {
+		return nil, nil
+	}
+	query := m.db.Order("id DESC").Limit(steps)
+	if module != "" {
+		query = query.Where("module = ?", module)
+	}
+	var records []SchemaMigration
+	if err := query.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("迁移记录查询失败：%w", err)
+	}
+
+	migrations := make(map[string]Migration, len(m.migrations))
+	for _, migration := range m.migrations {
+		migrations[migrationKey(migration.Module, migration.Version)] = migration
+	}
+	var done []Migration
+	for _, record := range records {
+		migration, ok := migrations[migrationKey(record.Module, record.Version)]
+		if !ok {
+			return done, fmt.Errorf("迁移 %s/%04d_%s 的迁移文件不存在，无法回滚", record.Module, record.Version, record.Name)
+		}
+		if migration.Checksum != record.Checksum {
+			return done, fmt.Errorf("迁移 %s/%04d_%s 执行后被修改，checksum 不一致，无法回滚", record.Module, record.Version, record.Name)
+		}
+		if strings.TrimSpace(migration.Down) == "" {
+			return done, fmt.Errorf("迁移 %s/%04d_%s 缺少 down 脚本，无法回滚", record.Module, record.Version, record.Name)
+		}
+		if err := m.exec(migration.Down); err != nil {
+			return done, fmt.Errorf("迁移 %s/%04d_%s 回滚失败：%w", record.Module, record.Version, record.Name, err)
+		}
+		if err := m.db.Delete(&record).Error; err != nil {
+			return done, fmt.Errorf("迁移记录删除失败：%w", err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Create 在模块迁移目录下生成下一个版本的空迁移文件，返回生成的文件路径
+func (m *Migrator) Create(module string, name string) ([]string, error) {
+	if !namePattern.MatchString(name) {
+		return nil, errors.New("迁移名称只能包含小写字母、数字与下划线")
+	}
+	var source *Source
+	for i := range m.sources {
+		if m.sources[i].Module == module {
+			source = &m.sources[i]
+		}
+	}
+	if source == nil || source.Dir == "" {
+		return nil, fmt.Errorf("模块不存在：%s", module)
+	}
+	var version uint
+	for _, migration := range m.migrations {
+		if migration.Module == module && migration.Version > version {
+			version = migration.Version
+		}
+	}
+	prefix := filepath.Join(source.Dir, fmt.Sprintf("%04d_%s", version+1, name))
+	files := []string{prefix + ".up.sql", prefix + ".down.sql"}
+	for _, file := range files {
+		if err := os.WriteFile(file, []byte("-- "+name+"\n"), 0644); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// 逐条执行脚本中的语句
+func (m *Migrator) exec(script string) error {
+	for _, statement := range splitStatements(script) {
+		if err := m.db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 按行尾分号拆分语句，忽略 -- 开头的注释行
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+func migrationKey(module string, version uint) string {
+	return fmt.Sprintf("%s/%d", module, version)
+}
@@ -0,0 +1,259 @@
+package migrate
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/glebarez/sqlite"
+	core_model "github.com/maxlcoder/homework-backend/app/modules/core/model"
+	homework_model "github.com/maxlcoder/homework-backend/app/modules/homework/model"
+	oms_model "github.com/maxlcoder/homework-backend/app/modules/oms/model"
+	wms_model "github.com/maxlcoder/homework-backend/app/modules/wms/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func testSource(files map[string]string) Source {
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	return Source{Module: "test", FS: fsys}
+}
+
+var testFiles = map[string]string{
+	"0001_notes.up.sql":   "CREATE TABLE notes (id integer PRIMARY KEY);",
+	"0001_notes.down.sql": "DROP TABLE notes;",
+	"0002_tags.up.sql":    "CREATE TABLE tags (id integer PRIMARY KEY);",
+	"0002_tags.down.sql":  "DROP TABLE tags;",
+}
+
+func newTestMigrator(t *testing.T, db *gorm.DB, files map[string]string) *Migrator {
+	t.Helper()
+	migrator, err := New(db, testSource(files))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrator
+}
+
+func states(t *testing.T, migrator *Migrator) []string {
+	t.Helper()
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result []string
+	for _, status := range statuses {
+		result = append(result, status.State)
+	}
+	return result
+}
+
+func TestMigratorUpAndCheck(t *testing.T) {
+	db := newTestDB(t)
+	migrator := newTestMigrator(t, db, testFiles)
+	if err := migrator.Check(); err == nil || !strings.Contains(err.Error(), "待执行迁移") {
+		t.Fatalf("存在待执行迁移时应报错, got %v", err)
+	}
+	done, err := migrator.Up()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 2 {
+		t.Fatalf("应执行 2 个迁移, got %d", len(done))
+	}
+	if err := migrator.Check(); err != nil {
+		t.Fatalf("迁移到最新后检查应通过, got %v", err)
+	}
+	if done, _ := migrator.Up(); len(done) != 0 {
+		t.Fatalf("重复执行不应再有迁移, got %d", len(done))
+	}
+}
+
+func TestMigratorChecksumMismatch(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := newTestMigrator(t, db, testFiles).Up(); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := map[string]string{}
+	for name, content := range testFiles {
+		changed[name] = content
+	}
+	changed["0001_notes.up.sql"] = "CREATE TABLE notes (id integer PRIMARY KEY, title text);"
+	migrator := newTestMigrator(t, db, changed)
+
+	if got := states(t, migrator); got[0] != StateChanged || got[1] != StateApplied {
+		t.Fatalf("修改已执行的迁移应标记为 changed, got %v", got)
+	}
+	if err := migrator.Check(); err == nil || !strings.Contains(err.Error(), "checksum 不一致") {
+		t.Fatalf("checksum 不一致时检查应报错, got %v", err)
+	}
+	if _, err := migrator.Up(); err == nil || !strings.Contains(err.Error(), "checksum 不一致") {
+		t.Fatalf("checksum 不一致时拒绝执行迁移, got %v", err)
+	}
+	if _, err := migrator.Down("test", 2); err == nil || !strings.Contains(err.Error(), "checksum 不一致") {
+		t.Fatalf("checksum 不一致时拒绝回滚, got %v", err)
+	}
+}
+
+func TestMigratorMissingFile(t *testing.T) {
+	db := newTestDB(t)
+	if _, err := newTestMigrator(t, db, testFiles).Up(); err != nil {
+		t.Fatal(err)
+	}
+	migrator := newTestMigrator(t, db, map[string]string{
+		"0001_notes.up.sql":   testFiles["0001_notes.up.sql"],
+		"0001_notes.down.sql": testFiles["0001_notes.down.sql"],
+	})
+	if got := states(t, migrator); len(got) != 2 || got[1] != StateMissing {
+		t.Fatalf("已执行但文件不存在的迁移应标记为 missing, got %v", got)
+	}
+	if err := migrator.Check(); err == nil || !strings.Contains(err.Error(), "迁移文件不存在") {
+		t.Fatalf("迁移文件缺失时检查应报错, got %v", err)
+	}
+}
+
+func TestMigratorDown(t *testing.T) {
+	db := newTestDB(t)
+	migrator := newTestMigrator(t, db, testFiles)
+	if _, err := migrator.Up(); err != nil {
+		t.Fatal(err)
+	}
+	done, err := migrator.Down("test", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != 1 || done[0].Version != 2 {
+		t.Fatalf("应回滚最新的迁移, got %+v", done)
+	}
+	if db.Migrator().HasTable("tags") {
+		t.Fatal("回滚后表应删除")
+	}
+	if got := states(t, migrator); got[0] != StateApplied || got[1] != StatePending {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestLoadRejectsInvalidFiles(t *testing.T) {
+	cases := map[string]map[string]string{
+		"文件名不合法": {"0001_Notes.up.sql": "SELECT 1;"},
+		"版本号重复":  {"0001_notes.up.sql": "SELECT 1;", "0001_tags.up.sql": "SELECT 1;"},
+		"缺少 up":  {"0001_notes.down.sql": "SELECT 1;"},
+	}
+	for name, files := range cases {
+		if _, err := New(newTestDB(t), testSource(files)); err == nil {
+			t.Errorf("%s: 应报错", name)
+		}
+	}
+}
+
+func TestModuleMigrationsLoad(t *testing.T) {
+	migrator, err := New(newTestDB(t))
+	if err != nil {
+		t.Fatalf("模块迁移文件应全部合法, got %v", err)
+	}
+	if len(migrator.migrations) == 0 {
+		t.Fatal("应加载到模块迁移")
+	}
+}
+
+func TestMigratorUpgradesBaselineSchema(t *testing.T) {
+	db := newTestDB(t)
+	// 引入迁移前由 AutoMigrate 创建的表与数据
+	if err := db.Exec("CREATE TABLE accounts (id integer PRIMARY KEY, name text NOT NULL DEFAULT '')").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("INSERT INTO accounts (id, name) VALUES (1, 'admin')").Error; err != nil {
+		t.Fatal(err)
+	}
+	migrator := newTestMigrator(t, db, map[string]string{
+		"0001_init.up.sql":           "CREATE TABLE IF NOT EXISTS accounts (id integer PRIMARY KEY, name text NOT NULL DEFAULT '');",
+		"0001_init.down.sql":         "DROP TABLE accounts;",
+		"0002_add_mfa.up.sql":        "ALTER TABLE accounts ADD COLUMN mfa_enabled boolean NOT NULL DEFAULT false;",
+		"0002_add_mfa.down.sql":      "ALTER TABLE accounts DROP COLUMN mfa_enabled;",
+		"0003_add_sessions.up.sql":   "CREATE TABLE sessions (id integer PRIMARY KEY, account_id integer NOT NULL DEFAULT 0);",
+		"0003_add_sessions.down.sql": "DROP TABLE sessions;",
+	})
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("基线表结构应能升级到最新, got %v", err)
+	}
+	if err := migrator.Check(); err != nil {
+		t.Fatalf("升级后检查应通过, got %v", err)
+	}
+	if !db.Migrator().HasColumn("accounts", "mfa_enabled") || !db.Migrator().HasTable("sessions") {
+		t.Fatal("已存在的基线表应通过增量迁移补充字段")
+	}
+	var count int64
+	if err := db.Table("accounts").Where("id = 1 AND mfa_enabled = ?", false).Count(&count).Error; err != nil || count != 1 {
+		t.Fatalf("已有数据应保留并使用新字段默认值, got %d %v", count, err)
+	}
+}
+
+var (
+	createTablePattern = regexp.MustCompile("(?s)CREATE TABLE (?:IF NOT EXISTS )?`(\\w+)` \\((.*?)\\n\\);")
+	alterTablePattern  = regexp.MustCompile("(?s)ALTER TABLE `(\\w+)`(.*?);")
+	columnPattern      = regexp.MustCompile("(?m)^\\s*(?:ADD COLUMN )?`(\\w+)` ")
+)
+
+// 按执行顺序汇总各模块迁移脚本建表与新增的字段
+func migratedColumns(t *testing.T) map[string]map[string]bool {
+	t.Helper()
+	migrator, err := New(newTestDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := make(map[string]map[string]bool)
+	for _, migration := range migrator.migrations {
+		for _, matches := range createTablePattern.FindAllStringSubmatch(migration.Up, -1) {
+			tables[matches[1]] = make(map[string]bool)
+			for _, column := range columnPattern.FindAllStringSubmatch(matches[2], -1) {
+				tables[matches[1]][column[1]] = true
+			}
+		}
+		for _, matches := range alterTablePattern.FindAllStringSubmatch(migration.Up, -1) {
+			for _, column := range columnPattern.FindAllStringSubmatch(matches[2], -1) {
+				tables[matches[1]][column[1]] = true
+			}
+		}
+	}
+	return tables
+}
+
+func TestModuleMigrationsCoverModels(t *testing.T) {
+	tables := migratedColumns(t)
+	var models []interface{}
+	for _, moduleModels := range [][]interface{}{core_model.Models(), wms_model.Models(), oms_model.Models(), homework_model.Models()} {
+		models = append(models, moduleModels...)
+	}
+	for _, model := range models {
+		s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		columns, ok := tables[s.Table]
+		if !ok {
+			t.Errorf("%s: 迁移脚本未创建该表", s.Table)
+			continue
+		}
+		for _, field := range s.Fields {
+			if field.DBName != "" && !columns[field.DBName] {
+				t.Errorf("%s.%s: 迁移脚本缺少该字段", s.Table, field.DBName)
+			}
+		}
+	}
+}
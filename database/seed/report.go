@@ -0,0 +1,76 @@
+package seed
+
+import (
+	"context"
+
+	core_service "github.com/maxlcoder/homework-backend/app/modules/core/service"
+	"gorm.io/gorm"
+)
+
+// Report 初始化数据变更统计，重复执行且代码定义未变化时各项均为 0
+type Report struct {
+	DryRun bool                     `json:"dry_run"`
+	Tables map[string]*TableReport  `json:"tables"`
+	Casbin *core_service.CasbinDiff `json:"casbin"`
+}
+
+// TableReport 单表新增、更新、删除的行数
+type TableReport struct {
+	Created int64 `json:"created"`
+	Updated int64 `json:"updated"`
+	Deleted int64 `json:"deleted"`
+}
+
+// Changed 是否存在变更
+func (r *Report) Changed() bool {
+	for _, table := range r.Tables {
+		if table.Created+table.Updated+table.Deleted > 0 {
+			return true
+		}
+	}
+	return r.Casbin != nil && r.Casbin.Changed()
+}
+
+func (r *Report) table(name string) *TableReport {
+	table, ok := r.Tables[name]
+	if !ok {
+		table = &TableReport{}
+		r.Tables[name] = table
+	}
+	return table
+}
+
+type reportKey struct{}
+
+func withReport(ctx context.Context, report *Report) context.Context {
+	return context.WithValue(ctx, reportKey{}, report)
+}
+
+// 按 context 中的 Report 统计写操作影响的行数，未绑定 Report 的语句不统计
+func registerReportCallbacks(db *gorm.DB) error {
+	if db.Callback().Create().Get("seed:report") != nil {
+		return nil
+	}
+	record := func(count func(table *TableReport, rows int64)) func(*gorm.DB) {
+		return func(tx *gorm.DB) {
+			report, ok := tx.Statement.Context.Value(reportKey{}).(*Report)
+			if !ok || tx.Error != nil || tx.RowsAffected == 0 {
+				return
+			}
+			count(report.table(tx.Statement.Table), tx.RowsAffected)
+		}
+	}
+	if err := db.Callback().Create().After("gorm:create").Register("seed:report", record(func(table *TableReport, rows int64) {
+		table.Created += rows
+	})); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("seed:report", record(func(table *TableReport, rows int64) {
+		table.Updated += rows
+	})); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("seed:report", record(func(table *TableReport, rows int64) {
+		table.Deleted += rows
+	}))
+}
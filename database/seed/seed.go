@@ -1,22 +1,51 @@
 package seed
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
-	"sync"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
 	"github.com/maxlcoder/homework-backend/app/contract"
 	core_model "github.com/maxlcoder/homework-backend/app/modules/core/model"
+	core_service "github.com/maxlcoder/homework-backend/app/modules/core/service"
 	"github.com/maxlcoder/homework-backend/model"
+	"github.com/maxlcoder/homework-backend/pkg/tenant"
 	"github.com/spf13/viper"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
-func InitSeed(db *gorm.DB, r *gin.Engine, enforcer *casbin.Enforcer) error {
+// dry-run 结束时回滚事务
+var errDryRun = errors.New("dry run")
+
+// Run 同步初始化数据（超管、权限、菜单及 casbin 规则），可重复执行；dryRun 时在事务中执行后回滚，只返回将产生的变更
+func Run(db *gorm.DB, r *gin.Engine, enforcer *casbin.SyncedEnforcer, dryRun bool) (*Report, error) {
+	if err := registerReportCallbacks(db); err != nil {
+		return nil, err
+	}
+	report := &Report{DryRun: dryRun, Tables: make(map[string]*TableReport)}
+	// 初始化数据涉及全部租户的角色授权，按系统任务处理
+	db = db.WithContext(tenant.System(withReport(context.Background(), report)))
+	if !dryRun {
+		return report, seed(db, r, enforcer, report)
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := seed(tx, r, enforcer, report); err != nil {
+			return err
+		}
+		return errDryRun
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	return report, err
+}
+
+func seed(db *gorm.DB, r *gin.Engine, enforcer *casbin.SyncedEnforcer, report *Report) error {
 
 	// 添加超管
 	if err := seedSuperAdmin(db); err != nil {
@@ -31,7 +60,7 @@ func InitSeed(db *gorm.DB, r *gin.Engine, enforcer *casbin.Enforcer) error {
 		return err
 	}
 	// 权限初始化，将全部 /admin 开头的路由转移到 permission 表
-	if err := seedPermissions(db, r, enforcer); err != nil {
+	if err := seedPermissions(db, r); err != nil {
 		return err
 	}
 	// 菜单初始化，将菜单权限数组中的内容同步到 menu 和 menu_permission 表
@@ -44,6 +73,23 @@ func InitSeed(db *gorm.DB, r *gin.Engine, enforcer *casbin.Enforcer) error {
 		return err
 	}
 
+	// 租户角色收回超出租户可用范围的菜单（如新调整为平台专属的菜单）
+	if err := core_service.RevokeUnentitledMenus(db); err != nil {
+		return err
+	}
+
+	// casbin 规则按授权数据重建，修正历史数据中不一致的规则；dry-run 时只计算差异
+	diff, err := core_service.NewCasbinService(db, enforcer).Reconcile(report.DryRun)
+	if err != nil {
+		return err
+	}
+	report.Casbin = diff
+	if diff.Changed() && !report.DryRun {
+		slog.Info("casbin 规则已重建",
+			"add_policies", len(diff.AddPolicies), "remove_policies", len(diff.RemovePolicies),
+			"add_groupings", len(diff.AddGroupings), "remove_groupings", len(diff.RemoveGroupings))
+	}
+
 	return nil
 }
 
@@ -67,6 +113,8 @@ func seedSuperAdmin(db *gorm.DB) error {
 			return err
 		}
 		admin.Password = password
+		// 初始密码，首次登录需修改
+		admin.MustChangePassword = true
 		db.Create(&admin)
 	}
 	return nil
@@ -92,9 +140,7 @@ func seedSuperRole(db *gorm.DB) error {
 
 // 超管角色分配
 func seedSuperAdminRole(db *gorm.DB) error {
-	db.Clauses(clause.OnConflict{
-		UpdateAll: false,
-	}).Create(&core_model.AdminRole{
+	db.Clauses(clause.OnConflict{DoNothing: true}).Create(&core_model.AdminRole{
 		AdminId: 1,
 		RoleId:  1,
 	})
@@ -102,7 +148,7 @@ func seedSuperAdminRole(db *gorm.DB) error {
 }
 
 // 路由权限
-func seedPermissions(db *gorm.DB, r *gin.Engine, enforcer *casbin.Enforcer) error {
+func seedPermissions(db *gorm.DB, r *gin.Engine) error {
 	// 检查相关表是否存在
 	has := db.Migrator().HasTable(&core_model.Permission{})
 	if !has {
@@ -125,18 +171,9 @@ func seedPermissions(db *gorm.DB, r *gin.Engine, enforcer *casbin.Enforcer) erro
 			db.Create(&permission)
 		}
 		permissionIds = append(permissionIds, permission.ID)
-
-		// 补充 casbin 中 p 规则，给角色赋权，超管角色 ID = 1 & tenant id = 0
-		enforcer.AddPermissionForUser("role_1", "0", permission.PATH, permission.Method)
 	}
 
-	// 找出需要删除的 permission ，删除 casbin 中对应的 p 规则
-	var deletePermissions []core_model.Permission
-	db.Where("id NOT IN ?", permissionIds).Find(&deletePermissions)
-
-	for _, permission := range deletePermissions {
-		enforcer.DeletePermission("1", permission.PATH, permission.Method)
-	}
+	// casbin 中的 p 规则在初始化最后按授权数据统一重建
 
 	// 删除非现有权限相关关联，默认不存在一个权限没有的情况
 	if len(permissionIds) > 0 {
@@ -162,36 +199,27 @@ func seedMenus(db *gorm.DB) error {
 	// 加载菜单
 	menus := loadMenus()
 
-	// 存在的菜单列表，删除不存在的
+	// 存在的菜单列表，删除不存在的；dry-run 在事务中执行，事务连接不能并发使用，按顺序同步
 	menuIds := []uint{}
-	menuIdCh := make(chan uint)
-	var wg sync.WaitGroup
-
-	for _, menu := range menus {
-		wg.Add(1)
-		go insertUpdateMenu(db, menuIdCh, &menu, 0, &wg)
+	for i := range menus {
+		menuIds = insertUpdateMenu(db, &menus[i], 0, menuIds)
 	}
 
-	go func() {
-		wg.Wait()
-		close(menuIdCh)
-	}()
-
-	menuId := <-menuIdCh
-	menuIds = append(menuIds, menuId)
-	for menuId := range menuIdCh {
-		menuIds = append(menuIds, menuId)
-	}
+	// 手动创建的菜单不在代码定义中，需保留
+	var customMenuIds []uint
+	db.Model(&core_model.Menu{}).Where("source = ?", core_model.MenuSourceCustom).Pluck("id", &customMenuIds)
+	menuIds = append(menuIds, customMenuIds...)
 
 	// 删除不在系统中的菜单
 	db.Where("menu_id NOT IN ?", menuIds).Delete(&core_model.RoleMenu{})
 	db.Where("menu_id NOT IN ?", menuIds).Delete(&core_model.MenuPermission{})
+	db.Where("menu_id NOT IN ?", menuIds).Delete(&core_model.MenuField{})
 
 	return nil
 }
 
-func insertUpdateMenu(db *gorm.DB, ch chan<- uint, menu *core_model.Menu, parentId uint, wg *sync.WaitGroup) {
-	defer wg.Done()
+// 同步菜单及其子菜单，返回追加了已同步菜单 ID 的 menuIds
+func insertUpdateMenu(db *gorm.DB, menu *core_model.Menu, parentId uint, menuIds []uint) []uint {
 	// 根据编号查询是否存在，存在则更新，不存在则插入
 	var findMenu core_model.Menu
 	err := db.Where("number = ?", menu.Number).First(&findMenu).Error
@@ -199,11 +227,16 @@ func insertUpdateMenu(db *gorm.DB, ch chan<- uint, menu *core_model.Menu, parent
 		findMenu.Number = menu.Number
 		findMenu.Name = menu.Name
 		findMenu.ParentID = parentId
+		findMenu.Sort = menu.Sort
+		findMenu.Source = core_model.MenuSourceCode
 		db.Create(&findMenu)
-	} else {
-		db.Model(&findMenu).Updates(core_model.Menu{
+	} else if !findMenu.Customized && (findMenu.Name != menu.Name || findMenu.ParentID != parentId || findMenu.Sort != menu.Sort || findMenu.Source != core_model.MenuSourceCode) {
+		// 已通过菜单管理调整过的菜单保留手动修改，未变化时不更新
+		db.Model(&findMenu).Select("name", "parent_id", "sort", "source").Updates(core_model.Menu{
 			Name:     menu.Name,
 			ParentID: parentId,
+			Sort:     menu.Sort,
+			Source:   core_model.MenuSourceCode,
 		})
 	}
 	// 检查是否有 permissions，有则需要写入权限关系
@@ -217,26 +250,36 @@ func insertUpdateMenu(db *gorm.DB, ch chan<- uint, menu *core_model.Menu, parent
 				})
 			}
 			// 菜单与权限的关联，不存在则创建
-			db.Clauses(clause.OnConflict{
-				UpdateAll: false,
-			}).Create(&core_model.MenuPermission{
+			db.Clauses(clause.OnConflict{DoNothing: true}).Create(&core_model.MenuPermission{
 				MenuID:       findMenu.ID,
 				PermissionID: findPermission.ID,
 			})
 		}
 	}
 
-	ch <- findMenu.ID
+	// 字段权限，以菜单定义为准
+	fieldQuery := db.Where("menu_id = ?", findMenu.ID)
+	if len(menu.Fields) > 0 {
+		fieldQuery = fieldQuery.Where("field NOT IN ?", menu.Fields)
+	}
+	fieldQuery.Delete(&core_model.MenuField{})
+	for _, field := range menu.Fields {
+		db.Clauses(clause.OnConflict{DoNothing: true}).Create(&core_model.MenuField{
+			MenuID: findMenu.ID,
+			Field:  field,
+		})
+	}
+
+	menuIds = append(menuIds, findMenu.ID)
 
 	for _, child := range menu.Children {
-		wg.Add(1)
-		go insertUpdateMenu(db, ch, child, findMenu.ID, wg)
+		menuIds = insertUpdateMenu(db, child, findMenu.ID, menuIds)
 	}
-
+	return menuIds
 }
 
 // 角色菜单,权限关联
-func seedRoleMenuPermissions(db *gorm.DB, enforcer *casbin.Enforcer) error {
+func seedRoleMenuPermissions(db *gorm.DB, enforcer *casbin.SyncedEnforcer) error {
 	// 检查相关表是否存在
 	has := db.Migrator().HasTable(&core_model.RoleMenu{})
 	if !has {
@@ -250,7 +293,7 @@ func seedRoleMenuPermissions(db *gorm.DB, enforcer *casbin.Enforcer) error {
 	}
 	for _, menu := range menus {
 		var roleMenu core_model.RoleMenu
-		err := db.Where("id = ?", menu.ID).First(&roleMenu).Error
+		err := db.Where("role_id = ? AND menu_id = ?", 1, menu.ID).First(&roleMenu).Error
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			roleMenu.RoleID = 1
 			roleMenu.MenuID = menu.ID
@@ -266,7 +309,7 @@ func seedRoleMenuPermissions(db *gorm.DB, enforcer *casbin.Enforcer) error {
 	}
 	for _, permission := range permissions {
 		var rolePermission core_model.RolePermission
-		err := db.Where("id = ?", permission.ID).First(&rolePermission).Error
+		err := db.Where("role_id = ? AND permission_id = ?", 1, permission.ID).First(&rolePermission).Error
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			rolePermission.RoleID = 1
 			rolePermission.PermissionID = permission.ID
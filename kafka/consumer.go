@@ -2,17 +2,16 @@ package kafka
 
 import (
 	"context"
-	"log"
-	"os"
-	"os/signal"
+	"errors"
+	"log/slog"
 	"strings"
 	"sync"
-	"syscall"
 
 	"github.com/segmentio/kafka-go"
 )
 
-type HandleFunc func(msg kafka.Message) error
+// HandleFunc 消息处理，ctx 带有消息头中的请求 ID 与消费 span
+type HandleFunc func(ctx context.Context, msg kafka.Message) error
 
 type ConsumerManager struct {
 	mu       sync.Mutex
@@ -20,14 +19,22 @@ type ConsumerManager struct {
 	handlers map[string]HandleFunc
 	brokers  []string
 
-	wg     sync.WaitGroup
-	stopCh chan struct{}
+	wg        sync.WaitGroup // 消费循环
+	handlerWg sync.WaitGroup // 处理中的消息
+	ctx       context.Context
+	cancel    context.CancelFunc
 }
 
-var cm = &ConsumerManager{
-	readers:  make(map[string]*kafka.Reader),
-	handlers: make(map[string]HandleFunc),
-	stopCh:   make(chan struct{}),
+var cm = newConsumerManager()
+
+func newConsumerManager() *ConsumerManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &ConsumerManager{
+		readers:  make(map[string]*kafka.Reader),
+		handlers: make(map[string]HandleFunc),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
 }
 
 func InitConsumer(brokers []string) {
@@ -60,7 +67,18 @@ func RegisterConsumer(groupId, topic string, handler HandleFunc) {
 	cm.handlers[key] = handler
 }
 
-// 有多少 handler 就启动多少
+// Lags 各消费组 reader 的积压消息数，key 为 groupId/topic
+func (cm *ConsumerManager) Lags() map[string]int64 {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	lags := make(map[string]int64, len(cm.readers))
+	for key, reader := range cm.readers {
+		lags[key] = reader.Stats().Lag
+	}
+	return lags
+}
+
+// 有多少 handler 就启动多少，由 StopConsumers 停止
 func StartConsumers() {
 	for key, handler := range cm.handlers {
 		parts := strings.Split(key, "/")
@@ -69,41 +87,92 @@ func StartConsumers() {
 		cm.wg.Add(1)
 		go cm.runConsumer(reader, handler)
 	}
-	go cm.waitForExit()
 }
 
 // 执行消费
 func (cm *ConsumerManager) runConsumer(reader *kafka.Reader, handler HandleFunc) {
 	defer cm.wg.Done()
+	topic, groupId := reader.Config().Topic, reader.Config().GroupID
 	for {
-		select {
-		case <-cm.stopCh:
-			_ = reader.Close()
-			return
-		default:
-			msg, err := reader.ReadMessage(context.Background())
-			if err != nil {
-				log.Println("reader error:", err)
-				continue
+		msg, err := reader.ReadMessage(cm.ctx)
+		if err != nil {
+			if cm.ctx.Err() != nil {
+				return
 			}
-			// 并发执行 handler
-			go func(m kafka.Message) {
-				if err := handler(m); err != nil {
-					log.Println("handler error:", err)
-				}
-			}(msg)
+			consumeErrorsTotal.WithLabelValues(topic, groupId, "read").Inc()
+			slog.Error("kafka reader error", "topic", topic, "error", err)
+			continue
 		}
+		consumedTotal.WithLabelValues(topic, groupId).Inc()
+		// 并发执行 handler
+		cm.handlerWg.Add(1)
+		go func(m kafka.Message) {
+			defer cm.handlerWg.Done()
+			ctx, span := startConsumerSpan(m, groupId)
+			err := handler(ctx, m)
+			endSpan(span, err)
+			if err != nil {
+				consumeErrorsTotal.WithLabelValues(topic, groupId, "handle").Inc()
+				slog.ErrorContext(ctx, "kafka handler error", "topic", m.Topic, "offset", m.Offset, "error", err)
+			}
+		}(msg)
 	}
 }
 
-func (cm *ConsumerManager) waitForExit() {
-	ch := make(chan os.Signal, 1)
-	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
-	<-ch
+// StopConsumers 停止拉取消息，等待处理中的 handler 完成后关闭 reader，ctx 到期时不再等待
+func StopConsumers(ctx context.Context) error {
+	cm.cancel()
+	done := make(chan struct{})
+	go func() {
+		cm.wg.Wait()
+		cm.handlerWg.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = errors.New("等待 kafka 消息处理超时")
+	}
 
-	log.Println("Kafka shutting down...")
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	for key, reader := range cm.readers {
+		if closeErr := reader.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+		delete(cm.readers, key)
+	}
+	return err
+}
 
-	close(cm.stopCh)
-	cm.wg.Wait()
-	log.Println("Kafka shutdown complete")
+// Broadcast 广播订阅：不加入消费组，每个实例都收到全部消息，从订阅时的最新位置开始消费（仅消费 0 号分区），ctx 取消后返回
+func Broadcast(ctx context.Context, brokers []string, topic string, handler HandleFunc) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: brokers,
+		Topic:   topic,
+	})
+	defer reader.Close()
+	if err := reader.SetOffset(kafka.LastOffset); err != nil {
+		slog.Error("kafka reader set offset error", "topic", topic, "error", err)
+	}
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			consumeErrorsTotal.WithLabelValues(topic, "", "read").Inc()
+			slog.Error("kafka reader error", "topic", topic, "error", err)
+			continue
+		}
+		consumedTotal.WithLabelValues(topic, "").Inc()
+		msgCtx, span := startConsumerSpan(msg, "")
+		err = handler(msgCtx, msg)
+		endSpan(span, err)
+		if err != nil {
+			consumeErrorsTotal.WithLabelValues(topic, "", "handle").Inc()
+			slog.ErrorContext(msgCtx, "kafka handler error", "topic", topic, "offset", msg.Offset, "error", err)
+		}
+	}
 }
@@ -1,8 +1,9 @@
 package kafka
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"log/slog"
 
 	"github.com/segmentio/kafka-go"
 )
@@ -12,12 +13,12 @@ type OrderCreatedMsg struct {
 	UserID  string `json:"userId"`
 }
 
-func OrderCreatedHandler(msg kafka.Message) error {
+func OrderCreatedHandler(ctx context.Context, msg kafka.Message) error {
 	var m OrderCreatedMsg
 	if err := json.Unmarshal(msg.Value, &m); err != nil {
 		return err
 	}
-	fmt.Println("订单创建事件 ->", m.OrderID, m.UserID)
+	slog.InfoContext(ctx, "订单创建事件", "order_id", m.OrderID, "user_id", m.UserID)
 
 	// TODO: 调用 service 层处理业务逻辑
 
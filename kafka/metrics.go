@@ -0,0 +1,72 @@
+package kafka
+
+import (
+	"strings"
+
+	"github.com/maxlcoder/homework-backend/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	producedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "kafka",
+		Name:      "produced_messages_total",
+		Help:      "kafka 发送成功的消息数",
+	}, []string{"topic"})
+
+	produceErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "kafka",
+		Name:      "produce_errors_total",
+		Help:      "kafka 发送失败次数",
+	}, []string{"topic"})
+
+	consumedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "kafka",
+		Name:      "consumed_messages_total",
+		Help:      "kafka 消费的消息数，广播订阅的 group 为空",
+	}, []string{"topic", "group"})
+
+	// stage 为 read（拉取失败）/ handle（处理失败）
+	consumeErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "kafka",
+		Name:      "consume_errors_total",
+		Help:      "kafka 消费失败次数",
+	}, []string{"topic", "group", "stage"})
+
+	consumerLagDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "kafka", "consumer_lag"),
+		"kafka 消费组积压消息数",
+		[]string{"topic", "group"}, nil,
+	)
+)
+
+func init() {
+	metrics.MustRegister(producedTotal, produceErrorsTotal, consumedTotal, consumeErrorsTotal, &consumerLagCollector{})
+}
+
+// 发送结果计数
+func observeProduce(topic string, err error) {
+	if err != nil {
+		produceErrorsTotal.WithLabelValues(topic).Inc()
+		return
+	}
+	producedTotal.WithLabelValues(topic).Inc()
+}
+
+// consumerLagCollector 采集时读取各消费组 reader 的积压数
+type consumerLagCollector struct{}
+
+func (c *consumerLagCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- consumerLagDesc
+}
+
+func (c *consumerLagCollector) Collect(ch chan<- prometheus.Metric) {
+	for key, lag := range cm.Lags() {
+		groupId, topic, _ := strings.Cut(key, "/")
+		ch <- prometheus.MustNewConstMetric(consumerLagDesc, prometheus.GaugeValue, float64(lag), topic, groupId)
+	}
+}
@@ -2,6 +2,8 @@ package kafka
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"sync"
 
 	"github.com/segmentio/kafka-go"
@@ -11,6 +13,7 @@ type ProducerManager struct {
 	mu      sync.Mutex
 	writers map[string]*kafka.Writer
 	brokers []string
+	pending sync.WaitGroup // 发送中的异步消息
 }
 
 var pm = &ProducerManager{
@@ -40,18 +43,76 @@ func GetWriter(topic string) *kafka.Writer {
 	return writer
 }
 
-// SendSync 同步发送
-func SendSync(topic string, value []byte) error {
+// Ping 就绪检查：任一 broker 可连接即视为可用
+func Ping(ctx context.Context) error {
+	if len(pm.brokers) == 0 {
+		return errors.New("未配置 kafka broker")
+	}
+	var err error
+	for _, broker := range pm.brokers {
+		var conn *kafka.Conn
+		conn, err = kafka.DialContext(ctx, "tcp", broker)
+		if err == nil {
+			return conn.Close()
+		}
+	}
+	return err
+}
+
+// SendSync 同步发送，ctx 中的请求 ID 与 trace context 随消息头传递
+func SendSync(ctx context.Context, topic string, value []byte) error {
 	writer := GetWriter(topic)
-	return writer.WriteMessages(context.Background(), kafka.Message{
-		Value: value,
+	ctx, span := startProducerSpan(ctx, topic)
+	err := writer.WriteMessages(ctx, kafka.Message{
+		Value:   value,
+		Headers: messageHeaders(ctx),
 	})
+	endSpan(span, err)
+	observeProduce(topic, err)
+	return err
 }
 
-// SendAsync 异步发送
-func SendAsync(topic string, value []byte) {
+// SendAsync 异步发送，ctx 中的请求 ID 与 trace context 随消息头传递，发送不受请求结束影响
+func SendAsync(ctx context.Context, topic string, value []byte) {
 	writer := GetWriter(topic)
-	go writer.WriteMessages(context.Background(), kafka.Message{
-		Value: value,
-	})
+	ctx, span := startProducerSpan(ctx, topic)
+	headers := messageHeaders(ctx)
+	pm.pending.Add(1)
+	go func() {
+		defer pm.pending.Done()
+		err := writer.WriteMessages(context.Background(), kafka.Message{
+			Value:   value,
+			Headers: headers,
+		})
+		endSpan(span, err)
+		observeProduce(topic, err)
+		if err != nil {
+			slog.ErrorContext(ctx, "kafka async send failed", "topic", topic, "error", err)
+		}
+	}()
+}
+
+// CloseProducers 等待发送中的异步消息完成后关闭 Writer，ctx 到期时不再等待
+func CloseProducers(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		pm.pending.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = errors.New("等待 kafka 异步消息发送超时")
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	for topic, writer := range pm.writers {
+		if closeErr := writer.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+		delete(pm.writers, topic)
+	}
+	return err
 }
@@ -0,0 +1,102 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/maxlcoder/homework-backend/pkg/logger"
+	"github.com/maxlcoder/homework-backend/pkg/tracing"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// headerCarrier 以 kafka 消息头承载 W3C trace context
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, header := range *c.headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (c headerCarrier) Set(key string, value string) {
+	for i, header := range *c.headers {
+		if header.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.headers))
+	for _, header := range *c.headers {
+		keys = append(keys, header.Key)
+	}
+	return keys
+}
+
+// 消息头：请求 ID 与 trace context，消费端据此关联日志与链路
+func messageHeaders(ctx context.Context) []kafka.Header {
+	var headers []kafka.Header
+	if requestId := logger.RequestId(ctx); requestId != "" {
+		headers = append(headers, kafka.Header{Key: logger.RequestIdHeader, Value: []byte(requestId)})
+	}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{headers: &headers})
+	return headers
+}
+
+// MessageContext 带上消息头中请求 ID 与上游链路的 context，消费端日志与 span 据此与生产端请求关联
+func MessageContext(msg kafka.Message) context.Context {
+	ctx := context.Background()
+	headers := msg.Headers
+	if requestId := (headerCarrier{headers: &headers}).Get(logger.RequestIdHeader); requestId != "" {
+		ctx = logger.WithRequestId(ctx, requestId)
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, headerCarrier{headers: &headers})
+}
+
+func startProducerSpan(ctx context.Context, topic string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, fmt.Sprintf("send %s", topic),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeSend,
+			semconv.MessagingDestinationName(topic),
+		),
+	)
+}
+
+func startConsumerSpan(msg kafka.Message, groupId string) (context.Context, trace.Span) {
+	ctx, span := tracing.Tracer().Start(MessageContext(msg), fmt.Sprintf("process %s", msg.Topic),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeProcess,
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingKafkaOffset(int(msg.Offset)),
+		),
+	)
+	if groupId != "" {
+		span.SetAttributes(semconv.MessagingConsumerGroupName(groupId))
+	}
+	return ctx, span
+}
+
+// 结束 span，失败时记录错误
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
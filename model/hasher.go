@@ -0,0 +1,216 @@
+package model
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/maxlcoder/homework-backend/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrUnknownHasher = errors.New("不支持的密码哈希算法")
+
+// Hasher 密码哈希算法
+type Hasher interface {
+	// Name 算法名称
+	Name() string
+	// Hash 生成密码哈希
+	Hash(password string) (string, error)
+	// Match 哈希是否由该算法生成
+	Match(hash string) bool
+	// Verify 校验密码
+	Verify(password string, hash string) bool
+	// NeedsRehash 哈希参数是否低于当前配置
+	NeedsRehash(hash string) bool
+}
+
+// BcryptHasher bcrypt 哈希
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h *BcryptHasher) Name() string {
+	return "bcrypt"
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(bytes), err
+}
+
+func (h *BcryptHasher) Match(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+func (h *BcryptHasher) Verify(password string, hash string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+func (h *BcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost < h.Cost
+}
+
+// Argon2idHasher argon2id 哈希，格式 $argon2id$v=19$m=65536,t=3,p=2$salt$key
+type Argon2idHasher struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+func (h *Argon2idHasher) Name() string {
+	return "argon2id"
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Argon2idHasher) Match(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+func (h *Argon2idHasher) Verify(password string, hash string) bool {
+	params, err := parseArgon2Hash(hash)
+	if err != nil {
+		return false
+	}
+	key := argon2.IDKey([]byte(password), params.salt, params.iterations, params.memory, params.parallelism, uint32(len(params.key)))
+	return subtle.ConstantTimeCompare(key, params.key) == 1
+}
+
+func (h *Argon2idHasher) NeedsRehash(hash string) bool {
+	params, err := parseArgon2Hash(hash)
+	if err != nil {
+		return true
+	}
+	return params.memory < h.Memory ||
+		params.iterations < h.Iterations ||
+		params.parallelism < h.Parallelism ||
+		uint32(len(params.salt)) < h.SaltLength ||
+		uint32(len(params.key)) < h.KeyLength
+}
+
+func parseArgon2Hash(hash string) (*argon2Params, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrUnknownHasher
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, err
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("argon2 版本 %d 不兼容", version)
+	}
+	params := &argon2Params{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism); err != nil {
+		return nil, err
+	}
+	var err error
+	if params.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, err
+	}
+	if params.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// NewHasher 根据算法名称与配置创建哈希器，未配置的参数使用默认值
+func NewHasher(hasherConfig config.HasherConfig) (Hasher, error) {
+	switch hasherConfig.Algorithm {
+	case "", "bcrypt":
+		cost := hasherConfig.BcryptCost
+		if cost == 0 {
+			cost = bcrypt.DefaultCost
+		}
+		if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost 需在 %d-%d 之间", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+		return &BcryptHasher{Cost: cost}, nil
+	case "argon2id":
+		argon2Config := hasherConfig.Argon2
+		hasher := &Argon2idHasher{
+			Memory:      argon2Config.Memory,
+			Iterations:  argon2Config.Iterations,
+			Parallelism: argon2Config.Parallelism,
+			SaltLength:  argon2Config.SaltLength,
+			KeyLength:   argon2Config.KeyLength,
+		}
+		if hasher.Memory == 0 {
+			hasher.Memory = 64 * 1024
+		}
+		if hasher.Iterations == 0 {
+			hasher.Iterations = 3
+		}
+		if hasher.Parallelism == 0 {
+			hasher.Parallelism = 2
+		}
+		if hasher.SaltLength == 0 {
+			hasher.SaltLength = 16
+		}
+		if hasher.KeyLength == 0 {
+			hasher.KeyLength = 32
+		}
+		if hasher.Memory < 8*uint32(hasher.Parallelism) {
+			return nil, fmt.Errorf("argon2id memory 不能小于 8 * parallelism KiB")
+		}
+		if hasher.SaltLength < 8 || hasher.KeyLength < 16 {
+			return nil, fmt.Errorf("argon2id salt_length 不能小于 8，key_length 不能小于 16")
+		}
+		return hasher, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownHasher, hasherConfig.Algorithm)
+}
+
+// ValidateHasher 校验当前配置的哈希算法与参数，启动时调用，配置错误时拒绝启动
+func ValidateHasher() error {
+	if _, err := currentHasher(); err != nil {
+		return fmt.Errorf("密码哈希配置错误：%w", err)
+	}
+	return nil
+}
+
+// 当前配置的哈希器，未加载配置时使用默认 bcrypt；配置错误时返回错误，不退回其他算法，避免已有哈希被降级重写
+func currentHasher() (Hasher, error) {
+	hasherConfig := config.HasherConfig{}
+	if conf := config.GetConfig(); conf != nil {
+		hasherConfig = conf.Password.Hasher
+	}
+	return NewHasher(hasherConfig)
+}
+
+// 根据哈希格式识别生成时使用的算法
+func hasherOf(hash string) Hasher {
+	for _, hasher := range []Hasher{&BcryptHasher{}, &Argon2idHasher{}} {
+		if hasher.Match(hash) {
+			return hasher
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/maxlcoder/homework-backend/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 测试使用较低的参数，避免拖慢用例
+func testArgon2Hasher() *Argon2idHasher {
+	return &Argon2idHasher{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+}
+
+func TestHashersVerify(t *testing.T) {
+	for _, hasher := range []Hasher{&BcryptHasher{Cost: bcrypt.MinCost}, testArgon2Hasher()} {
+		hash, err := hasher.Hash("secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !hasher.Match(hash) {
+			t.Errorf("%s: 应识别自身生成的哈希", hasher.Name())
+		}
+		if !hasher.Verify("secret", hash) {
+			t.Errorf("%s: 正确密码应通过", hasher.Name())
+		}
+		if hasher.Verify("wrong", hash) {
+			t.Errorf("%s: 错误密码应拒绝", hasher.Name())
+		}
+		// 按哈希格式识别算法，切换算法后历史哈希仍可校验
+		if !CheckPasswordHash("secret", hash) {
+			t.Errorf("%s: 应按哈希格式识别算法", hasher.Name())
+		}
+	}
+	if CheckPasswordHash("secret", "plain") {
+		t.Error("无法识别的哈希应拒绝")
+	}
+}
+
+func TestBcryptNeedsRehash(t *testing.T) {
+	weak, err := (&BcryptHasher{Cost: bcrypt.MinCost}).Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hasher := &BcryptHasher{Cost: bcrypt.MinCost + 1}
+	if !hasher.NeedsRehash(weak) {
+		t.Error("cost 低于配置时需要重新哈希")
+	}
+	if (&BcryptHasher{Cost: bcrypt.MinCost}).NeedsRehash(weak) {
+		t.Error("cost 与配置一致时无需重新哈希")
+	}
+	if !hasher.NeedsRehash("invalid") {
+		t.Error("无法解析的哈希需要重新哈希")
+	}
+}
+
+func TestArgon2idNeedsRehash(t *testing.T) {
+	hash, err := testArgon2Hasher().Hash("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if testArgon2Hasher().NeedsRehash(hash) {
+		t.Error("参数与配置一致时无需重新哈希")
+	}
+	stronger := testArgon2Hasher()
+	stronger.Iterations = 2
+	if !stronger.NeedsRehash(hash) {
+		t.Error("迭代次数低于配置时需要重新哈希")
+	}
+	stronger = testArgon2Hasher()
+	stronger.Memory = 2048
+	if !stronger.NeedsRehash(hash) {
+		t.Error("内存参数低于配置时需要重新哈希")
+	}
+}
+
+func TestPasswordNeedsRehash(t *testing.T) {
+	// 未加载配置时使用默认 bcrypt
+	current, err := HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if PasswordNeedsRehash(current) {
+		t.Error("当前算法与参数生成的哈希无需重新哈希")
+	}
+	weak, _ := (&BcryptHasher{Cost: bcrypt.MinCost}).Hash("secret")
+	if !PasswordNeedsRehash(weak) {
+		t.Error("cost 较低的 bcrypt 哈希需要重新哈希")
+	}
+	other, _ := testArgon2Hasher().Hash("secret")
+	if !PasswordNeedsRehash(other) {
+		t.Error("算法与配置不一致时需要重新哈希")
+	}
+}
+
+func TestNewHasher(t *testing.T) {
+	hasher, err := NewHasher(config.HasherConfig{Algorithm: "argon2id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hasher.Name() != "argon2id" {
+		t.Fatalf("got %s", hasher.Name())
+	}
+	if _, err := NewHasher(config.HasherConfig{BcryptCost: bcrypt.MaxCost + 1}); err == nil {
+		t.Error("bcrypt cost 超出范围应报错")
+	}
+	if _, err := NewHasher(config.HasherConfig{Algorithm: "md5"}); !errors.Is(err, ErrUnknownHasher) {
+		t.Errorf("不支持的算法应报错, got %v", err)
+	}
+	if _, err := NewHasher(config.HasherConfig{Algorithm: "argon2id", Argon2: config.Argon2Config{KeyLength: 8}}); err == nil {
+		t.Error("argon2id 参数不合法应报错")
+	}
+}
+
+func TestInvalidHasherConfigDoesNotFallBack(t *testing.T) {
+	previous := config.Conf
+	t.Cleanup(func() { config.Conf = previous })
+	config.Conf = &config.Config{}
+	config.Conf.Password.Hasher = config.HasherConfig{Algorithm: "argon2"}
+
+	if err := ValidateHasher(); !errors.Is(err, ErrUnknownHasher) {
+		t.Fatalf("哈希配置错误时启动校验应报错, got %v", err)
+	}
+	if _, err := HashPassword("secret"); err == nil {
+		t.Error("哈希配置错误时不应退回 bcrypt 生成哈希")
+	}
+	hash, _ := testArgon2Hasher().Hash("secret")
+	if PasswordNeedsRehash(hash) {
+		t.Error("哈希配置错误时不应重新哈希已有密码")
+	}
+}
@@ -3,8 +3,9 @@ package model
 import (
 	"time"
 
-	"golang.org/x/crypto/bcrypt"
+	"github.com/maxlcoder/homework-backend/pkg/datascope"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BaseModel struct {
@@ -27,6 +28,32 @@ type BaseTenantModel struct {
 	TenantID uint `gorm:"not null;default:0;comment:租户 ID"`
 }
 
+// DataOwner 数据归属人与归属部门，嵌入后查询条件默认按角色数据权限过滤，创建时按当前操作人自动填充
+type DataOwner struct {
+	CreatedBy    uint `gorm:"not null;default:0;index;comment:创建人"`
+	DepartmentId uint `gorm:"not null;default:0;index;comment:归属部门 ID"`
+}
+
+func (DataOwner) DataScope(operator datascope.Operator) (clause.Expression, error) {
+	return datascope.OwnerExpression(operator, "created_by", "department_id")
+}
+
+func (DataOwner) DataOwned() {}
+
+func (o *DataOwner) BeforeCreate(tx *gorm.DB) error {
+	operator, ok := datascope.FromContext(tx.Statement.Context)
+	if !ok {
+		return nil
+	}
+	if o.CreatedBy == 0 {
+		o.CreatedBy = operator.AdminId
+	}
+	if o.DepartmentId == 0 {
+		o.DepartmentId = operator.DepartmentId
+	}
+	return nil
+}
+
 type ModelCreatedAtUpdatedAt struct {
 	CreatedAt time.Time `gorm:"not null;autoCreateTime;comment:创建时间"`
 	UpdatedAt time.Time `gorm:"not null;autoUpdateTime;comment:创建时间"`
@@ -37,17 +64,55 @@ type Pagination struct {
 	PerPage int `form:"per_page" default:"10" binding:"omitempty,min=1,max=100" label:"每页大小"`
 }
 
+// HashPassword 使用配置的哈希算法生成密码哈希
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	hasher, err := currentHasher()
+	if err != nil {
+		return "", err
+	}
+	return hasher.Hash(password)
 }
 
+// CheckPasswordHash 按哈希格式识别算法校验密码，兼容历史算法生成的哈希
 func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	hasher := hasherOf(hash)
+	if hasher == nil {
+		return false
+	}
+	return hasher.Verify(password, hash)
+}
+
+// PasswordNeedsRehash 哈希算法与当前配置不一致或参数较弱时需要重新哈希，哈希配置错误时不重新哈希
+func PasswordNeedsRehash(hash string) bool {
+	hasher, err := currentHasher()
+	if err != nil {
+		return false
+	}
+	if !hasher.Match(hash) {
+		return true
+	}
+	return hasher.NeedsRehash(hash)
 }
 
 type Authenticatable interface {
 	GetId() uint
 	GetPassword() string
 }
+
+// TenantScoped 按租户隔离的模型，嵌入 BaseTenantModel 即可，读写由 tenant 插件自动限定租户
+type TenantScoped interface {
+	TenantScoped()
+}
+
+func (BaseTenantModel) TenantScoped() {}
+
+// TenantModels 筛选出按租户隔离的模型
+func TenantModels(models []interface{}) []interface{} {
+	var tenantModels []interface{}
+	for _, m := range models {
+		if _, ok := m.(TenantScoped); ok {
+			tenantModels = append(tenantModels, m)
+		}
+	}
+	return tenantModels
+}
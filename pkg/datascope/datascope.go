@@ -0,0 +1,158 @@
+package datascope
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUnsupported 当前模型无法按操作人的数据权限过滤，查询直接报错而不是返回全部数据
+var ErrUnsupported = errors.New("当前数据不支持按角色数据权限过滤")
+
+// Scope 角色数据权限范围
+type Scope string
+
+const (
+	// ScopeAll 全部数据
+	ScopeAll Scope = "all"
+	// ScopeTenant 本租户数据，租户模型已由 tenant 插件隔离，非租户模型由模型自身给出过滤条件
+	ScopeTenant Scope = "tenant"
+	// ScopeDepartment 本部门数据，操作人未归属部门时按本人处理
+	ScopeDepartment Scope = "department"
+	// ScopeSelf 仅本人数据
+	ScopeSelf Scope = "self"
+)
+
+func (s Scope) IsValid() bool {
+	switch s {
+	case ScopeAll, ScopeTenant, ScopeDepartment, ScopeSelf:
+		return true
+	}
+	return false
+}
+
+// Operator 当前操作人及其角色的数据权限
+type Operator struct {
+	AdminId      uint
+	DepartmentId uint
+	TenantId     uint
+	Scope        Scope
+}
+
+// Scoped 支持数据权限的模型，按操作人返回过滤条件，返回 nil 表示该范围不过滤，无法过滤时返回 ErrUnsupported
+type Scoped interface {
+	DataScope(operator Operator) (clause.Expression, error)
+}
+
+// Owned 带归属人的数据（嵌入 model.DataOwner），查询条件默认开启数据权限
+type Owned interface {
+	Scoped
+	DataOwned()
+}
+
+type operatorKey struct{}
+
+// WithOperator 绑定当前操作人，查询条件开启数据权限后按其角色范围过滤
+func WithOperator(ctx context.Context, operator Operator) context.Context {
+	return context.WithValue(ctx, operatorKey{}, operator)
+}
+
+// FromContext 当前操作人，未绑定时视为系统任务，不做数据权限过滤
+func FromContext(ctx context.Context) (Operator, bool) {
+	if ctx == nil {
+		return Operator{}, false
+	}
+	operator, ok := ctx.Value(operatorKey{}).(Operator)
+	return operator, ok
+}
+
+// Apply 按 db context 中的操作人对当前模型追加数据权限条件，作为 scope 使用
+func Apply(db *gorm.DB) *gorm.DB {
+	operator, ok := FromContext(db.Statement.Context)
+	if !ok || operator.Scope == ScopeAll {
+		return db
+	}
+	target := db.Statement.Model
+	if target == nil {
+		target = db.Statement.Dest
+	}
+	if err := db.Statement.Parse(target); err != nil {
+		db.AddError(fmt.Errorf("%w: %v", ErrUnsupported, err))
+		return db
+	}
+	// 带 tenant_id 的租户模型已由 tenant 插件隔离
+	if operator.Scope == ScopeTenant && db.Statement.Schema.LookUpField("TenantID") != nil {
+		return db
+	}
+	scoped, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(Scoped)
+	if !ok {
+		db.AddError(fmt.Errorf("%w: %s", ErrUnsupported, db.Statement.Schema.Table))
+		return db
+	}
+	expression, err := scoped.DataScope(operator)
+	if err != nil {
+		db.AddError(fmt.Errorf("%w: %s", err, db.Statement.Schema.Table))
+		return db
+	}
+	if expression == nil {
+		return db
+	}
+	return db.Where(expression)
+}
+
+// IsOwned 当前模型是否为带归属人的数据
+func IsOwned(db *gorm.DB) bool {
+	target := db.Statement.Model
+	if target == nil {
+		target = db.Statement.Dest
+	}
+	if target == nil || db.Statement.Parse(target) != nil {
+		return false
+	}
+	_, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(Owned)
+	return ok
+}
+
+// OwnerExpression 按归属人、归属部门字段生成本人与本部门范围的条件，字段为空时无法过滤，返回 ErrUnsupported
+// 本租户范围只适用于非租户模型，按归属人所在租户过滤
+func OwnerExpression(operator Operator, ownerColumn string, departmentColumn string) (clause.Expression, error) {
+	scope := operator.Scope
+	if scope == ScopeDepartment && operator.DepartmentId == 0 {
+		scope = ScopeSelf
+	}
+	switch scope {
+	case ScopeAll:
+		return nil, nil
+	case ScopeTenant:
+		if ownerColumn == "" {
+			return nil, ErrUnsupported
+		}
+		return TenantOwnerExpression(operator, ownerColumn), nil
+	case ScopeDepartment:
+		if departmentColumn == "" {
+			return nil, ErrUnsupported
+		}
+		return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: departmentColumn}, Value: operator.DepartmentId}, nil
+	case ScopeSelf:
+		if ownerColumn == "" {
+			return nil, ErrUnsupported
+		}
+		return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: ownerColumn}, Value: operator.AdminId}, nil
+	}
+	return nil, ErrUnsupported
+}
+
+// TenantOwnerExpression 归属人为本租户管理员，平台租户（ID 为 0）不过滤
+func TenantOwnerExpression(operator Operator, ownerColumn string) clause.Expression {
+	if operator.TenantId == 0 {
+		return nil
+	}
+	return clause.Expr{
+		SQL:  "? IN (SELECT admin_id FROM tenant_admins WHERE tenant_id = ?)",
+		Vars: []interface{}{clause.Column{Table: clause.CurrentTable, Name: ownerColumn}, operator.TenantId},
+	}
+}
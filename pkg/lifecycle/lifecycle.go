@@ -0,0 +1,177 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/maxlcoder/homework-backend/config"
+)
+
+// Phase 关闭阶段，按 Phases 顺序依次关闭，启动顺序相反
+type Phase string
+
+const (
+	PhaseHttp      Phase = "http"      // 停止接收请求并等待处理中的请求完成
+	PhaseConsumer  Phase = "consumer"  // 停止消费，等待处理中的消息完成
+	PhaseModule    Phase = "module"    // 模块后台任务
+	PhaseProducer  Phase = "producer"  // 发送缓冲中的消息
+	PhaseDatabase  Phase = "database"  // 关闭数据库连接池
+	PhaseTelemetry Phase = "telemetry" // 上报剩余的链路数据
+)
+
+// Phases 关闭顺序
+var Phases = []Phase{PhaseHttp, PhaseConsumer, PhaseModule, PhaseProducer, PhaseDatabase, PhaseTelemetry}
+
+// 未配置时的关闭总超时
+const defaultTimeout = 30 * time.Second
+
+// Hook 生命周期钩子，OnStart、OnStop 均可为空
+type Hook struct {
+	Name    string
+	Phase   Phase
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Manager 统一管理服务启动与关闭
+type Manager struct {
+	mu           sync.Mutex
+	conf         config.ShutdownConfig
+	hooks        []Hook
+	started      []Hook
+	shuttingDown atomic.Bool
+	stopCh       chan struct{}
+	stopOnce     sync.Once
+}
+
+var manager = newManager()
+
+func newManager() *Manager {
+	return &Manager{
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Init 设置关闭超时配置
+func Init(conf config.ShutdownConfig) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	manager.conf = conf
+}
+
+// Register 注册钩子，同一阶段内先注册的先启动、后关闭
+func Register(hook Hook) {
+	manager.mu.Lock()
+	defer manager.mu.Unlock()
+	manager.hooks = append(manager.hooks, hook)
+}
+
+// ShuttingDown 是否已开始关闭，就绪检查据此返回不可用
+func ShuttingDown() bool {
+	return manager.shuttingDown.Load()
+}
+
+// Stop 主动触发关闭，如 HTTP 服务异常退出
+func Stop() {
+	manager.stopOnce.Do(func() {
+		close(manager.stopCh)
+	})
+}
+
+// Run 执行启动钩子，收到 SIGINT / SIGTERM 或 Stop 后按阶段关闭
+func Run() error {
+	if err := manager.start(context.Background()); err != nil {
+		return errors.Join(err, manager.shutdown())
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(ch)
+	select {
+	case sig := <-ch:
+		slog.Info("shutdown signal received", "signal", sig.String())
+	case <-manager.stopCh:
+		slog.Info("shutdown requested")
+	}
+	return manager.shutdown()
+}
+
+// 按启动顺序（关闭阶段倒序）执行 OnStart，失败时停止启动
+func (m *Manager) start(ctx context.Context) error {
+	m.mu.Lock()
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	for i := len(Phases) - 1; i >= 0; i-- {
+		for _, hook := range hooks {
+			if hook.Phase != Phases[i] {
+				continue
+			}
+			if hook.OnStart != nil {
+				if err := hook.OnStart(ctx); err != nil {
+					return fmt.Errorf("%s 启动失败：%w", hook.Name, err)
+				}
+			}
+			m.mu.Lock()
+			m.started = append(m.started, hook)
+			m.mu.Unlock()
+		}
+	}
+	return nil
+}
+
+// 按阶段关闭已启动的钩子，每个阶段有独立超时，所有阶段共用总超时
+func (m *Manager) shutdown() error {
+	m.shuttingDown.Store(true)
+	m.mu.Lock()
+	conf := m.conf
+	started := m.started
+	m.mu.Unlock()
+
+	// 先将就绪检查置为不可用，等待负载均衡摘除实例
+	if conf.Delay > 0 {
+		slog.Info("waiting before shutdown", "delay", conf.Delay.String())
+		time.Sleep(conf.Delay)
+	}
+
+	timeout := conf.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	for _, phase := range Phases {
+		phaseCtx := ctx
+		phaseCancel := context.CancelFunc(func() {})
+		if phaseTimeout := conf.Phases[string(phase)]; phaseTimeout > 0 {
+			phaseCtx, phaseCancel = context.WithTimeout(ctx, phaseTimeout)
+		}
+		for i := len(started) - 1; i >= 0; i-- {
+			hook := started[i]
+			if hook.Phase != phase || hook.OnStop == nil {
+				continue
+			}
+			start := time.Now()
+			err := hook.OnStop(phaseCtx)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s 关闭失败：%w", hook.Name, err))
+				slog.Error("shutdown hook failed", "phase", phase, "hook", hook.Name, "duration_ms", time.Since(start).Milliseconds(), "error", err)
+				continue
+			}
+			slog.Info("shutdown hook done", "phase", phase, "hook", hook.Name, "duration_ms", time.Since(start).Milliseconds())
+		}
+		phaseCancel()
+	}
+	slog.Info("shutdown complete")
+	return errors.Join(errs...)
+}
@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/maxlcoder/homework-backend/config"
+	"gorm.io/gorm"
+	gorm_logger "gorm.io/gorm/logger"
+)
+
+// GormLogger 以 slog 输出 SQL 日志，查询使用的 context 带请求 ID 时一并记录
+type GormLogger struct {
+	level         gorm_logger.LogLevel
+	slowThreshold time.Duration
+}
+
+// NewGormLogger SQL 日志级别：silent / error / warn（默认，只记录错误与慢查询）/ info（记录全部 SQL）
+func NewGormLogger(conf config.LogConfig) *GormLogger {
+	level := gorm_logger.Warn
+	switch strings.ToLower(strings.TrimSpace(conf.SqlLevel)) {
+	case "silent":
+		level = gorm_logger.Silent
+	case "error":
+		level = gorm_logger.Error
+	case "info":
+		level = gorm_logger.Info
+	}
+	slowThreshold := conf.SlowThreshold
+	if slowThreshold <= 0 {
+		slowThreshold = 200 * time.Millisecond
+	}
+	return &GormLogger{level: level, slowThreshold: slowThreshold}
+}
+
+func (l *GormLogger) LogMode(level gorm_logger.LogLevel) gorm_logger.Interface {
+	newLogger := *l
+	newLogger.level = level
+	return &newLogger
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gorm_logger.Info {
+		slog.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gorm_logger.Warn {
+		slog.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gorm_logger.Error {
+		slog.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gorm_logger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	switch {
+	case err != nil && l.level >= gorm_logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		sql, rows := fc()
+		slog.ErrorContext(ctx, "sql error", "sql", sql, "rows", rows, "elapsed_ms", elapsed.Milliseconds(), "error", err)
+	case elapsed > l.slowThreshold && l.level >= gorm_logger.Warn:
+		sql, rows := fc()
+		slog.WarnContext(ctx, "slow sql", "sql", sql, "rows", rows, "elapsed_ms", elapsed.Milliseconds())
+	case l.level >= gorm_logger.Info:
+		sql, rows := fc()
+		slog.InfoContext(ctx, "sql", "sql", sql, "rows", rows, "elapsed_ms", elapsed.Milliseconds())
+	}
+}
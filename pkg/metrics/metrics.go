@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace 指标名前缀
+const Namespace = "homework"
+
+// Registry 全局指标注册表，模块的业务指标通过 Register 注册后由 /metrics 一并输出
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HttpRequestDuration 请求耗时，route 为路由定义（c.FullPath()），未匹配路由时为 unmatched
+	HttpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP 请求耗时",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	// HttpRequestsInFlight 正在处理的请求数
+	HttpRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "正在处理的 HTTP 请求数",
+	})
+
+	// CasbinEnforceTotal 权限校验次数，result 为 allow / deny / error
+	CasbinEnforceTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "casbin",
+		Name:      "enforce_total",
+		Help:      "casbin 权限校验次数",
+	}, []string{"source", "result"})
+
+	// CasbinEnforceDuration 权限校验耗时
+	CasbinEnforceDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "casbin",
+		Name:      "enforce_duration_seconds",
+		Help:      "casbin 权限校验耗时",
+		Buckets:   []float64{.0001, .0005, .001, .005, .01, .05, .1},
+	}, []string{"source"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HttpRequestDuration,
+		HttpRequestsInFlight,
+		CasbinEnforceTotal,
+		CasbinEnforceDuration,
+	)
+}
+
+// Register 注册模块自定义指标，如业务计数器
+func Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := Registry.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MustRegister 注册模块自定义指标，重复注册时 panic
+func MustRegister(cs ...prometheus.Collector) {
+	Registry.MustRegister(cs...)
+}
+
+// RegisterDB 连接池指标（sql.DBStats），name 区分数据库
+func RegisterDB(name string, db *sql.DB) error {
+	return Registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler 指标输出接口
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// ObserveCasbinEnforce 记录一次权限校验，source 区分调用方（middleware / check 等）
+func ObserveCasbinEnforce(source string, start time.Time, allowed bool, err error) {
+	result := "deny"
+	if err != nil {
+		result = "error"
+	} else if allowed {
+		result = "allow"
+	}
+	CasbinEnforceTotal.WithLabelValues(source, result).Inc()
+	CasbinEnforceDuration.WithLabelValues(source).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,133 @@
+package tenant
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+
+	"github.com/maxlcoder/homework-backend/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Plugin gorm 租户隔离插件
+//
+// 对嵌入 BaseTenantModel 的模型：查询、更新、删除自动追加 tenant_id 条件，新增时自动填充 tenant_id，
+// 写入其他租户的数据直接报错。CrossTenant 授权的 context 不做过滤，但逐条记录审计日志；System 声明的系统任务不做过滤；
+// 三者都没有的 context 读写租户模型直接报错，避免遗漏绑定租户时越权访问。
+type Plugin struct{}
+
+func NewPlugin() *Plugin {
+	return &Plugin{}
+}
+
+func (p *Plugin) Name() string {
+	return "tenant"
+}
+
+func (p *Plugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	if err := callback.Create().Before("gorm:create").Register("tenant:create", p.create); err != nil {
+		return err
+	}
+	if err := callback.Query().Before("gorm:query").Register("tenant:query", p.scope); err != nil {
+		return err
+	}
+	if err := callback.Update().Before("gorm:update").Register("tenant:update", p.update); err != nil {
+		return err
+	}
+	if err := callback.Delete().Before("gorm:delete").Register("tenant:delete", p.scope); err != nil {
+		return err
+	}
+	return callback.Row().Before("gorm:row").Register("tenant:row", p.scope)
+}
+
+// 当前语句的租户：返回 false 表示无需处理
+func (p *Plugin) tenantOf(db *gorm.DB) (*schema.Field, uint, bool) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil {
+		return nil, 0, false
+	}
+	if _, ok := reflect.New(stmt.Schema.ModelType).Interface().(model.TenantScoped); !ok {
+		return nil, 0, false
+	}
+	field := stmt.Schema.LookUpField("TenantID")
+	if field == nil {
+		return nil, 0, false
+	}
+	if cross, ok := crossTenantOf(stmt.Context); ok {
+		slog.InfoContext(stmt.Context, "跨租户访问", "operator", cross.OperatorId, "reason", cross.Reason, "table", stmt.Table)
+		return nil, 0, false
+	}
+	if isSystem(stmt.Context) {
+		return nil, 0, false
+	}
+	tenantId, ok := FromContext(stmt.Context)
+	if !ok {
+		db.AddError(ErrTenantRequired)
+		return nil, 0, false
+	}
+	return field, tenantId, true
+}
+
+func (p *Plugin) scope(db *gorm.DB) {
+	field, tenantId, ok := p.tenantOf(db)
+	if !ok {
+		return
+	}
+	db.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: tenantId},
+	}})
+}
+
+func (p *Plugin) create(db *gorm.DB) {
+	field, tenantId, ok := p.tenantOf(db)
+	if !ok {
+		return
+	}
+	p.assign(db, field, tenantId, true)
+}
+
+func (p *Plugin) update(db *gorm.DB) {
+	field, tenantId, ok := p.tenantOf(db)
+	if !ok {
+		return
+	}
+	p.assign(db, field, tenantId, false)
+	p.scope(db)
+}
+
+// 校验待写入数据的租户，fill 为 true 时空值填充为当前租户
+func (p *Plugin) assign(db *gorm.DB, field *schema.Field, tenantId uint, fill bool) {
+	stmt := db.Statement
+	if updates, ok := stmt.Dest.(map[string]interface{}); ok {
+		for _, key := range []string{field.Name, field.DBName} {
+			if value, exists := updates[key]; exists && fmt.Sprint(value) != fmt.Sprint(tenantId) {
+				db.AddError(fmt.Errorf("禁止写入其他租户的数据: %v", value))
+				return
+			}
+		}
+		return
+	}
+	check := func(rv reflect.Value) {
+		value, zero := field.ValueOf(stmt.Context, rv)
+		if zero {
+			if fill {
+				db.AddError(field.Set(stmt.Context, rv, tenantId))
+			}
+			return
+		}
+		if value != tenantId {
+			db.AddError(fmt.Errorf("禁止写入其他租户的数据: %v", value))
+		}
+	}
+	switch stmt.ReflectValue.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < stmt.ReflectValue.Len(); i++ {
+			check(reflect.Indirect(stmt.ReflectValue.Index(i)))
+		}
+	case reflect.Struct:
+		check(stmt.ReflectValue)
+	}
+}
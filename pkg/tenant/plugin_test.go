@@ -0,0 +1,102 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/maxlcoder/homework-backend/model"
+	"gorm.io/gorm"
+)
+
+type note struct {
+	model.BaseTenantModel
+	Title string
+}
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&note{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(NewPlugin()); err != nil {
+		t.Fatal(err)
+	}
+	system := db.WithContext(System(context.Background()))
+	for _, n := range []note{{Title: "a"}, {Title: "b"}} {
+		n.TenantID = 1
+		if n.Title == "b" {
+			n.TenantID = 2
+		}
+		if err := system.Create(&n).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestPluginRequiresTenant(t *testing.T) {
+	db := newTestDB(t)
+	var notes []note
+	err := db.Find(&notes).Error
+	if !errors.Is(err, ErrTenantRequired) {
+		t.Fatalf("未绑定租户应报错, got %v", err)
+	}
+	err = db.Create(&note{Title: "c"}).Error
+	if !errors.Is(err, ErrTenantRequired) {
+		t.Fatalf("未绑定租户写入应报错, got %v", err)
+	}
+}
+
+func TestPluginScopesTenant(t *testing.T) {
+	db := newTestDB(t).WithContext(WithTenant(context.Background(), 1))
+	var notes []note
+	if err := db.Find(&notes).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 1 || notes[0].Title != "a" {
+		t.Fatalf("只应查到本租户数据, got %+v", notes)
+	}
+	created := note{Title: "c"}
+	if err := db.Create(&created).Error; err != nil {
+		t.Fatal(err)
+	}
+	if created.TenantID != 1 {
+		t.Fatalf("新增应填充当前租户, got %d", created.TenantID)
+	}
+	other := note{Title: "d"}
+	other.TenantID = 2
+	if err := db.Create(&other).Error; err == nil {
+		t.Fatal("写入其他租户的数据应报错")
+	}
+}
+
+func TestPluginSystemAndCrossTenant(t *testing.T) {
+	db := newTestDB(t)
+	var count int64
+	if err := db.WithContext(System(context.Background())).Model(&note{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("系统任务不做租户过滤, got %d", count)
+	}
+
+	if _, err := CrossTenant(WithTenant(context.Background(), 1), 1, "排查"); !errors.Is(err, ErrCrossTenantDenied) {
+		t.Fatalf("非平台租户不能跨租户访问, got %v", err)
+	}
+	cross, err := CrossTenant(WithTenant(context.Background(), PlatformTenantId), 1, "排查")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WithContext(cross).Model(&note{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Fatalf("跨租户访问不做租户过滤, got %d", count)
+	}
+}
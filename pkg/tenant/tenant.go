@@ -0,0 +1,78 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// PlatformTenantId 平台租户，平台（超级）管理员所属
+const PlatformTenantId uint = 0
+
+var ErrCrossTenantDenied = errors.New("仅平台管理员可跨租户访问")
+
+// ErrTenantRequired 未绑定租户、也未声明跨租户或系统任务的 context 读写租户数据
+var ErrTenantRequired = errors.New("未指定租户，禁止读写租户数据")
+
+type tenantKey struct{}
+
+type crossTenantKey struct{}
+
+type systemKey struct{}
+
+// crossTenant 跨租户访问授权，执行的每条语句都会记录操作人与原因
+type crossTenant struct {
+	OperatorId uint
+	Reason     string
+}
+
+// WithTenant 绑定当前租户，之后使用该 context 的租户模型读写都限定在该租户内
+func WithTenant(ctx context.Context, tenantId uint) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantId)
+}
+
+// FromContext 当前租户
+func FromContext(ctx context.Context) (uint, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	tenantId, ok := ctx.Value(tenantKey{}).(uint)
+	return tenantId, ok
+}
+
+// CrossTenant 显式跨租户访问，仅平台租户可用，需提供操作人与原因用于审计
+func CrossTenant(ctx context.Context, operatorId uint, reason string) (context.Context, error) {
+	tenantId, ok := FromContext(ctx)
+	if !ok || tenantId != PlatformTenantId {
+		return nil, ErrCrossTenantDenied
+	}
+	reason = strings.TrimSpace(reason)
+	if operatorId == 0 || reason == "" {
+		return nil, errors.New("跨租户访问需指定操作人与原因")
+	}
+	return context.WithValue(ctx, crossTenantKey{}, crossTenant{OperatorId: operatorId, Reason: reason}), nil
+}
+
+// System 声明系统任务（初始化数据、登录鉴权、租户管理、定时清理等），租户模型读写不做租户过滤，优先于已绑定的租户
+func System(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, systemKey{}, true)
+}
+
+func isSystem(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	system, _ := ctx.Value(systemKey{}).(bool)
+	return system
+}
+
+func crossTenantOf(ctx context.Context) (crossTenant, bool) {
+	if ctx == nil {
+		return crossTenant{}, false
+	}
+	cross, ok := ctx.Value(crossTenantKey{}).(crossTenant)
+	return cross, ok
+}
@@ -0,0 +1,88 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/maxlcoder/homework-backend/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 追踪器名称
+const instrumentationName = "github.com/maxlcoder/homework-backend"
+
+var (
+	provider   *sdktrace.TracerProvider
+	providerMu sync.Mutex
+)
+
+// Init 按配置设置全局 TracerProvider 与 W3C trace context 传播；未配置 exporter 时只传播上下文，不记录 span
+func Init(conf config.TracingConfig) error {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch conf.Exporter {
+	case "":
+		return nil
+	case "otlp":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(conf.Endpoint)}
+		if conf.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(context.Background(), opts...)
+	case "stdout":
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return fmt.Errorf("不支持的 tracing exporter: %s", conf.Exporter)
+	}
+	if err != nil {
+		return fmt.Errorf("tracing exporter 初始化失败: %w", err)
+	}
+
+	serviceName := conf.ServiceName
+	if serviceName == "" {
+		serviceName = "homework-backend"
+	}
+	ratio := conf.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(tracerProvider)
+
+	providerMu.Lock()
+	provider = tracerProvider
+	providerMu.Unlock()
+	return nil
+}
+
+// Shutdown 导出剩余 span 并关闭 TracerProvider，未启用时直接返回
+func Shutdown(ctx context.Context) error {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	if provider == nil {
+		return nil
+	}
+	err := provider.Shutdown(ctx)
+	provider = nil
+	return err
+}
+
+// Tracer 项目统一的追踪器，使用当前全局 TracerProvider
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
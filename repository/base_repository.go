@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"github.com/maxlcoder/homework-backend/model"
 	"gorm.io/gorm"
 )
@@ -16,6 +18,13 @@ func NewBaseRepository[T any](db *gorm.DB) *BaseRepository[T] {
 	}
 }
 
+// WithContext 绑定请求 context，租户模型的读写由 tenant 插件按 context 中的租户自动隔离
+func (r *BaseRepository[T]) WithContext(ctx context.Context) *BaseRepository[T] {
+	return &BaseRepository[T]{
+		DB: r.DB.WithContext(ctx),
+	}
+}
+
 // 实现基础方法
 func (r *BaseRepository[T]) getDB(tx *gorm.DB) *gorm.DB {
 	if tx != nil {
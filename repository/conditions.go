@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"github.com/maxlcoder/homework-backend/pkg/datascope"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -15,6 +16,10 @@ type ConditionScope struct {
 	OrderBy    clause.OrderBy
 	Group      string
 	Having     string
+	// 按 context 中当前操作人的角色数据权限过滤，用于列表查询；带归属人的模型（嵌入 DataOwner）默认开启
+	DataScope bool
+	// 不做数据权限过滤，如唯一性校验需覆盖操作人不可见的数据
+	SkipDataScope bool
 }
 
 func (cs ConditionScope) Apply(db *gorm.DB) *gorm.DB {
@@ -31,6 +36,15 @@ func (cs ConditionScope) Apply(db *gorm.DB) *gorm.DB {
 	if len(cs.Scopes) > 0 {
 		query = query.Scopes(cs.Scopes...)
 	}
+	// 数据权限：执行时才能确定模型，无法按操作人过滤时查询报错
+	if !cs.SkipDataScope {
+		query = query.Scopes(func(db *gorm.DB) *gorm.DB {
+			if cs.DataScope || datascope.IsOwned(db) {
+				return datascope.Apply(db)
+			}
+			return db
+		})
+	}
 	// preload
 	for _, preload := range cs.Preloads {
 		query = query.Preload(preload)
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/maxlcoder/homework-backend/model"
+	"github.com/maxlcoder/homework-backend/pkg/datascope"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// 带归属人、无租户字段的数据，与 wms Staff 一致
+type ownedNote struct {
+	model.BaseModel
+	model.DataOwner
+	Title string
+}
+
+// 不支持数据权限的数据
+type plainNote struct {
+	model.BaseModel
+	Title string
+}
+
+// 管理员 1、2 属于租户 2，管理员 3 属于租户 3；管理员 1、2 同属部门 10
+func newConditionTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&ownedNote{}, &plainNote{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("CREATE TABLE tenant_admins (tenant_id integer NOT NULL, admin_id integer NOT NULL)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("INSERT INTO tenant_admins (tenant_id, admin_id) VALUES (2, 1), (2, 2), (3, 3)").Error; err != nil {
+		t.Fatal(err)
+	}
+	notes := []ownedNote{
+		{DataOwner: model.DataOwner{CreatedBy: 1, DepartmentId: 10}, Title: "admin_1"},
+		{DataOwner: model.DataOwner{CreatedBy: 2, DepartmentId: 10}, Title: "admin_2"},
+		{DataOwner: model.DataOwner{CreatedBy: 3, DepartmentId: 20}, Title: "admin_3"},
+	}
+	if err := db.Create(&notes).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&[]plainNote{{Title: "a"}, {Title: "b"}}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func withOperator(db *gorm.DB, scope datascope.Scope) *gorm.DB {
+	return db.WithContext(datascope.WithOperator(context.Background(), datascope.Operator{
+		AdminId:      1,
+		DepartmentId: 10,
+		TenantId:     2,
+		Scope:        scope,
+	}))
+}
+
+func TestConditionScopeFiltersOwnedByDefault(t *testing.T) {
+	db := newConditionTestDB(t)
+	cases := map[datascope.Scope]int64{
+		datascope.ScopeAll:        3,
+		datascope.ScopeTenant:     2,
+		datascope.ScopeDepartment: 2,
+		datascope.ScopeSelf:       1,
+	}
+	for scope, want := range cases {
+		count, notes, err := NewBaseRepository[ownedNote](withOperator(db, scope)).Page(ConditionScope{}, model.Pagination{Page: 1, PerPage: 10})
+		if err != nil {
+			t.Fatalf("%s: %v", scope, err)
+		}
+		if count != want || int64(len(notes)) != want {
+			t.Errorf("%s: 应返回 %d 条, got %d %d", scope, want, count, len(notes))
+		}
+		for _, note := range notes {
+			if note.Title == "admin_3" && scope != datascope.ScopeAll {
+				t.Errorf("%s: 不应返回其他租户的数据", scope)
+			}
+		}
+	}
+}
+
+func TestConditionScopeSkipDataScope(t *testing.T) {
+	db := newConditionTestDB(t)
+	count, err := NewBaseRepository[ownedNote](withOperator(db, datascope.ScopeSelf)).CountBy(ConditionScope{SkipDataScope: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Fatalf("跳过数据权限时应返回全部数据, got %d", count)
+	}
+	// 未绑定操作人视为系统任务，不过滤
+	count, err = NewBaseRepository[ownedNote](db).CountBy(ConditionScope{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 3 {
+		t.Fatalf("系统任务应返回全部数据, got %d", count)
+	}
+}
+
+func TestConditionScopeFailsClosed(t *testing.T) {
+	db := newConditionTestDB(t)
+	for _, scope := range []datascope.Scope{datascope.ScopeTenant, datascope.ScopeSelf} {
+		_, notes, err := NewBaseRepository[plainNote](withOperator(db, scope)).Page(ConditionScope{DataScope: true}, model.Pagination{Page: 1, PerPage: 10})
+		if !errors.Is(err, datascope.ErrUnsupported) {
+			t.Errorf("%s: 无法按数据权限过滤时应报错, got %v", scope, err)
+		}
+		if len(notes) != 0 {
+			t.Errorf("%s: 报错时不应返回数据, got %d", scope, len(notes))
+		}
+	}
+	// 未开启数据权限且非归属人数据，不过滤
+	count, err := NewBaseRepository[plainNote](withOperator(db, datascope.ScopeSelf)).CountBy(ConditionScope{})
+	if err != nil || count != 2 {
+		t.Fatalf("got %d %v", count, err)
+	}
+}
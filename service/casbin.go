@@ -7,13 +7,15 @@ import (
 )
 
 // 初始化 casbin
-func NewCasbin(db *gorm.DB) (*casbin.Enforcer, error) {
-	// 初始化 casbin 相关表
-	adapter, err := gormadapter.NewAdapterByDBUseTableName(db, "", "casbin_rule")
+func NewCasbin(db *gorm.DB) (*casbin.SyncedEnforcer, error) {
+	// casbin_rule 表由 core 模块迁移创建，adapter 不自动建表
+	adapterDB := db.Session(&gorm.Session{})
+	gormadapter.TurnOffAutoMigrate(adapterDB)
+	adapter, err := gormadapter.NewAdapterByDBUseTableName(adapterDB, "", "casbin_rule")
 	if err != nil {
 		return nil, err
 	}
-	enforcer, err := casbin.NewEnforcer("config/rbac_with_domains_model.conf", adapter)
+	enforcer, err := casbin.NewSyncedEnforcer("config/rbac_with_domains_model.conf", adapter)
 	if err != nil {
 		return nil, err
 	}
@@ -21,5 +23,10 @@ func NewCasbin(db *gorm.DB) (*casbin.Enforcer, error) {
 	if err != nil {
 		return nil, err
 	}
+	// 多实例部署时同步规则变更
+	err = setupWatcher(enforcer, db)
+	if err != nil {
+		return nil, err
+	}
 	return enforcer, nil
 }
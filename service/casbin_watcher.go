@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/casbin/casbin/v2"
+	"github.com/casbin/casbin/v2/persist"
+	"github.com/maxlcoder/homework-backend/config"
+	"github.com/maxlcoder/homework-backend/kafka"
+	"github.com/maxlcoder/homework-backend/pkg/lifecycle"
+	kafka_go "github.com/segmentio/kafka-go"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 当前实例标识，忽略自己发出的变更通知
+var instanceId = newInstanceId()
+
+func newInstanceId() string {
+	hostname, _ := os.Hostname()
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(b))
+}
+
+// 按配置为 enforcer 设置规则变更通知，其他实例收到通知后重新加载规则
+func setupWatcher(enforcer *casbin.SyncedEnforcer, db *gorm.DB) error {
+	conf := config.GetConfig()
+	if conf == nil {
+		return nil
+	}
+	casbinConfig := conf.Casbin
+	var watcher persist.Watcher
+	var err error
+	switch casbinConfig.Watcher {
+	case "":
+		return nil
+	case "kafka":
+		watcher, err = NewKafkaWatcher(conf.Kafka.Brokers, casbinConfig.Topic)
+	case "database":
+		watcher, err = NewDBWatcher(db, casbinConfig.PollInterval)
+	default:
+		return fmt.Errorf("不支持的 casbin watcher: %s", casbinConfig.Watcher)
+	}
+	if err != nil {
+		return err
+	}
+	if err := enforcer.SetWatcher(watcher); err != nil {
+		return err
+	}
+	// 关闭时停止接收变更通知
+	lifecycle.Register(lifecycle.Hook{
+		Name:  "casbin-watcher",
+		Phase: lifecycle.PhaseConsumer,
+		OnStop: func(ctx context.Context) error {
+			watcher.Close()
+			return nil
+		},
+	})
+	return watcher.SetUpdateCallback(func(source string) {
+		if err := enforcer.LoadPolicy(); err != nil {
+			slog.Error("casbin policy reload failed", "error", err)
+			return
+		}
+		slog.Info("casbin policy reloaded", "source", source)
+	})
+}
+
+// casbinMessage 规则变更通知
+type casbinMessage struct {
+	Instance  string    `json:"instance"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// KafkaWatcher 通过 kafka 广播规则变更，topic 需为单分区
+type KafkaWatcher struct {
+	topic    string
+	mu       sync.RWMutex
+	callback func(string)
+	cancel   context.CancelFunc
+}
+
+func NewKafkaWatcher(brokers []string, topic string) (*KafkaWatcher, error) {
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("未配置 kafka broker")
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("未配置 casbin 通知 topic")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &KafkaWatcher{
+		topic:  topic,
+		cancel: cancel,
+	}
+	go kafka.Broadcast(ctx, brokers, topic, w.handle)
+	return w, nil
+}
+
+func (w *KafkaWatcher) handle(ctx context.Context, msg kafka_go.Message) error {
+	var message casbinMessage
+	if err := json.Unmarshal(msg.Value, &message); err != nil {
+		return err
+	}
+	if message.Instance == instanceId {
+		return nil
+	}
+	w.mu.RLock()
+	callback := w.callback
+	w.mu.RUnlock()
+	if callback != nil {
+		callback(message.Instance)
+	}
+	return nil
+}
+
+func (w *KafkaWatcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callback = callback
+	return nil
+}
+
+// Update 通知其他实例，通过 kafka 生产者发送，需在创建 enforcer 前调用 kafka.InitProducer
+func (w *KafkaWatcher) Update() error {
+	value, err := json.Marshal(casbinMessage{Instance: instanceId, ChangedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+	return kafka.SendSync(context.Background(), w.topic, value)
+}
+
+func (w *KafkaWatcher) Close() {
+	w.cancel()
+}
+
+// CasbinPolicyVersion 规则版本号，任一实例变更规则后递增，其他实例轮询发现变化后重新加载
+type CasbinPolicyVersion struct {
+	ID        uint
+	Version   int64     `gorm:"not null;default:0"`
+	Instance  string    `gorm:"size:100;not null;default:'';comment:最近一次变更的实例"`
+	UpdatedAt time.Time `gorm:"not null;autoUpdateTime"`
+}
+
+// DBWatcher 数据库轮询方式，无需额外组件，延迟取决于轮询间隔
+type DBWatcher struct {
+	db       *gorm.DB
+	interval time.Duration
+	mu       sync.Mutex
+	version  int64
+	callback func(string)
+	stopCh   chan struct{}
+	once     sync.Once
+}
+
+func NewDBWatcher(db *gorm.DB, interval time.Duration) (*DBWatcher, error) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	// 表结构由 core 模块迁移创建
+	record := CasbinPolicyVersion{ID: 1}
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error; err != nil {
+		return nil, err
+	}
+	if err := db.First(&record, 1).Error; err != nil {
+		return nil, err
+	}
+	w := &DBWatcher{
+		db:       db,
+		interval: interval,
+		version:  record.Version,
+		stopCh:   make(chan struct{}),
+	}
+	go w.poll()
+	return w, nil
+}
+
+func (w *DBWatcher) poll() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			var record CasbinPolicyVersion
+			if err := w.db.First(&record, 1).Error; err != nil {
+				slog.Error("casbin policy version poll failed", "error", err)
+				continue
+			}
+			w.mu.Lock()
+			changed := record.Version != w.version
+			w.version = record.Version
+			callback := w.callback
+			w.mu.Unlock()
+			if changed && callback != nil {
+				callback(record.Instance)
+			}
+		}
+	}
+}
+
+func (w *DBWatcher) SetUpdateCallback(callback func(string)) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callback = callback
+	return nil
+}
+
+func (w *DBWatcher) Update() error {
+	var record CasbinPolicyVersion
+	err := w.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&CasbinPolicyVersion{}).Where("id = ?", 1).Updates(map[string]interface{}{
+			"version":  gorm.Expr("version + 1"),
+			"instance": instanceId,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.First(&record, 1).Error
+	})
+	if err != nil {
+		return err
+	}
+	// 期间其他实例也有变更时版本号跳跃，留给轮询重新加载
+	w.mu.Lock()
+	if record.Version == w.version+1 {
+		w.version = record.Version
+	}
+	w.mu.Unlock()
+	return nil
+}
+
+func (w *DBWatcher) Close() {
+	w.once.Do(func() {
+		close(w.stopCh)
+	})
+}